@@ -8,6 +8,10 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/contrib/swagger"
@@ -15,26 +19,42 @@ import (
 	docs "github.com/topboyasante/pitstop/docs/v1"
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/database"
+	"github.com/topboyasante/pitstop/internal/core/keys"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/core/middleware"
 	"github.com/topboyasante/pitstop/internal/core/redis"
 	"github.com/topboyasante/pitstop/internal/modules/auth"
+	"github.com/topboyasante/pitstop/internal/modules/badge"
 	"github.com/topboyasante/pitstop/internal/modules/health"
 	"github.com/topboyasante/pitstop/internal/modules/post"
 	"github.com/topboyasante/pitstop/internal/modules/question"
+	"github.com/topboyasante/pitstop/internal/modules/reputation"
 	"github.com/topboyasante/pitstop/internal/modules/user"
 	"github.com/topboyasante/pitstop/internal/provider"
 )
 
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	logger.InitGlobal()
 
 	err := config.InitGlobal()
 	if err != nil {
-		logger.Error("failed to start server - configuration error: %v", err)
+		logger.Fatal("failed to start server - configuration error", "error", err)
+		log.Panicf("error: %s", err)
 	}
 	cfg := config.Get()
 
+	if err := keys.InitGlobal(cfg); err != nil {
+		logger.Fatal("failed to initialize JWT signing keys", "error", err)
+	}
+
+	// Check hourly whether the active signing key is due for rotation
+	stopRotation := make(chan struct{})
+	defer close(stopRotation)
+	keys.Get().StartRotation(time.Hour, stopRotation)
+
 	db, err := database.Init(cfg)
 	if err != nil {
 		logger.Fatal("failed to connect to database: %v", err)
@@ -60,11 +80,37 @@ func main() {
 	// Initialize provider with dependency injection
 	provider := provider.NewProvider(db, redisClient, cfg, validator)
 
+	// Erase accounts whose deletion grace period has passed
+	stopDeletionSweeper := make(chan struct{})
+	defer close(stopDeletionSweeper)
+	provider.UserService.StartDeletionSweeper(cfg.Users.DeletionSweepInterval, stopDeletionSweeper)
+
+	// Finish data exports interrupted by a restart and remove expired archives hourly
+	provider.ExportService.ResumeUnfinished()
+	stopExportSweeper := make(chan struct{})
+	defer close(stopExportSweeper)
+	provider.ExportService.StartExpirySweeper(time.Hour, stopExportSweeper)
+
+	// Award or expire bounties whose time is up
+	stopBountySweeper := make(chan struct{})
+	defer close(stopBountySweeper)
+	provider.BountyService.StartExpirySweeper(cfg.Bounties.SweepInterval, stopBountySweeper)
+
+	// Add buffered views to the database periodically; the flusher is stopped after the server
+	// shuts down so the views recorded by the last requests are flushed too
+	stopViewFlusher := make(chan struct{})
+	viewFlusherDone := provider.ViewService.StartFlusher(cfg.Views.FlushInterval, stopViewFlusher)
+
 	// Update Swagger host dynamically
 	docs.SwaggerInfo.Host = fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
 
+	// Behind a reverse proxy the client IP, which rate limits and anonymous view counts key on,
+	// is read from the proxy header, but only for requests from the trusted proxies
 	app := fiber.New(fiber.Config{
-		ErrorHandler: middleware.ErrorHandler(),
+		ErrorHandler:            middleware.ErrorHandler(),
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Server.TrustedProxies,
 	})
 
 	// Add rate limiting and request logging middleware
@@ -78,17 +124,37 @@ func main() {
 		Title:    "Pitstop API Documentation",
 	}))
 
+	// Public signing keys so other services can verify Pitstop tokens
+	auth.RegisterWellKnownRoutes(app, provider.AuthHandler)
+
 	v1 := app.Group("/api/v1")
 
 	// Register modular routes
 	health.RegisterRoutes(v1, provider.HealthHandler)
-	auth.RegisterRoutes(v1, provider.AuthHandler)
-	user.RegisterRoutes(v1, provider.UserHandler, provider.FollowHandler)
+	auth.RegisterRoutes(v1, provider.AuthHandler, provider.TokenHandler, provider.MFAHandler)
+	user.RegisterRoutes(v1, provider.UserHandler, provider.FollowHandler, provider.BlockHandler, provider.ExportHandler, provider.SuggestionHandler)
 	post.RegisterRoutes(v1, provider.PostHandler, provider.CommentHandler, provider.LikeHandler)
-	question.RegisterRoutes(v1, provider.QuestionHandler, provider.AnswerHandler)
+	question.RegisterRoutes(v1, provider.QuestionHandler, provider.AnswerHandler, provider.VoteHandler, provider.BountyHandler, provider.LifecycleHandler, provider.CommentHandler, provider.LikeHandler)
+	reputation.RegisterRoutes(v1, provider.ReputationHandler)
+	badge.RegisterRoutes(v1, provider.BadgeHandler)
+
+	go func() {
+		if err := app.Listen(":" + cfg.Server.Port); err != nil {
+			logger.Fatal("failed to start server: %v", err)
+			log.Panicf("error: %s", err)
+		}
+	}()
 
-	if err := app.Listen(":" + cfg.Server.Port); err != nil {
-		logger.Fatal("failed to start server: %v", err)
-		log.Panicf("error: %s", err)
+	// Wait for an interrupt, then stop taking requests and let in-flight ones finish
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("Shutting down server")
+	if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		logger.Error("Failed to shut down server", "error", err)
 	}
+
+	close(stopViewFlusher)
+	<-viewFlusherDone
 }
@@ -1,7 +1,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/topboyasante/pitstop/internal/core/logger"
@@ -10,19 +16,37 @@ import (
 
 // The API configuration structure
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	OAuth    oauth2.Config
-	Redis    RedisConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	OAuth      map[string]OAuthProviderConfig
+	Redis      RedisConfig
+	JWT        JWTConfig
+	Clients    map[string]ClientConfig
+	MFA        MFAConfig
+	Mail       MailConfig
+	Storage    StorageConfig
+	Users      UsersConfig
+	Reputation ReputationConfig
+	Bounties   BountyConfig
+	Questions  QuestionsConfig
+	Views      ViewsConfig
 }
 
 // Server configuration structure
 type ServerConfig struct {
+	Environment string
 	Port        string
 	Host        string
 	JWTSecret   string
 	JWTIssuer   string
 	FrontendURL string
+	// PublicURL is the externally reachable base URL of the API, used in links sent by email
+	PublicURL string
+	// ProxyHeader names the header a reverse proxy puts the client IP in, such as X-Forwarded-For.
+	// When empty the IP of the connection is used.
+	ProxyHeader string
+	// TrustedProxies lists the proxy IPs or CIDR ranges whose ProxyHeader is believed
+	TrustedProxies []string
 }
 
 // Database configuration structure
@@ -39,6 +63,171 @@ type RedisConfig struct {
 	URL string
 }
 
+// OAuth provider configuration structure. Endpoints are configurable so a local stand-in can replace the real provider.
+type OAuthProviderConfig struct {
+	oauth2.Config
+	UserInfoURL string
+	// EmailsURL lists the account's email addresses when the profile may omit them (GitHub)
+	EmailsURL string
+}
+
+// Supported OAuth providers
+const (
+	OAuthProviderGoogle   = "google"
+	OAuthProviderGitHub   = "github"
+	OAuthProviderFacebook = "facebook"
+)
+
+// ClientConfig registers an application that can sign users in. The client ID is used as
+// the audience of the tokens issued to it.
+type ClientConfig struct {
+	ID string
+	// RedirectURIs are the only locations the OAuth callback may send codes to. The first is the default
+	RedirectURIs []string
+	// RequirePKCE rejects authorization requests without a code_challenge (public clients)
+	RequirePKCE     bool
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// Two-factor authentication configuration structure
+type MFAConfig struct {
+	// Issuer is the account label shown in authenticator apps
+	Issuer string
+	// EncryptionKey encrypts TOTP secrets and keys recovery code hashes. Derived from JWT_SECRET when unset
+	EncryptionKey string
+	// RequiredRoles lists the roles that must use two-factor authentication to exercise their privileges
+	RequiredRoles []string
+	// PendingTokenTTL is how long the mfa_pending token from a sign-in stays valid
+	PendingTokenTTL time.Duration
+}
+
+// Outgoing email configuration structure
+type MailConfig struct {
+	// Driver is "log" (write to the log, for development) or "smtp"
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	// MagicLinkEnabled turns on passwordless sign-in with emailed links
+	MagicLinkEnabled bool
+	// DataExportEnabled turns on personal data exports, whose download link is emailed
+	DataExportEnabled bool
+}
+
+// Blob storage configuration structure
+type StorageConfig struct {
+	// Driver selects where blobs are kept. Only "local" is supported
+	Driver string
+	// LocalPath is the directory used by the local driver
+	LocalPath string
+}
+
+// User profile configuration structure
+type UsersConfig struct {
+	// UsernameChangeCooldown is the minimum time between two username changes
+	UsernameChangeCooldown time.Duration
+	// UsernameHoldPeriod is how long a previous username stays reserved for its owner
+	// before someone else can claim it. The redirect to the owner works until then.
+	UsernameHoldPeriod time.Duration
+	// DeletionGracePeriod is how long a deletion request waits before the account is erased.
+	// Signing in during this period cancels the request.
+	DeletionGracePeriod time.Duration
+	// DeletionSweepInterval is how often accounts past their grace period are erased
+	DeletionSweepInterval time.Duration
+	// ExportLinkTTL is how long a personal data export can be downloaded
+	ExportLinkTTL time.Duration
+	// ExportCooldown is the minimum time between two data export requests
+	ExportCooldown time.Duration
+	// SuggestionsCacheTTL is how long a user's ranked follow suggestions are cached
+	SuggestionsCacheTTL time.Duration
+}
+
+// Reputation configuration structure. Points are credited to (or, when negative, debited from)
+// the user named in each comment; thresholds are the reputation a privilege unlocks at.
+type ReputationConfig struct {
+	// QuestionUpvoted goes to the author of an upvoted question
+	QuestionUpvoted int
+	// AnswerUpvoted goes to the author of an upvoted answer
+	AnswerUpvoted int
+	// AnswerAccepted goes to the author of an accepted answer
+	AnswerAccepted int
+	// AcceptedAnswer goes to the question author who accepts an answer
+	AcceptedAnswer int
+	// Downvoted goes to the author of a downvoted question or answer
+	Downvoted int
+	// DownvoteCast goes to the user who downvotes an answer
+	DownvoteCast int
+	// SpamRemoved goes to the author of content removed as spam
+	SpamRemoved int
+	// EditTagsThreshold unlocks editing the tags of other users' questions
+	EditTagsThreshold int
+	// CloseVoteThreshold unlocks voting to close questions
+	CloseVoteThreshold int
+	// SkipReviewThreshold unlocks posting without review. There is no review queue yet, so it
+	// only shows up in the privileges list.
+	SkipReviewThreshold int
+}
+
+// Bounty configuration structure
+type BountyConfig struct {
+	// MinAmount and MaxAmount bound the reputation a question author can offer
+	MinAmount int
+	MaxAmount int
+	// Duration is how long a bounty stays open before it expires
+	Duration time.Duration
+	// SweepInterval is how often expired bounties are settled
+	SweepInterval time.Duration
+}
+
+// Question configuration structure
+type QuestionsConfig struct {
+	// CloseVotesRequired is how many close votes close a question and how many reopen votes
+	// reopen it. A moderator's vote is enough on its own.
+	CloseVotesRequired int
+}
+
+// View counting configuration structure
+type ViewsConfig struct {
+	// Window is how long repeat views by the same viewer are not counted again
+	Window time.Duration
+	// FlushInterval is how often the views buffered in Redis are added to the database
+	FlushInterval time.Duration
+}
+
+// Registered clients
+const (
+	ClientWeb     = "web"
+	ClientIOS     = "ios"
+	ClientAndroid = "android"
+)
+
+// JWT signing configuration structure
+type JWTConfig struct {
+	// Algorithm is one of HS256, RS256 or EdDSA
+	Algorithm string
+	// KeysDir holds PEM encoded private keys named <kid>.pem. When empty, keys are kept in memory only
+	KeysDir string
+	// RotationInterval is how long a signing key stays active before a new one is generated
+	RotationInterval time.Duration
+	// KeyRetention is how long a retired key is still accepted for verification
+	KeyRetention time.Duration
+}
+
+// Supported JWT signing algorithms
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	defaultJWTSecret  = "dummy"
+	minJWTSecretBytes = 32
+)
+
 // getEnvWithDefault retrieves an environment variable or returns a default value if not set.
 // It logs whether the actual environment variable was used or if it fell back to the default.
 func getEnv(key, defaultValue string) string {
@@ -51,6 +240,51 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvDuration retrieves an environment variable as a time.Duration or returns a default value if unset or invalid.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key, defaultValue.String())
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Warn("Invalid duration for environment variable, using default", "key", key, "default", defaultValue.String())
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvInt retrieves an environment variable as an int or returns a default value if unset or invalid.
+func getEnvInt(key string, defaultValue int) int {
+	value := getEnv(key, strconv.Itoa(defaultValue))
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Warn("Invalid integer for environment variable, using default", "key", key, "default", defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvBool retrieves an environment variable as a bool or returns a default value if unset or invalid.
+func getEnvBool(key string, defaultValue bool) bool {
+	value := getEnv(key, strconv.FormatBool(defaultValue))
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Warn("Invalid boolean for environment variable, using default", "key", key, "default", defaultValue)
+		return defaultValue
+	}
+	return enabled
+}
+
+// getEnvList retrieves a comma separated environment variable or returns the default values if not set.
+func getEnvList(key string, defaultValue []string) []string {
+	value := getEnv(key, strings.Join(defaultValue, ","))
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 // New creates and initializes a new Config instance by loading environment variables.
 // It attempts to load from a .env file first, then reads required and optional environment variables.
 // Returns a fully configured Config struct or an error if required variables are missing.
@@ -62,6 +296,7 @@ func New() (*Config, error) {
 		logger.Info("Successfully loaded .env file")
 	}
 
+	environment := getEnv("ENVIRONMENT", "development")
 	port := getEnv("PORT", "8080")
 	host := getEnv("HOST", "localhost")
 	dbHost := getEnv("PGHOST", "")
@@ -70,25 +305,26 @@ func New() (*Config, error) {
 	dbPassword := getEnv("PGPASSWORD", "")
 	dbSslMode := getEnv("PGSSLMODE", "disable")
 	dbChannelBinding := getEnv("PGCHANNELBINDING", "disable")
-	oauthClientID := getEnv("OAUTH_CLIENT_ID", "")
-	oauthClientSecret := getEnv("OAUTH_CLIENT_SECRET", "")
-	oauthRedirectURI := getEnv("OAUTH_REDIRECT_URI", "")
 	redisURL := getEnv("REDIS_URL", "redis://localhost:6379")
-	jwtSecret := getEnv("JWT_SECRET", "dummy")
+	jwtSecret := getEnv("JWT_SECRET", defaultJWTSecret)
 	jwtIssuer := getEnv("JWT_ISSUER", "pitstop")
+	jwtAlgorithm := getEnv("JWT_ALGORITHM", JWTAlgorithmHS256)
+	jwtKeysDir := getEnv("JWT_KEYS_DIR", "")
+	jwtRotationInterval := getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+	jwtKeyRetention := getEnvDuration("JWT_KEY_RETENTION", 91*24*time.Hour)
 	frontendURL := getEnv("FRONTEND_URL", "http://localhost:3000")
 
-	logger.Info("Configuration loaded successfully",
-		"server_port", port,
-		"database_configured", true)
-
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
-			Port:        port,
-			Host:        host,
-			JWTSecret:   jwtSecret,
-			JWTIssuer:   jwtIssuer,
-			FrontendURL: frontendURL,
+			Environment:    environment,
+			Port:           port,
+			Host:           host,
+			JWTSecret:      jwtSecret,
+			JWTIssuer:      jwtIssuer,
+			FrontendURL:    frontendURL,
+			PublicURL:      strings.TrimRight(getEnv("PUBLIC_URL", fmt.Sprintf("http://%s:%s", host, port)), "/"),
+			ProxyHeader:    getEnv("PROXY_HEADER", ""),
+			TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:           dbHost,
@@ -98,20 +334,308 @@ func New() (*Config, error) {
 			SslMode:        dbSslMode,
 			ChannelBinding: dbChannelBinding,
 		},
-		OAuth: oauth2.Config{
-			ClientID:     oauthClientID,
-			ClientSecret: oauthClientSecret,
-			RedirectURL:  oauthRedirectURI,
-			Scopes:       []string{"https://www.googleapis.com/auth/userinfo.profile", "https://www.googleapis.com/auth/userinfo.email"},
-			Endpoint: oauth2.Endpoint{
-				AuthURL:  "https://accounts.google.com/o/oauth2/auth",
-				TokenURL: "https://oauth2.googleapis.com/token",
-			},
-		},
+		OAuth: loadOAuthProviders(),
 		Redis: RedisConfig{
 			URL: redisURL,
 		},
-	}, nil
+		JWT: JWTConfig{
+			Algorithm:        jwtAlgorithm,
+			KeysDir:          jwtKeysDir,
+			RotationInterval: jwtRotationInterval,
+			KeyRetention:     jwtKeyRetention,
+		},
+		Clients: loadClients(frontendURL),
+		MFA: MFAConfig{
+			Issuer:          getEnv("MFA_ISSUER", "Pitstop"),
+			EncryptionKey:   getEnv("MFA_ENCRYPTION_KEY", ""),
+			RequiredRoles:   getEnvList("MFA_REQUIRED_ROLES", nil),
+			PendingTokenTTL: getEnvDuration("MFA_PENDING_TOKEN_TTL", 5*time.Minute),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "Pitstop <no-reply@pitstop.local>"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			LocalPath: getEnv("STORAGE_LOCAL_PATH", "./storage"),
+		},
+		Users: UsersConfig{
+			UsernameChangeCooldown: getEnvDuration("USERNAME_CHANGE_COOLDOWN", 30*24*time.Hour),
+			UsernameHoldPeriod:     getEnvDuration("USERNAME_HOLD_PERIOD", 90*24*time.Hour),
+			DeletionGracePeriod:    getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 14*24*time.Hour),
+			DeletionSweepInterval:  getEnvDuration("ACCOUNT_DELETION_SWEEP_INTERVAL", time.Hour),
+			ExportLinkTTL:          getEnvDuration("DATA_EXPORT_LINK_TTL", 7*24*time.Hour),
+			ExportCooldown:         getEnvDuration("DATA_EXPORT_COOLDOWN", 24*time.Hour),
+			SuggestionsCacheTTL:    getEnvDuration("USER_SUGGESTIONS_CACHE_TTL", 6*time.Hour),
+		},
+		Reputation: ReputationConfig{
+			QuestionUpvoted:     getEnvInt("REPUTATION_QUESTION_UPVOTED", 5),
+			AnswerUpvoted:       getEnvInt("REPUTATION_ANSWER_UPVOTED", 10),
+			AnswerAccepted:      getEnvInt("REPUTATION_ANSWER_ACCEPTED", 15),
+			AcceptedAnswer:      getEnvInt("REPUTATION_ACCEPTED_ANSWER", 2),
+			Downvoted:           getEnvInt("REPUTATION_DOWNVOTED", -2),
+			DownvoteCast:        getEnvInt("REPUTATION_DOWNVOTE_CAST", -1),
+			SpamRemoved:         getEnvInt("REPUTATION_SPAM_REMOVED", -100),
+			EditTagsThreshold:   getEnvInt("PRIVILEGE_EDIT_TAGS_REPUTATION", 500),
+			CloseVoteThreshold:  getEnvInt("PRIVILEGE_CLOSE_VOTE_REPUTATION", 1000),
+			SkipReviewThreshold: getEnvInt("PRIVILEGE_SKIP_REVIEW_REPUTATION", 2000),
+		},
+		Bounties: BountyConfig{
+			MinAmount:     getEnvInt("BOUNTY_MIN_AMOUNT", 50),
+			MaxAmount:     getEnvInt("BOUNTY_MAX_AMOUNT", 500),
+			Duration:      getEnvDuration("BOUNTY_DURATION", 7*24*time.Hour),
+			SweepInterval: getEnvDuration("BOUNTY_SWEEP_INTERVAL", 15*time.Minute),
+		},
+		Questions: QuestionsConfig{
+			CloseVotesRequired: getEnvInt("QUESTION_CLOSE_VOTES_REQUIRED", 3),
+		},
+		Views: ViewsConfig{
+			Window:        getEnvDuration("VIEW_WINDOW", 24*time.Hour),
+			FlushInterval: getEnvDuration("VIEW_FLUSH_INTERVAL", time.Minute),
+		},
+	}
+
+	// Features that email users a link are on by default only when the email can reach them
+	cfg.Mail.MagicLinkEnabled = getEnvBool("MAGIC_LINK_ENABLED", cfg.CanSendMail())
+	cfg.Mail.DataExportEnabled = getEnvBool("DATA_EXPORT_ENABLED", cfg.CanSendMail())
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Configuration loaded successfully",
+		"server_port", port,
+		"environment", environment,
+		"jwt_algorithm", jwtAlgorithm,
+		"database_configured", true)
+
+	return cfg, nil
+}
+
+// loadOAuthProviders reads the configuration of every OAuth provider that has a client ID set.
+// Google keeps reading the original OAUTH_* variables so existing deployments are unaffected.
+func loadOAuthProviders() map[string]OAuthProviderConfig {
+	providers := make(map[string]OAuthProviderConfig)
+
+	if clientID := getEnv("OAUTH_CLIENT_ID", ""); clientID != "" {
+		providers[OAuthProviderGoogle] = OAuthProviderConfig{
+			Config: oauth2.Config{
+				ClientID:     clientID,
+				ClientSecret: getEnv("OAUTH_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("OAUTH_REDIRECT_URI", ""),
+				Scopes:       []string{"https://www.googleapis.com/auth/userinfo.profile", "https://www.googleapis.com/auth/userinfo.email"},
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  getEnv("GOOGLE_AUTH_URL", "https://accounts.google.com/o/oauth2/auth"),
+					TokenURL: getEnv("GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+				},
+			},
+			UserInfoURL: getEnv("GOOGLE_USERINFO_URL", "https://www.googleapis.com/oauth2/v2/userinfo"),
+		}
+	}
+
+	if clientID := getEnv("GITHUB_CLIENT_ID", ""); clientID != "" {
+		providers[OAuthProviderGitHub] = OAuthProviderConfig{
+			Config: oauth2.Config{
+				ClientID:     clientID,
+				ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("GITHUB_REDIRECT_URI", ""),
+				Scopes:       []string{"read:user", "user:email"},
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  getEnv("GITHUB_AUTH_URL", "https://github.com/login/oauth/authorize"),
+					TokenURL: getEnv("GITHUB_TOKEN_URL", "https://github.com/login/oauth/access_token"),
+				},
+			},
+			UserInfoURL: getEnv("GITHUB_USERINFO_URL", "https://api.github.com/user"),
+			EmailsURL:   getEnv("GITHUB_EMAILS_URL", "https://api.github.com/user/emails"),
+		}
+	}
+
+	if clientID := getEnv("FACEBOOK_CLIENT_ID", ""); clientID != "" {
+		providers[OAuthProviderFacebook] = OAuthProviderConfig{
+			Config: oauth2.Config{
+				ClientID:     clientID,
+				ClientSecret: getEnv("FACEBOOK_CLIENT_SECRET", ""),
+				RedirectURL:  getEnv("FACEBOOK_REDIRECT_URI", ""),
+				Scopes:       []string{"email", "public_profile"},
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  getEnv("FACEBOOK_AUTH_URL", "https://www.facebook.com/v19.0/dialog/oauth"),
+					TokenURL: getEnv("FACEBOOK_TOKEN_URL", "https://graph.facebook.com/v19.0/oauth/access_token"),
+				},
+			},
+			UserInfoURL: getEnv("FACEBOOK_USERINFO_URL", "https://graph.facebook.com/v19.0/me?fields=id,email,first_name,last_name,picture.type(large)"),
+		}
+	}
+
+	return providers
+}
+
+// loadClients reads the registered web and mobile clients. Mobile apps cannot keep a secret,
+// so they must use PKCE and receive shorter-lived access tokens.
+func loadClients(frontendURL string) map[string]ClientConfig {
+	return map[string]ClientConfig{
+		ClientWeb: {
+			ID:              ClientWeb,
+			RedirectURIs:    getEnvList("CLIENT_WEB_REDIRECT_URIS", []string{frontendURL + "/auth/callback"}),
+			RequirePKCE:     false,
+			AccessTokenTTL:  getEnvDuration("CLIENT_WEB_ACCESS_TOKEN_TTL", 30*time.Minute),
+			RefreshTokenTTL: getEnvDuration("CLIENT_WEB_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		},
+		ClientIOS: {
+			ID:              ClientIOS,
+			RedirectURIs:    getEnvList("CLIENT_IOS_REDIRECT_URIS", []string{"pitstop://auth/callback"}),
+			RequirePKCE:     true,
+			AccessTokenTTL:  getEnvDuration("CLIENT_IOS_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("CLIENT_IOS_REFRESH_TOKEN_TTL", 90*24*time.Hour),
+		},
+		ClientAndroid: {
+			ID:              ClientAndroid,
+			RedirectURIs:    getEnvList("CLIENT_ANDROID_REDIRECT_URIS", []string{"pitstop://auth/callback"}),
+			RequirePKCE:     true,
+			AccessTokenTTL:  getEnvDuration("CLIENT_ANDROID_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvDuration("CLIENT_ANDROID_REFRESH_TOKEN_TTL", 90*24*time.Hour),
+		},
+	}
+}
+
+// Client returns the registered client with the given ID
+func (c *Config) Client(id string) (ClientConfig, bool) {
+	client, ok := c.Clients[id]
+	return client, ok
+}
+
+// AllowsRedirectURI reports whether the redirect URI is registered for the client. Matching is exact.
+func (c ClientConfig) AllowsRedirectURI(redirectURI string) bool {
+	for _, allowed := range c.RedirectURIs {
+		if allowed == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresMFA reports whether users with the role must use two-factor authentication
+func (c *Config) RequiresMFA(role string) bool {
+	for _, required := range c.MFA.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSendMail reports whether email reaches users. The log driver only counts outside production,
+// where developers read the links from the log.
+func (c *Config) CanSendMail() bool {
+	return c.Mail.Driver == "smtp" || (c.Mail.Driver == "log" && !c.IsProduction())
+}
+
+// IsProduction reports whether the API is running in a production environment
+func (c *Config) IsProduction() bool {
+	return c.Server.Environment == "production" || c.Server.Environment == "prod"
+}
+
+// validate checks the loaded configuration for values that are unsafe or unsupported
+func (c *Config) validate() error {
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		return errors.New("PROXY_HEADER requires TRUSTED_PROXIES, otherwise any client can set its own IP")
+	}
+
+	switch c.JWT.Algorithm {
+	case JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+	default:
+		return errors.New("JWT_ALGORITHM must be one of HS256, RS256 or EdDSA")
+	}
+
+	if c.JWT.RotationInterval <= 0 {
+		return errors.New("JWT_KEY_ROTATION_INTERVAL must be positive")
+	}
+
+	if c.Users.DeletionSweepInterval <= 0 {
+		return errors.New("ACCOUNT_DELETION_SWEEP_INTERVAL must be positive")
+	}
+
+	for id, client := range c.Clients {
+		if len(client.RedirectURIs) == 0 {
+			return fmt.Errorf("client %s must have at least one redirect URI", id)
+		}
+		for _, redirectURI := range client.RedirectURIs {
+			if u, err := url.Parse(redirectURI); err != nil || u.Scheme == "" {
+				return fmt.Errorf("client %s has an invalid redirect URI %q", id, redirectURI)
+			}
+		}
+		if client.AccessTokenTTL <= 0 || client.RefreshTokenTTL <= 0 {
+			return fmt.Errorf("client %s token lifetimes must be positive", id)
+		}
+		// Refresh tokens are verified with the key that signed them, so a retired key has to be
+		// kept for as long as the tokens it signed live
+		if client.RefreshTokenTTL > c.JWT.KeyRetention {
+			return fmt.Errorf("JWT_KEY_RETENTION must be at least client %s's refresh token lifetime of %s", id, client.RefreshTokenTTL)
+		}
+	}
+
+	switch c.Mail.Driver {
+	case "log":
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			return errors.New("SMTP_HOST must be set when MAIL_DRIVER is smtp")
+		}
+	default:
+		return errors.New("MAIL_DRIVER must be log or smtp")
+	}
+
+	// Sign-in links and export downloads are emailed, so they cannot work without a mail server
+	if !c.CanSendMail() {
+		if c.Mail.MagicLinkEnabled || c.Mail.DataExportEnabled {
+			return errors.New("MAGIC_LINK_ENABLED and DATA_EXPORT_ENABLED require MAIL_DRIVER=smtp in production")
+		}
+		logger.Warn("No SMTP server is configured; magic-link sign-in and data exports are disabled")
+	}
+
+	if c.Storage.Driver != "local" {
+		return errors.New("STORAGE_DRIVER must be local")
+	}
+
+	if c.Users.ExportLinkTTL <= 0 {
+		return errors.New("DATA_EXPORT_LINK_TTL must be positive")
+	}
+
+	if c.Users.SuggestionsCacheTTL <= 0 {
+		return errors.New("USER_SUGGESTIONS_CACHE_TTL must be positive")
+	}
+
+	if c.Reputation.EditTagsThreshold < 0 || c.Reputation.CloseVoteThreshold < 0 || c.Reputation.SkipReviewThreshold < 0 {
+		return errors.New("privilege reputation thresholds must not be negative")
+	}
+
+	if c.Bounties.MinAmount < 1 || c.Bounties.MaxAmount < c.Bounties.MinAmount {
+		return errors.New("BOUNTY_MIN_AMOUNT must be positive and not above BOUNTY_MAX_AMOUNT")
+	}
+	if c.Bounties.Duration <= 0 || c.Bounties.SweepInterval <= 0 {
+		return errors.New("BOUNTY_DURATION and BOUNTY_SWEEP_INTERVAL must be positive")
+	}
+
+	if c.Questions.CloseVotesRequired < 1 {
+		return errors.New("QUESTION_CLOSE_VOTES_REQUIRED must be at least 1")
+	}
+
+	if c.Views.Window <= 0 || c.Views.FlushInterval <= 0 {
+		return errors.New("VIEW_WINDOW and VIEW_FLUSH_INTERVAL must be positive")
+	}
+
+	if c.IsProduction() && c.MFA.EncryptionKey == "" {
+		logger.Warn("MFA_ENCRYPTION_KEY is not set; TOTP secrets are encrypted with a key derived from JWT_SECRET")
+	}
+
+	// Refuse to run production with the default or a short HMAC secret
+	if c.IsProduction() && (c.Server.JWTSecret == defaultJWTSecret || len(c.Server.JWTSecret) < minJWTSecretBytes) {
+		return errors.New("JWT_SECRET must be set to a random value of at least 32 bytes in production")
+	}
+
+	return nil
 }
 
 // Global config instance
@@ -5,8 +5,11 @@ import (
 
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/logger"
+	authDomain "github.com/topboyasante/pitstop/internal/modules/auth/domain"
+	badgeDomain "github.com/topboyasante/pitstop/internal/modules/badge/domain"
 	postDomain "github.com/topboyasante/pitstop/internal/modules/post/domain"
 	questionDomain "github.com/topboyasante/pitstop/internal/modules/question/domain"
+	reputationDomain "github.com/topboyasante/pitstop/internal/modules/reputation/domain"
 	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -42,14 +45,38 @@ func Init(cfg *config.Config) (*gorm.DB, error) {
 func runMigrations(db *gorm.DB) error {
 	logger.Info("Running database migrations")
 
+	if err := migratePostComments(db); err != nil {
+		logger.Error("Failed to migrate post comments", "error", err)
+		return err
+	}
+
+	if err := dedupeAcceptedAnswers(db); err != nil {
+		logger.Error("Failed to dedupe accepted answers", "error", err)
+		return err
+	}
+
 	err := db.AutoMigrate(
 		&userDomain.User{},
 		&userDomain.Follow{},
+		&userDomain.FollowRequest{},
+		&userDomain.Block{},
+		&userDomain.Mute{},
+		&userDomain.UserIdentity{},
+		&userDomain.UsernameRedirect{},
+		&userDomain.DataExport{},
+		&authDomain.PersonalAccessToken{},
+		&authDomain.MFAEnrollment{},
+		&authDomain.RecoveryCode{},
 		&postDomain.Post{},
 		&postDomain.Comment{},
 		&postDomain.Like{},
 		&questionDomain.Question{},
 		&questionDomain.Answer{},
+		&questionDomain.Vote{},
+		&questionDomain.Bounty{},
+		&questionDomain.CloseVote{},
+		&reputationDomain.ReputationEntry{},
+		&badgeDomain.UserBadge{},
 	)
 
 	if err != nil {
@@ -57,6 +84,96 @@ func runMigrations(db *gorm.DB) error {
 		return err
 	}
 
+	if err := backfillUserIdentities(db); err != nil {
+		logger.Error("Failed to backfill user identities", "error", err)
+		return err
+	}
+
+	if err := backfillQuestionsAnswered(db); err != nil {
+		logger.Error("Failed to backfill answered questions", "error", err)
+		return err
+	}
+
+	if err := seedDeletedUser(db); err != nil {
+		logger.Error("Failed to create the deleted user placeholder", "error", err)
+		return err
+	}
+
 	logger.Info("Database migrations completed successfully")
 	return nil
 }
+
+// migratePostComments moves comments created before comments became polymorphic from
+// comments.post_id to commentable_id/commentable_type. It runs before AutoMigrate, which
+// cannot add the new NOT NULL columns to a table that already has rows, and does nothing
+// once post_id is gone.
+func migratePostComments(db *gorm.DB) error {
+	if !db.Migrator().HasTable("comments") || !db.Migrator().HasColumn("comments", "post_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`ALTER TABLE comments ADD COLUMN IF NOT EXISTS commentable_id text`,
+			`ALTER TABLE comments ADD COLUMN IF NOT EXISTS commentable_type varchar(20)`,
+			`UPDATE comments SET commentable_id = post_id, commentable_type = 'post' WHERE commentable_id IS NULL`,
+			`ALTER TABLE comments ALTER COLUMN commentable_id SET NOT NULL`,
+			`ALTER TABLE comments ALTER COLUMN commentable_type SET NOT NULL`,
+			`ALTER TABLE comments DROP COLUMN post_id`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillUserIdentities creates an identity for every user that signed up before
+// identities were tracked separately. It is safe to run on every start.
+func backfillUserIdentities(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO user_identities (id, user_id, provider, provider_id, email, email_verified, created_at, updated_at)
+		SELECT gen_random_uuid()::text, u.id, u.provider, u.provider_id, u.email, false, u.created_at, NOW()
+		FROM users u
+		WHERE u.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM user_identities i WHERE i.user_id = u.id)
+		ON CONFLICT (provider, provider_id) DO NOTHING`).Error
+}
+
+// dedupeAcceptedAnswers keeps only the most recently accepted answer of any question that has
+// more than one. It runs before AutoMigrate, which cannot create the unique index on accepted
+// answers while duplicates exist, and does nothing once there are none.
+func dedupeAcceptedAnswers(db *gorm.DB) error {
+	if !db.Migrator().HasTable("answers") {
+		return nil
+	}
+
+	return db.Exec(`
+		UPDATE answers SET is_accepted = false
+		WHERE is_accepted = true
+		  AND id NOT IN (
+			SELECT DISTINCT ON (question_id) id
+			FROM answers
+			WHERE is_accepted = true
+			ORDER BY question_id, updated_at DESC, id
+		  )`).Error
+}
+
+// backfillQuestionsAnswered brings questions.is_answered in line with the accepted answers, for
+// questions from before the column was kept up to date. It is safe to run on every start.
+func backfillQuestionsAnswered(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE questions q
+		SET is_answered = EXISTS (SELECT 1 FROM answers a WHERE a.question_id = q.id AND a.is_accepted = true)
+		WHERE q.is_answered IS DISTINCT FROM EXISTS (SELECT 1 FROM answers a WHERE a.question_id = q.id AND a.is_accepted = true)`).Error
+}
+
+// seedDeletedUser creates the placeholder account that anonymized content belongs to
+func seedDeletedUser(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO users (id, provider, provider_id, username, email, display_name, role, created_at, updated_at)
+		VALUES (?, 'system', 'deleted', ?, 'deleted@pitstop.invalid', 'Deleted user', 'user', NOW(), NOW())
+		ON CONFLICT (id) DO NOTHING`, userDomain.DeletedUserID, userDomain.DeletedUserUsername).Error
+}
@@ -0,0 +1,456 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+)
+
+const rsaKeyBits = 2048
+
+// kidTimeLayout is the UTC timestamp a generated kid starts with
+const kidTimeLayout = "20060102T150405"
+
+// SigningKey is a single private key identified by its kid
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+// JWK represents a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Manager holds every key that may verify tokens and the one currently used to sign them.
+// With HS256 it wraps the shared JWT secret and publishes no public keys.
+type Manager struct {
+	cfg *config.Config
+
+	mutex    sync.RWMutex
+	keys     map[string]*SigningKey
+	activeID string
+}
+
+// NewManager creates a key manager and loads or generates the initial signing key
+func NewManager(cfg *config.Config) (*Manager, error) {
+	m := &Manager{
+		cfg:  cfg,
+		keys: make(map[string]*SigningKey),
+	}
+
+	if m.isHMAC() {
+		logger.Info("JWT signing configured with shared secret", "algorithm", cfg.JWT.Algorithm)
+		return m, nil
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	if err := m.RotateIfDue(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Sign signs the claims with the active key and sets the kid header
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	if m.isHMAC() {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.cfg.Server.JWTSecret))
+	}
+
+	m.mutex.RLock()
+	key := m.keys[m.activeID]
+	m.mutex.RUnlock()
+
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc resolves the verification key for a token from its kid header
+func (m *Manager) Keyfunc(token *jwt.Token) (any, error) {
+	if m.isHMAC() {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(m.cfg.Server.JWTSecret), nil
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, errors.New("missing kid header")
+	}
+
+	m.mutex.RLock()
+	key := m.keys[kid]
+	m.mutex.RUnlock()
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Private.Public(), nil
+}
+
+// ValidMethods returns the algorithms accepted when parsing tokens
+func (m *Manager) ValidMethods() []string {
+	return []string{m.cfg.JWT.Algorithm}
+}
+
+// JWKS returns the public half of every key still accepted for verification
+func (m *Manager) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if m.isHMAC() {
+		return set
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, key := range m.sortedKeys() {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Algorithm,
+		}
+
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// RotateIfDue generates a new signing key when the active one is older than the rotation interval,
+// and drops retired keys once they are past the retention window
+func (m *Manager) RotateIfDue() error {
+	if m.isHMAC() {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	active := m.keys[m.activeID]
+	if active == nil || time.Since(active.CreatedAt) >= m.cfg.JWT.RotationInterval {
+		key, err := generateKey(m.cfg.JWT.Algorithm)
+		if err != nil {
+			return fmt.Errorf("failed to generate signing key: %w", err)
+		}
+
+		if err := m.persist(key); err != nil {
+			return err
+		}
+
+		m.keys[key.ID] = key
+		m.activeID = key.ID
+
+		logger.Info("JWT signing key rotated",
+			"event", "auth.key_rotated",
+			"kid", key.ID,
+			"algorithm", key.Algorithm)
+	}
+
+	m.prune()
+	return nil
+}
+
+// StartRotation checks for a due rotation on the given interval until stop is closed.
+// Keys written by other instances to the shared keys directory are picked up on each tick.
+func (m *Manager) StartRotation(interval time.Duration, stop <-chan struct{}) {
+	if m.isHMAC() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.load(); err != nil {
+					logger.Error("Failed to reload JWT signing keys", "error", err)
+				}
+				if err := m.RotateIfDue(); err != nil {
+					logger.Error("Failed to rotate JWT signing key", "error", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func (m *Manager) isHMAC() bool {
+	return m.cfg.JWT.Algorithm == config.JWTAlgorithmHS256
+}
+
+// load reads <kid>.pem files from the keys directory and makes the newest one active
+func (m *Manager) load() error {
+	if m.cfg.JWT.KeysDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(m.cfg.JWT.KeysDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read keys directory: %w", err)
+	}
+
+	loaded := make(map[string]*SigningKey)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+
+		path := filepath.Join(m.cfg.JWT.KeysDir, entry.Name())
+		key, err := readKey(path)
+		if err != nil {
+			return fmt.Errorf("failed to load signing key %s: %w", entry.Name(), err)
+		}
+		if key.Algorithm != m.cfg.JWT.Algorithm {
+			logger.Warn("Skipping signing key with different algorithm", "kid", key.ID, "algorithm", key.Algorithm)
+			continue
+		}
+
+		loaded[key.ID] = key
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, key := range loaded {
+		if _, exists := m.keys[id]; !exists {
+			m.keys[id] = key
+		}
+	}
+
+	keys := m.sortedKeys()
+	if len(keys) > 0 {
+		m.activeID = keys[0].ID
+	}
+
+	return nil
+}
+
+// persist writes a newly generated key to the keys directory so other instances can use it
+func (m *Manager) persist(key *SigningKey) error {
+	if m.cfg.JWT.KeysDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.cfg.JWT.KeysDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	path := filepath.Join(m.cfg.JWT.KeysDir, key.ID+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	return nil
+}
+
+// prune removes keys that retired longer ago than the retention window. Must be called with the lock held.
+func (m *Manager) prune() {
+	keys := m.sortedKeys()
+	for i := 1; i < len(keys); i++ {
+		// A key retires when the next newer key is created
+		retiredAt := keys[i-1].CreatedAt
+		if time.Since(retiredAt) <= m.cfg.JWT.KeyRetention {
+			continue
+		}
+
+		delete(m.keys, keys[i].ID)
+		if m.cfg.JWT.KeysDir != "" {
+			_ = os.Remove(filepath.Join(m.cfg.JWT.KeysDir, keys[i].ID+".pem"))
+		}
+
+		logger.Info("JWT signing key retired",
+			"event", "auth.key_retired",
+			"kid", keys[i].ID)
+	}
+}
+
+// sortedKeys returns keys ordered newest first. Must be called with the lock held.
+func (m *Manager) sortedKeys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// generateKey creates a new private key for the algorithm with a timestamped kid
+func generateKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case config.JWTAlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case config.JWTAlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return &SigningKey{
+		ID:        fmt.Sprintf("%s-%s", now.Format(kidTimeLayout), base64.RawURLEncoding.EncodeToString(suffix)),
+		Algorithm: algorithm,
+		Private:   private,
+		CreatedAt: now,
+	}, nil
+}
+
+// readKey parses a PKCS#8 PEM private key file. The file name is the kid, and the creation time
+// comes from the timestamp the kid starts with, so copying or restoring the keys directory does
+// not reset a key's age. Keys named by hand without that timestamp fall back to the file's mtime.
+func readKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), ".pem")
+	createdAt, ok := kidTime(id)
+	if !ok {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		createdAt = info.ModTime()
+	}
+
+	key := &SigningKey{
+		ID:        id,
+		CreatedAt: createdAt,
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = config.JWTAlgorithmRS256
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Algorithm = config.JWTAlgorithmEdDSA
+		key.Private = private
+	default:
+		return nil, errors.New("unsupported private key type")
+	}
+
+	return key, nil
+}
+
+// kidTime parses the creation time from the timestamp that generated kids start with
+func kidTime(id string) (time.Time, bool) {
+	stamp, _, _ := strings.Cut(id, "-")
+	createdAt, err := time.Parse(kidTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == config.JWTAlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// Global key manager instance
+var GlobalManager *Manager
+
+// InitGlobal initializes the global key manager instance
+func InitGlobal(cfg *config.Config) error {
+	var err error
+	GlobalManager, err = NewManager(cfg)
+	return err
+}
+
+// Get returns the global key manager instance
+func Get() *Manager {
+	return GlobalManager
+}
@@ -0,0 +1,134 @@
+// Package mailer sends transactional email through a configurable driver.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"regexp"
+	"strings"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+)
+
+// Supported mail drivers
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// Message is a single email
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by MAIL_DRIVER
+func New(cfg *config.Config) (Mailer, error) {
+	switch cfg.Mail.Driver {
+	case DriverLog:
+		return &LogMailer{redactLinks: cfg.IsProduction()}, nil
+	case DriverSMTP:
+		return &SMTPMailer{cfg: cfg.Mail}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Mail.Driver)
+	}
+}
+
+// linkPattern matches URLs, including app schemes used as mobile redirect URIs
+var linkPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://\S+`)
+
+// LogMailer writes email to the log instead of sending it. Meant for local development, where
+// developers follow sign-in links from the log.
+type LogMailer struct {
+	// redactLinks removes links from the logged text. Set in production, where sign-in and
+	// download links carry bearer tokens that would let anyone reading the logs use them.
+	redactLinks bool
+}
+
+// Send logs the message
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	text := msg.Text
+	if m.redactLinks {
+		text = linkPattern.ReplaceAllString(text, "[link removed]")
+	}
+	logger.Info("Email not sent (log mail driver)",
+		"event", "mail.logged",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"text", text)
+	return nil
+}
+
+// SMTPMailer sends email through an SMTP server
+type SMTPMailer struct {
+	cfg config.MailConfig
+}
+
+// Send delivers the message as a multipart text and HTML email
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(m.cfg.SMTPHost, m.cfg.SMTPPort)
+
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+	}
+
+	// The envelope sender is the bare address; the From header keeps the display name
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+
+	if err := smtp.SendMail(addr, auth, from.Address, []string{msg.To}, buildMIME(m.cfg.From, msg)); err != nil {
+		logger.Error("Failed to send email",
+			"event", "mail.send_failed",
+			"to", msg.To,
+			"subject", msg.Subject,
+			"error", err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	logger.Info("Email sent",
+		"event", "mail.sent",
+		"to", msg.To,
+		"subject", msg.Subject)
+	return nil
+}
+
+// buildMIME renders the message with a text part and, when present, an HTML alternative
+func buildMIME(from string, msg Message) []byte {
+	const boundary = "pitstop-mail-boundary"
+
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(msg.Text)
+		return []byte(b.String())
+	}
+
+	b.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Text + "\r\n")
+	b.WriteString("--" + boundary + "\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.HTML + "\r\n")
+	b.WriteString("--" + boundary + "--\r\n")
+	return []byte(b.String())
+}
@@ -1,16 +1,124 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/core/response"
 	"github.com/topboyasante/pitstop/internal/shared/utils"
 )
 
-// JWTMiddleware validates JWT tokens from Authorization header
+// PersonalTokenPrefix marks bearer tokens that are personal access tokens rather than JWTs
+const PersonalTokenPrefix = "pit_"
+
+// PersonalTokenAudience is stored as the audience for requests authenticated with a personal access token
+const PersonalTokenAudience = "pat"
+
+// MFAPendingAudience marks the short-lived token issued between sign-in and the second factor.
+// It only grants access to the two-factor challenge endpoint.
+const MFAPendingAudience = "mfa_pending"
+
+// ScopeRead allows every read-only request. Writes need "<resource>:write", e.g. "posts:write".
+const ScopeRead = "read"
+
+// ErrTokenRateLimited is returned by a PersonalTokenAuthenticator when the token has used up its rate limit
+var ErrTokenRateLimited = errors.New("personal access token rate limit exceeded")
+
+// TokenPrincipal is the user and scopes behind a personal access token
+type TokenPrincipal struct {
+	UserID  string
+	TokenID string
+	Scopes  []string
+}
+
+// PersonalTokenAuthenticator validates personal access tokens. It is implemented by the auth module
+// and registered at startup so the core middleware does not depend on it.
+type PersonalTokenAuthenticator interface {
+	AuthenticatePersonalToken(ctx context.Context, token string) (*TokenPrincipal, error)
+}
+
+var personalTokens PersonalTokenAuthenticator
+
+// SetPersonalTokenAuthenticator registers the validator used for personal access tokens
+func SetPersonalTokenAuthenticator(authenticator PersonalTokenAuthenticator) {
+	personalTokens = authenticator
+}
+
+// requiredScope returns the scope a personal access token needs for the request.
+// Reads need "read"; writes need "<resource>:write" where resource is the first path segment after /api/v1.
+func requiredScope(c *fiber.Ctx) string {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return ScopeRead
+	}
+
+	path := strings.TrimPrefix(c.Path(), "/api/v1/")
+	resource, _, _ := strings.Cut(path, "/")
+	return resource + ":write"
+}
+
+// authenticatePersonalToken validates a personal access token and stores its principal in the context.
+// When the request must be rejected it writes the error response and returns false.
+func authenticatePersonalToken(c *fiber.Ctx, tokenString string) (bool, error) {
+	if personalTokens == nil {
+		return false, response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token", "Personal access tokens are not enabled")
+	}
+
+	principal, err := personalTokens.AuthenticatePersonalToken(c.Context(), tokenString)
+	if err != nil {
+		if errors.Is(err, ErrTokenRateLimited) {
+			logger.Warn("Personal access token rate limited", "path", c.Path())
+			return false, response.ErrorJSON(c, fiber.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Rate limit exceeded. Try again later.", err.Error())
+		}
+		logger.Warn("Personal access token validation failed", "error", err)
+		return false, response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token", err.Error())
+	}
+
+	scope := requiredScope(c)
+	if !slices.Contains(principal.Scopes, scope) {
+		logger.Warn("Personal access token missing scope", "token_id", principal.TokenID, "scope", scope)
+		return false, response.ErrorJSON(c, fiber.StatusForbidden, "INSUFFICIENT_SCOPE", "Token does not have the required scope", "Required scope: "+scope)
+	}
+
+	c.Locals("userID", principal.UserID)
+	c.Locals("audience", PersonalTokenAudience)
+	c.Locals("tokenID", principal.TokenID)
+	c.Locals("scopes", principal.Scopes)
+	return true, nil
+}
+
+// IsPersonalToken reports whether the request was authenticated with a personal access token
+func IsPersonalToken(c *fiber.Ctx) bool {
+	audience, _ := c.Locals("audience").(string)
+	return audience == PersonalTokenAudience
+}
+
+// InteractiveOnly refuses requests authenticated with a personal access token, whatever its
+// scopes. It guards account-level routes such as deleting the account, linked identities and
+// data exports, which a leaked automation token must not reach. It runs after JWTMiddleware.
+func InteractiveOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if IsPersonalToken(c) {
+			logger.Warn("Personal access token used on an account route", "token_id", c.Locals("tokenID"), "path", c.Path())
+			return response.ErrorJSON(c, fiber.StatusForbidden, "SESSION_REQUIRED", "This action requires a signed-in session", "Personal access tokens cannot be used here")
+		}
+		return c.Next()
+	}
+}
+
+// hasMFAClaim reports whether the session behind the token passed two-factor authentication
+func hasMFAClaim(token *jwt.Token) bool {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return ok && claims["mfa"] == true
+}
+
+// JWTMiddleware validates JWT tokens and personal access tokens from Authorization header
 func JWTMiddleware(config *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		logger.Debug("JWT middleware validating request")
@@ -30,6 +138,15 @@ func JWTMiddleware(config *config.Config) fiber.Handler {
 
 		tokenString := tokenParts[1]
 
+		// Personal access tokens are opaque and checked against their stored hash
+		if strings.HasPrefix(tokenString, PersonalTokenPrefix) {
+			if ok, err := authenticatePersonalToken(c, tokenString); !ok {
+				return err
+			}
+			logger.Debug("JWT middleware accepted personal access token", "userID", c.Locals("userID"))
+			return c.Next()
+		}
+
 		// Validate JWT token
 		token, err := utils.ValidateJWTToken(config, tokenString)
 		if err != nil {
@@ -44,9 +161,21 @@ func JWTMiddleware(config *config.Config) fiber.Handler {
 			return response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_CLAIMS", "Invalid token claims", err.Error())
 		}
 
+		if audience == MFAPendingAudience {
+			logger.Warn("MFA pending token used as access token", "userID", userID)
+			return response.ErrorJSON(c, fiber.StatusUnauthorized, "MFA_REQUIRED", "Two-factor authentication required", "Complete the challenge at /auth/mfa/challenge")
+		}
+
+		// Only tokens issued to a registered client are sessions; magic-link and other purpose tokens are not
+		if _, ok := config.Client(audience); !ok {
+			logger.Warn("Token with non-client audience used as access token", "userID", userID, "audience", audience)
+			return response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_TOKEN", "Invalid or expired token", "Token was not issued for API access")
+		}
+
 		// Store user info in context for route handlers
 		c.Locals("userID", userID)
 		c.Locals("audience", audience)
+		c.Locals("mfa", hasMFAClaim(token))
 
 		logger.Debug("JWT middleware validation successful", "userID", userID, "audience", audience)
 		return c.Next()
@@ -69,6 +198,15 @@ func OptionalJWTMiddleware(config *config.Config) fiber.Handler {
 		}
 
 		tokenString := tokenParts[1]
+		if strings.HasPrefix(tokenString, PersonalTokenPrefix) {
+			// A personal access token that is invalid, rate limited or out of scope is rejected
+			// rather than ignored, so scripts see why their request was not authenticated
+			if ok, err := authenticatePersonalToken(c, tokenString); !ok {
+				return err
+			}
+			return c.Next()
+		}
+
 		token, err := utils.ValidateJWTToken(config, tokenString)
 		if err != nil {
 			// Invalid token, continue without user context
@@ -80,10 +218,15 @@ func OptionalJWTMiddleware(config *config.Config) fiber.Handler {
 			// Invalid claims, continue without user context
 			return c.Next()
 		}
+		if _, ok := config.Client(audience); !ok {
+			// An unfinished sign-in or a purpose token, continue without user context
+			return c.Next()
+		}
 
 		// Store user info in context if token is valid
 		c.Locals("userID", userID)
 		c.Locals("audience", audience)
+		c.Locals("mfa", hasMFAClaim(token))
 
 		logger.Debug("Optional JWT middleware found valid token", "userID", userID)
 		return c.Next()
@@ -17,12 +17,16 @@ func RateLimiter(redisClient *redis.Client) fiber.Handler {
 	// Define public routes that don't require JWT authentication
 	publicRoutes := []string{
 		"/api/v1/auth/google",
-		"/api/v1/auth/google/callback",
+		"/api/v1/auth/github",
+		"/api/v1/auth/facebook",
 		"/api/v1/auth/exchange",
 		"/api/v1/auth/refresh",
+		"/api/v1/auth/mfa/challenge",
+		"/api/v1/auth/magic-link",
 		"/api/v1/docs",
 		"/health",
 		"/docs",
+		"/.well-known",
 	}
 
 	return func(c *fiber.Ctx) error {
@@ -100,9 +100,12 @@ func UnauthorizedError() *APIResponse {
 	return Error("UNAUTHORIZED", "Authentication required", "")
 }
 
-// ForbiddenError creates a forbidden error response
-func ForbiddenError() *APIResponse {
-	return Error("FORBIDDEN", "Access denied", "")
+// ForbiddenError creates a forbidden error response. An empty message falls back to a generic one.
+func ForbiddenError(message string) *APIResponse {
+	if message == "" {
+		message = "Access denied"
+	}
+	return Error("FORBIDDEN", message, "")
 }
 
 // JSON sends a JSON response using Fiber
@@ -151,6 +154,6 @@ func UnauthorizedJSON(c *fiber.Ctx) error {
 }
 
 // ForbiddenJSON sends a forbidden error JSON response
-func ForbiddenJSON(c *fiber.Ctx) error {
-	return JSON(c, fiber.StatusForbidden, ForbiddenError())
+func ForbiddenJSON(c *fiber.Ctx, message string) error {
+	return JSON(c, fiber.StatusForbidden, ForbiddenError(message))
 }
\ No newline at end of file
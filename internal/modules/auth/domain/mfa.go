@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+)
+
+// MFAEnrollment holds a user's TOTP secret. The secret is encrypted at rest and the
+// enrollment only takes effect once ConfirmedAt is set by a first valid code.
+// LastUsedStep is the time step of the last accepted code, so a code cannot be replayed.
+type MFAEnrollment struct {
+	UserID          string     `gorm:"primarykey" json:"user_id"`
+	EncryptedSecret string     `gorm:"not null;size:255" json:"-"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the MFAEnrollment model
+func (MFAEnrollment) TableName() string {
+	return "mfa_enrollments"
+}
+
+// IsConfirmed reports whether the enrollment has been verified and is enforced at sign-in
+func (e *MFAEnrollment) IsConfirmed() bool {
+	return e.ConfirmedAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the authenticator is lost.
+// Only a keyed hash of the code is stored.
+type RecoveryCode struct {
+	ID        string     `gorm:"primarykey" json:"id"`
+	UserID    string     `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for the RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// PersonalAccessToken is a long-lived token a user creates for scripts and integrations.
+// Only a SHA-256 hash of the token is stored; the plain token is shown once on creation.
+//
+// Scopes are stored space separated, e.g. "read posts:write".
+type PersonalAccessToken struct {
+	ID          string     `gorm:"primarykey" json:"id"`
+	UserID      string     `gorm:"not null;index" json:"user_id" validate:"required"`
+	Name        string     `gorm:"not null;size:100" json:"name" validate:"required,max=100"`
+	TokenHash   string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	TokenPrefix string     `gorm:"not null;size:16" json:"token_prefix"`
+	Scopes      string     `gorm:"not null;size:255" json:"scopes"`
+	RateLimit   int        `gorm:"not null" json:"rate_limit"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the PersonalAccessToken model
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// ScopeList returns the token's scopes
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsActive reports whether the token is neither revoked nor expired
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
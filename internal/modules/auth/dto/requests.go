@@ -20,4 +20,34 @@ type UpdateProfileRequest struct {
 	Username string `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 	Bio      string `json:"bio,omitempty" validate:"max=500"`
 	Location string `json:"location,omitempty" validate:"max=100"`
-}
\ No newline at end of file
+}
+// CreateTokenRequest represents a request to create a personal access token
+type CreateTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=read posts:write questions:write users:write"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=365"`
+	RateLimit     int      `json:"rate_limit,omitempty" validate:"omitempty,min=1,max=600"`
+}
+
+// MFACodeRequest carries a TOTP code, or a recovery code where accepted
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// MFAChallengeRequest completes a sign-in that requires two-factor authentication
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// MagicLinkRequest asks for a sign-in link to be emailed
+type MagicLinkRequest struct {
+	Email       string `json:"email" validate:"required,email,max=255"`
+	ClientID    string `json:"client_id,omitempty"`
+	RedirectURI string `json:"redirect_uri,omitempty" validate:"omitempty,max=500"`
+}
+
+// MagicLinkVerifyRequest exchanges the token from an emailed link for a session
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -27,12 +27,20 @@ type AuthURLResponse struct {
 	AuthURL string `json:"auth_url"`
 }
 
-// JWTTokenResponse represents a JWT token response
+// JWTTokenResponse represents a JWT token response. When the user has two-factor authentication
+// enabled, only MFARequired and MFAToken are set until the challenge is completed.
 type JWTTokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
-	ExpiresAt    int64  `json:"expires_at"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresAt    int64  `json:"expires_at,omitempty"`
+	// MFARequired means the sign-in must be completed at /auth/mfa/challenge with MFAToken
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAEnrollmentRequired means the user's role requires two-factor authentication, which is not set up yet
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+	// DeletionCancelled means signing in cancelled a pending account deletion
+	DeletionCancelled bool `json:"deletion_cancelled,omitempty"`
 }
 
 // RefreshTokenRequest represents a refresh token request
@@ -46,8 +54,55 @@ type AuthCodeResponse struct {
 	State string `json:"state"`
 }
 
-// ExchangeCodeRequest represents a code-to-token exchange request
+// AuthorizeRequest represents the client parameters of an OAuth authorization request.
+// ClientID defaults to "web" and RedirectURI to the client's first registered URI.
+type AuthorizeRequest struct {
+	ClientID            string `query:"client_id"`
+	RedirectURI         string `query:"redirect_uri"`
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+}
+
+// ExchangeCodeRequest represents a code-to-token exchange request.
+// CodeVerifier is required when the authorization request carried a code_challenge.
 type ExchangeCodeRequest struct {
-	Code  string `json:"code" validate:"required"`
-	State string `json:"state" validate:"required"`
-}
\ No newline at end of file
+	Code         string `json:"code" validate:"required"`
+	State        string `json:"state" validate:"required"`
+	ClientID     string `json:"client_id,omitempty"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+}
+// TokenResponse represents a personal access token in API responses. The token value is never returned.
+type TokenResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	RateLimit   int        `json:"rate_limit"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedTokenResponse is returned once when a personal access token is created
+type CreatedTokenResponse struct {
+	TokenResponse
+	Token string `json:"token"`
+}
+
+// MFAStatusResponse represents a user's two-factor authentication status
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollmentResponse carries the TOTP secret for a new enrollment. ProvisioningURI can be shown as a QR code.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse carries recovery codes. They are only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -1,14 +1,18 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/keys"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/core/response"
 	"github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/oauth"
 	"github.com/topboyasante/pitstop/internal/modules/auth/service"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
 )
 
 // AuthHandler handles HTTP requests for authentication
@@ -23,32 +27,57 @@ func NewAuthHandler(authService *service.AuthService) *AuthHandler {
 	}
 }
 
-// GoogleAuth initiates Google OAuth authentication
-// @Summary Initiate Google OAuth
-// @Description Get Google OAuth authorization URL
+// ProviderAuth initiates OAuth authentication with the requested provider
+// @Summary Initiate OAuth login
+// @Description Get the authorization URL for an OAuth provider (google, github, facebook)
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param provider path string true "OAuth provider" Enums(google, github, facebook)
+// @Param client_id query string false "Registered client" Enums(web, ios, android)
+// @Param redirect_uri query string false "Registered redirect URI for the client"
+// @Param code_challenge query string false "PKCE S256 code challenge (required for ios and android)"
+// @Param code_challenge_method query string false "PKCE method" Enums(S256)
 // @Success 200 {object} response.APIResponse
-// @Router /auth/google [get]
-func (h *AuthHandler) GoogleAuth(c *fiber.Ctx) error {
-	authURL := h.authService.Authenticate()
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /auth/{provider} [get]
+func (h *AuthHandler) ProviderAuth(c *fiber.Ctx) error {
+	provider := c.Params("provider")
+
+	var req dto.AuthorizeRequest
+	if err := c.QueryParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid query parameters", err.Error())
+	}
+
+	authURL, err := h.authService.Authenticate(provider, req)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			return response.NotFoundJSON(c, "OAuth provider")
+		}
+		if isClientError(err) {
+			return response.ValidationErrorJSON(c, "Invalid authorization request", err.Error())
+		}
+		logger.Error("Failed to initiate OAuth", "provider", provider, "error", err)
+		return response.InternalErrorJSON(c, "Failed to initiate OAuth")
+	}
 
 	return response.SuccessJSON(c, dto.AuthURLResponse{
 		AuthURL: authURL,
-	}, "Google OAuth URL generated successfully")
+	}, "OAuth URL generated successfully")
 }
 
-// GoogleCallback handles Google OAuth callback
-// @Summary Handle Google OAuth callback
-// @Description Redirects to frontend with authorization code
+// ProviderCallback handles the OAuth provider callback
+// @Summary Handle OAuth callback
+// @Description Redirects to the client's redirect URI with the authorization code
 // @Tags auth
+// @Param provider path string true "OAuth provider" Enums(google, github, facebook)
 // @Param code query string true "Authorization code"
 // @Param state query string true "CSRF state token"
-// @Success 302 "Redirect to frontend"
+// @Success 302 "Redirect to client"
 // @Failure 400 {object} map[string]string
-// @Router /auth/google/callback [get]
-func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
+// @Router /auth/{provider}/callback [get]
+func (h *AuthHandler) ProviderCallback(c *fiber.Ctx) error {
 	code := c.Query("code")
 	state := c.Query("state")
 
@@ -57,8 +86,9 @@ func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
 		return c.Redirect(config.Get().Server.FrontendURL + "/auth/error?error=missing_parameters")
 	}
 
-	// Redirect to frontend with code and state. The frontend will then hit a /exchange endpoint to retrieve the auth tokens
-	redirectURL := fmt.Sprintf("%s/auth/callback?code=%s&state=%s", config.Get().Server.FrontendURL, code, state)
+	// Redirect to the client with code and state. The client will then hit the /exchange endpoint to retrieve the auth tokens,
+	// or the link endpoint when the flow was started from account settings
+	redirectURL := h.authService.CallbackRedirectURL(c.Params("provider"), code, state)
 	return c.Redirect(redirectURL)
 }
 
@@ -98,9 +128,10 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body dto.ExchangeCodeRequest true "Code exchange request"
+// @Param request body dto.ExchangeCodeRequest true "Code exchange request. code_verifier is required when a code_challenge was sent"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
 // @Router /auth/exchange [post]
 func (h *AuthHandler) ExchangeCode(c *fiber.Ctx) error {
 	var req dto.ExchangeCodeRequest
@@ -112,8 +143,13 @@ func (h *AuthHandler) ExchangeCode(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Code and state are required", "Both 'code' and 'state' fields must be provided")
 	}
 
-	tokens, err := h.authService.ExchangeCode(req.Code, req.State)
+	tokens, err := h.authService.ExchangeCode(req)
 	if err != nil {
+		if errors.Is(err, userService.ErrEmailInUse) {
+			return response.ErrorJSON(c, fiber.StatusConflict, "ACCOUNT_EXISTS",
+				"An account with this email already exists",
+				"Sign in with the provider you used before, then link this one from your account")
+		}
 		logger.Error("Code exchange failed", "error", err)
 		return response.ValidationErrorJSON(c, "Failed to exchange authorization code", err.Error())
 	}
@@ -121,6 +157,79 @@ func (h *AuthHandler) ExchangeCode(c *fiber.Ctx) error {
 	return response.SuccessJSON(c, tokens, "Authorization code exchanged successfully")
 }
 
+// RequestMagicLink emails a passwordless sign-in link
+// @Summary Request a magic sign-in link
+// @Description Email a single-use sign-in link that expires in 15 minutes. The response is the same whether or not the email has an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MagicLinkRequest true "Magic link request"
+// @Success 202 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Failure 503 {object} response.APIResponse
+// @Router /auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(c *fiber.Ctx) error {
+	var req dto.MagicLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	if err := h.authService.RequestMagicLink(req, c.IP()); err != nil {
+		switch {
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			return response.ErrorJSON(c, fiber.StatusServiceUnavailable, "MAGIC_LINK_DISABLED", "Sign-in links are not available", "Sign in with another provider")
+		case errors.Is(err, service.ErrMagicLinkRateLimited):
+			return response.ErrorJSON(c, fiber.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Too many sign-in link requests", "Try again later")
+		case isClientError(err):
+			return response.ValidationErrorJSON(c, "Invalid client parameters", err.Error())
+		case strings.Contains(err.Error(), "validation failed"):
+			return response.ValidationErrorJSON(c, "A valid email is required", err.Error())
+		}
+		logger.Error("Magic link request failed", "error", err)
+		return response.InternalErrorJSON(c, "Failed to send sign-in link")
+	}
+
+	return response.JSON(c, fiber.StatusAccepted, response.Success(nil, "If the address can receive email, a sign-in link is on its way"))
+}
+
+// VerifyMagicLink exchanges the token from a magic link for JWT tokens
+// @Summary Sign in with a magic link
+// @Description Exchange the token from an emailed sign-in link for JWT tokens. Each link works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MagicLinkVerifyRequest true "Magic link token"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 503 {object} response.APIResponse
+// @Router /auth/magic-link/verify [post]
+func (h *AuthHandler) VerifyMagicLink(c *fiber.Ctx) error {
+	var req dto.MagicLinkVerifyRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return response.ValidationErrorJSON(c, "Token is required", "token field cannot be empty")
+	}
+
+	tokens, err := h.authService.VerifyMagicLink(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			return response.ErrorJSON(c, fiber.StatusServiceUnavailable, "MAGIC_LINK_DISABLED", "Sign-in links are not available", "Sign in with another provider")
+		case errors.Is(err, service.ErrInvalidMagicLink):
+			return response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_MAGIC_LINK", "Sign-in link is invalid, expired or already used", "Request a new link")
+		case errors.Is(err, userService.ErrEmailInUse):
+			return response.ErrorJSON(c, fiber.StatusConflict, "ACCOUNT_EXISTS",
+				"An account with this email already exists",
+				"Sign in with the provider you used before, then link this one from your account")
+		}
+		logger.Error("Magic link sign-in failed", "error", err)
+		return response.InternalErrorJSON(c, "Failed to sign in")
+	}
+
+	return response.SuccessJSON(c, tokens, "Signed in successfully")
+}
+
 // Me returns the current authenticated user info from JWT token
 // @Summary Get current user
 // @Description Get current authenticated user information from JWT token
@@ -142,3 +251,157 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 
 	return response.SuccessJSON(c, user, "User information retrieved successfully")
 }
+
+// GetIdentities lists the login providers linked to the current user
+// @Summary List linked providers
+// @Description Get the OAuth providers the current user can sign in with
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/identities [get]
+func (h *AuthHandler) GetIdentities(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	identities, err := h.authService.GetIdentities(userID)
+	if err != nil {
+		logger.Error("Failed to get identities", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve linked providers")
+	}
+
+	return response.SuccessJSON(c, identities, "Linked providers retrieved successfully")
+}
+
+// LinkProvider starts linking an OAuth provider to the current user
+// @Summary Start linking a provider
+// @Description Get the authorization URL for linking an OAuth provider to the current account
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "OAuth provider" Enums(google, github, facebook)
+// @Param client_id query string false "Registered client" Enums(web, ios, android)
+// @Param redirect_uri query string false "Registered redirect URI for the client"
+// @Param code_challenge query string false "PKCE S256 code challenge (required for ios and android)"
+// @Param code_challenge_method query string false "PKCE method" Enums(S256)
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /auth/identities/{provider}/link [get]
+func (h *AuthHandler) LinkProvider(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	provider := c.Params("provider")
+
+	var req dto.AuthorizeRequest
+	if err := c.QueryParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid query parameters", err.Error())
+	}
+
+	authURL, err := h.authService.AuthenticateLink(provider, userID, req)
+	if err != nil {
+		if errors.Is(err, oauth.ErrUnknownProvider) {
+			return response.NotFoundJSON(c, "OAuth provider")
+		}
+		if isClientError(err) {
+			return response.ValidationErrorJSON(c, "Invalid authorization request", err.Error())
+		}
+		logger.Error("Failed to initiate provider link", "provider", provider, "error", err)
+		return response.InternalErrorJSON(c, "Failed to initiate provider link")
+	}
+
+	return response.SuccessJSON(c, dto.AuthURLResponse{
+		AuthURL: authURL,
+	}, "OAuth URL generated successfully")
+}
+
+// CompleteLink finishes linking an OAuth provider to the current user
+// @Summary Complete linking a provider
+// @Description Exchange the authorization code from a link flow and attach the provider to the current account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "OAuth provider" Enums(google, github, facebook)
+// @Param request body dto.ExchangeCodeRequest true "Code exchange request"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /auth/identities/{provider}/link [post]
+func (h *AuthHandler) CompleteLink(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	provider := c.Params("provider")
+
+	var req dto.ExchangeCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	if req.Code == "" || req.State == "" {
+		return response.ValidationErrorJSON(c, "Code and state are required", "Both 'code' and 'state' fields must be provided")
+	}
+
+	identities, err := h.authService.LinkIdentity(userID, provider, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, userService.ErrIdentityTaken):
+			return response.ErrorJSON(c, fiber.StatusConflict, "IDENTITY_TAKEN", "This account is already linked to another Pitstop user", "")
+		case errors.Is(err, userService.ErrProviderLinked):
+			return response.ErrorJSON(c, fiber.StatusConflict, "PROVIDER_LINKED", "A different account from this provider is already linked", "Unlink it first")
+		}
+		logger.Error("Provider link failed", "provider", provider, "error", err)
+		return response.ValidationErrorJSON(c, "Failed to link provider", err.Error())
+	}
+
+	return response.SuccessJSON(c, identities, "Provider linked successfully")
+}
+
+// UnlinkProvider removes an OAuth provider from the current user
+// @Summary Unlink a provider
+// @Description Remove an OAuth provider from the current account. The last provider cannot be removed.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "OAuth provider" Enums(google, github, facebook)
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /auth/identities/{provider} [delete]
+func (h *AuthHandler) UnlinkProvider(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	provider := c.Params("provider")
+
+	if err := h.authService.UnlinkIdentity(userID, provider); err != nil {
+		if errors.Is(err, userService.ErrLastIdentity) {
+			return response.ErrorJSON(c, fiber.StatusConflict, "LAST_IDENTITY", "Cannot unlink your only login provider", "Link another provider first")
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "Linked provider")
+		}
+		logger.Error("Failed to unlink provider", "provider", provider, "error", err)
+		return response.InternalErrorJSON(c, "Failed to unlink provider")
+	}
+
+	return response.SuccessJSON(c, nil, "Provider unlinked successfully")
+}
+
+// JWKS publishes the public keys used to sign Pitstop tokens
+// @Summary Get JSON Web Key Set
+// @Description Public keys for verifying Pitstop access tokens, identified by kid. Empty when tokens are signed with a shared secret.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} keys.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	// Verifiers may cache the set and should refetch when they see an unknown kid
+	c.Set(fiber.HeaderCacheControl, "public, max-age=900")
+	return c.JSON(keys.Get().JWKS())
+}
+
+// isClientError reports whether an authorization error was caused by the client's parameters
+func isClientError(err error) bool {
+	return errors.Is(err, service.ErrInvalidClient) ||
+		errors.Is(err, service.ErrInvalidRedirectURI) ||
+		errors.Is(err, service.ErrPKCERequired) ||
+		errors.Is(err, service.ErrInvalidCodeChallenge)
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// MFAHandler handles HTTP requests for two-factor authentication
+type MFAHandler struct {
+	mfaService *service.MFAService
+}
+
+// NewMFAHandler creates a new MFA handler instance
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// GetStatus returns the current user's two-factor authentication status
+// @Summary Get two-factor status
+// @Description Whether two-factor authentication is enabled or required, and how many recovery codes remain
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa [get]
+func (h *MFAHandler) GetStatus(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.mfaService.GetStatus(userID)
+	if err != nil {
+		logger.Error("Failed to get MFA status", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve two-factor status")
+	}
+
+	return response.SuccessJSON(c, status, "Two-factor status retrieved successfully")
+}
+
+// Enroll starts TOTP enrollment for the current user
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and provisioning URI. Enrollment takes effect after /auth/mfa/verify.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	enrollment, err := h.mfaService.Enroll(userID)
+	if err != nil {
+		return h.handleError(c, userID, err, "Failed to start two-factor enrollment")
+	}
+
+	return response.SuccessJSON(c, enrollment, "Scan the provisioning URI with your authenticator app, then verify a code")
+}
+
+// Verify confirms TOTP enrollment with a first code and returns the recovery codes
+// @Summary Verify two-factor enrollment
+// @Description Confirm enrollment with a code from the authenticator app. Returns 10 single-use recovery codes, shown once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/verify [post]
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return response.ValidationErrorJSON(c, "Code is required", "code field cannot be empty")
+	}
+
+	codes, err := h.mfaService.Confirm(userID, req.Code)
+	if err != nil {
+		return h.handleError(c, userID, err, "Failed to verify two-factor enrollment")
+	}
+
+	return response.SuccessJSON(c, dto.RecoveryCodesResponse{RecoveryCodes: codes}, "Two-factor authentication enabled. Store your recovery codes safely")
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after checking a TOTP code. The old codes stop working.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return response.ValidationErrorJSON(c, "Code is required", "code field cannot be empty")
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return h.handleError(c, userID, err, "Failed to regenerate recovery codes")
+	}
+
+	return response.SuccessJSON(c, dto.RecoveryCodesResponse{RecoveryCodes: codes}, "Recovery codes regenerated successfully")
+}
+
+// Disable turns off two-factor authentication for the current user
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication with a TOTP or recovery code. Not allowed for roles that require it.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/mfa [delete]
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return response.ValidationErrorJSON(c, "Code is required", "code field cannot be empty")
+	}
+
+	if err := h.mfaService.Disable(userID, req.Code); err != nil {
+		return h.handleError(c, userID, err, "Failed to disable two-factor authentication")
+	}
+
+	return response.SuccessJSON(c, nil, "Two-factor authentication disabled")
+}
+
+// Challenge completes a sign-in that requires a second factor
+// @Summary Complete two-factor sign-in
+// @Description Exchange the mfa_token from /auth/exchange and a TOTP or recovery code for JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAChallengeRequest true "Challenge request"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/mfa/challenge [post]
+func (h *MFAHandler) Challenge(c *fiber.Ctx) error {
+	var req dto.MFAChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		return response.ValidationErrorJSON(c, "MFA token and code are required", "Both 'mfa_token' and 'code' fields must be provided")
+	}
+
+	tokens, err := h.mfaService.Challenge(req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMFAToken) {
+			return response.ErrorJSON(c, fiber.StatusUnauthorized, "INVALID_MFA_TOKEN", "Sign-in expired or too many attempts", "Sign in again")
+		}
+		return h.handleError(c, "", err, "Failed to complete two-factor sign-in")
+	}
+
+	return response.SuccessJSON(c, tokens, "Two-factor authentication successful")
+}
+
+// handleError maps MFA service errors to responses
+func (h *MFAHandler) handleError(c *fiber.Ctx, userID string, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		return response.ValidationErrorJSON(c, "Invalid code", err.Error())
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		return response.ValidationErrorJSON(c, "Two-factor authentication is not set up", err.Error())
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		return response.ErrorJSON(c, fiber.StatusConflict, "MFA_ALREADY_ENABLED", "Two-factor authentication is already enabled", "")
+	case errors.Is(err, service.ErrMFARequiredForRole):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "MFA_REQUIRED_FOR_ROLE", "Two-factor authentication is required for your role", "")
+	}
+
+	logger.Error(message, "user_id", userID, "error", err)
+	return response.InternalErrorJSON(c, message)
+}
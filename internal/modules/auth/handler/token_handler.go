@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// TokenHandler handles HTTP requests for personal access tokens
+type TokenHandler struct {
+	tokenService *service.TokenService
+}
+
+// NewTokenHandler creates a new token handler instance
+func NewTokenHandler(tokenService *service.TokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// CreateToken creates a personal access token for the current user
+// @Summary Create a personal access token
+// @Description Create a named, scoped token for scripts and integrations. The token is only returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateTokenRequest true "Token details"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/tokens [post]
+func (h *TokenHandler) CreateToken(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	var req dto.CreateTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	token, err := h.tokenService.CreateToken(userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrTokenLimitReached) {
+			return response.ErrorJSON(c, fiber.StatusConflict, "TOKEN_LIMIT_REACHED", "Too many personal access tokens", "Revoke an unused token first")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return response.ValidationErrorJSON(c, "Invalid token request", err.Error())
+		}
+		logger.Error("Failed to create personal access token", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to create token")
+	}
+
+	return response.CreatedJSON(c, token, "Token created successfully. Copy it now, it will not be shown again")
+}
+
+// GetTokens lists the current user's personal access tokens
+// @Summary List personal access tokens
+// @Description Get the current user's active personal access tokens with their last use
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/tokens [get]
+func (h *TokenHandler) GetTokens(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	tokens, err := h.tokenService.GetTokens(userID)
+	if err != nil {
+		logger.Error("Failed to get personal access tokens", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve tokens")
+	}
+
+	return response.SuccessJSON(c, tokens, "Tokens retrieved successfully")
+}
+
+// RevokeToken revokes one of the current user's personal access tokens
+// @Summary Revoke a personal access token
+// @Description Revoke a personal access token so it can no longer be used
+// @Tags auth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /auth/tokens/{id} [delete]
+func (h *TokenHandler) RevokeToken(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	tokenID := c.Params("id")
+	if err := h.tokenService.RevokeToken(userID, tokenID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "Token")
+		}
+		logger.Error("Failed to revoke personal access token", "user_id", userID, "token_id", tokenID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to revoke token")
+	}
+
+	return response.SuccessJSON(c, nil, "Token revoked successfully")
+}
@@ -0,0 +1,47 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"golang.org/x/oauth2"
+)
+
+// facebookProfile represents the Facebook Graph API /me response
+type facebookProfile struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Picture   struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	} `json:"picture"`
+}
+
+type facebookProvider struct {
+	baseProvider
+}
+
+func newFacebookProvider(cfg config.OAuthProviderConfig) *facebookProvider {
+	return &facebookProvider{baseProvider{name: config.OAuthProviderFacebook, cfg: cfg}}
+}
+
+// FetchProfile loads the profile from the Graph API. Facebook only returns
+// confirmed email addresses, so a present email is treated as verified.
+func (p *facebookProvider) FetchProfile(ctx context.Context, token *oauth2.Token) (*Profile, error) {
+	var profile facebookProfile
+	if err := p.getJSON(ctx, token, p.cfg.UserInfoURL, &profile); err != nil {
+		return nil, err
+	}
+
+	return &Profile{
+		ProviderID:    profile.ID,
+		Email:         profile.Email,
+		EmailVerified: profile.Email != "",
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		AvatarURL:     profile.Picture.Data.URL,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"golang.org/x/oauth2"
+)
+
+// githubProfile represents the GitHub /user response
+type githubProfile struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// githubEmail represents an entry of the GitHub /user/emails response
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+type githubProvider struct {
+	baseProvider
+}
+
+func newGitHubProvider(cfg config.OAuthProviderConfig) *githubProvider {
+	return &githubProvider{baseProvider{name: config.OAuthProviderGitHub, cfg: cfg}}
+}
+
+// FetchProfile loads the profile from GitHub. The public profile email may be hidden,
+// so the primary verified address is always read from the emails endpoint.
+func (p *githubProvider) FetchProfile(ctx context.Context, token *oauth2.Token) (*Profile, error) {
+	var profile githubProfile
+	if err := p.getJSON(ctx, token, p.cfg.UserInfoURL, &profile); err != nil {
+		return nil, err
+	}
+
+	var emails []githubEmail
+	if err := p.getJSON(ctx, token, p.cfg.EmailsURL, &emails); err != nil {
+		return nil, err
+	}
+
+	var primary *githubEmail
+	for i := range emails {
+		if emails[i].Primary {
+			primary = &emails[i]
+			break
+		}
+	}
+	if primary == nil {
+		return nil, errors.New("github account has no primary email")
+	}
+
+	// GitHub only has a single display name, so split it on the first space
+	firstName, lastName := profile.Name, ""
+	if i := strings.Index(profile.Name, " "); i > 0 {
+		firstName, lastName = profile.Name[:i], profile.Name[i+1:]
+	}
+
+	return &Profile{
+		ProviderID:    strconv.FormatInt(profile.ID, 10),
+		Email:         primary.Email,
+		EmailVerified: primary.Verified,
+		FirstName:     firstName,
+		LastName:      lastName,
+		AvatarURL:     profile.AvatarURL,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"golang.org/x/oauth2"
+)
+
+// googleProfile represents the Google userinfo response
+type googleProfile struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	FirstName     string `json:"given_name"`
+	LastName      string `json:"family_name"`
+	Picture       string `json:"picture"`
+	Locale        string `json:"locale"`
+	VerifiedEmail bool   `json:"verified_email"`
+}
+
+type googleProvider struct {
+	baseProvider
+}
+
+func newGoogleProvider(cfg config.OAuthProviderConfig) *googleProvider {
+	return &googleProvider{baseProvider{name: config.OAuthProviderGoogle, cfg: cfg}}
+}
+
+// FetchProfile loads the profile from the Google userinfo endpoint
+func (p *googleProvider) FetchProfile(ctx context.Context, token *oauth2.Token) (*Profile, error) {
+	var profile googleProfile
+	if err := p.getJSON(ctx, token, p.cfg.UserInfoURL, &profile); err != nil {
+		return nil, err
+	}
+
+	return &Profile{
+		ProviderID:    profile.ID,
+		Email:         profile.Email,
+		EmailVerified: profile.VerifiedEmail,
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		AvatarURL:     profile.Picture,
+		Locale:        profile.Locale,
+	}, nil
+}
@@ -0,0 +1,147 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"golang.org/x/oauth2"
+)
+
+// ErrUnknownProvider is returned when a provider is not supported or not configured
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// Profile is the provider-independent user profile returned after login
+type Profile struct {
+	ProviderID    string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	AvatarURL     string
+	Locale        string
+}
+
+// Provider is an OAuth2 identity provider that Pitstop users can sign in with
+type Provider interface {
+	// Name is the provider key used in routes and stored on users, e.g. "google"
+	Name() string
+	// AuthCodeURL returns the consent page URL for the given CSRF state
+	AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string
+	// Exchange trades an authorization code for a provider access token
+	Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
+	// FetchProfile loads the user's profile with a provider access token
+	FetchProfile(ctx context.Context, token *oauth2.Token) (*Profile, error)
+}
+
+// Registry holds every configured provider keyed by name
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry builds providers for every entry in the OAuth configuration
+func NewRegistry(cfg *config.Config) *Registry {
+	registry := &Registry{providers: make(map[string]Provider)}
+
+	for name, providerCfg := range cfg.OAuth {
+		switch name {
+		case config.OAuthProviderGoogle:
+			registry.Register(newGoogleProvider(providerCfg))
+		case config.OAuthProviderGitHub:
+			registry.Register(newGitHubProvider(providerCfg))
+		case config.OAuthProviderFacebook:
+			registry.Register(newFacebookProvider(providerCfg))
+		default:
+			logger.Warn("Ignoring unsupported OAuth provider", "provider", name)
+		}
+	}
+
+	logger.Info("OAuth providers registered", "providers", registry.Names())
+	return registry
+}
+
+// Register adds or replaces a provider
+func (r *Registry) Register(provider Provider) {
+	r.providers[provider.Name()] = provider
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name string) (Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// Names returns the names of all registered providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// baseProvider implements the OAuth2 code flow shared by every provider
+type baseProvider struct {
+	name string
+	cfg  config.OAuthProviderConfig
+}
+
+func (p *baseProvider) Name() string {
+	return p.name
+}
+
+func (p *baseProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return p.cfg.AuthCodeURL(state, opts...)
+}
+
+func (p *baseProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	return p.cfg.Exchange(ctx, code, opts...)
+}
+
+// getJSON performs an authenticated GET request and decodes the JSON response into target
+func (p *baseProvider) getJSON(ctx context.Context, token *oauth2.Token, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Error("Failed to fetch OAuth profile",
+			"event", "auth.profile_fetch_failed",
+			"provider", p.name,
+			"error", err)
+		return fmt.Errorf("failed to fetch profile: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error("OAuth profile fetch returned non-200 status",
+			"event", "auth.profile_fetch_failed",
+			"provider", p.name,
+			"status_code", resp.StatusCode)
+		return fmt.Errorf("profile fetch failed with status: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		logger.Error("Failed to decode OAuth profile response",
+			"event", "auth.profile_decode_failed",
+			"provider", p.name,
+			"error", err)
+		return fmt.Errorf("failed to decode profile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MFARepository handles two-factor enrollment and recovery code data operations
+type MFARepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository creates a new MFA repository instance
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetEnrollment retrieves a user's TOTP enrollment
+func (r *MFARepository) GetEnrollment(userID string) (*domain.MFAEnrollment, error) {
+	var enrollment domain.MFAEnrollment
+	err := r.db.Where("user_id = ?", userID).First(&enrollment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// SaveEnrollment creates or replaces a user's TOTP enrollment
+func (r *MFARepository) SaveEnrollment(enrollment *domain.MFAEnrollment) error {
+	return r.db.Save(enrollment).Error
+}
+
+// DeleteEnrollment removes a user's enrollment and recovery codes
+func (r *MFARepository) DeleteEnrollment(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.MFAEnrollment{}).Error
+	})
+}
+
+// PurgeUser deletes a user's enrollment and recovery codes inside the account deletion transaction
+func (r *MFARepository) PurgeUser(tx *gorm.DB, userID string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&domain.MFAEnrollment{}).Error
+}
+
+// ConfirmEnrollment marks the enrollment confirmed and stores its first recovery codes in one transaction
+func (r *MFARepository) ConfirmEnrollment(enrollment *domain.MFAEnrollment, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(enrollment).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, enrollment.UserID, codeHashes)
+	})
+}
+
+// ReplaceRecoveryCodes discards a user's recovery codes and stores a new set
+func (r *MFARepository) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// AdvanceStep records the time step of an accepted TOTP code. It fails when the step is not newer
+// than the last accepted one, so two requests cannot both use the same code.
+func (r *MFARepository) AdvanceStep(userID string, step int64) error {
+	result := r.db.Model(&domain.MFAEnrollment{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("code already used")
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It fails when no unused code matches.
+func (r *MFARepository) UseRecoveryCode(userID, codeHash string) error {
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("recovery code not found")
+	}
+	return nil
+}
+
+// CountUnusedRecoveryCodes counts a user's remaining recovery codes
+func (r *MFARepository) CountUnusedRecoveryCodes(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// replaceRecoveryCodes swaps a user's recovery codes inside a transaction
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codeHashes []string) error {
+	// Lock the enrollment so concurrent regenerations cannot interleave
+	var enrollment domain.MFAEnrollment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&enrollment).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]domain.RecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = domain.RecoveryCode{
+			ID:       uuid.NewString(),
+			UserID:   userID,
+			CodeHash: hash,
+		}
+	}
+	return tx.Create(&codes).Error
+}
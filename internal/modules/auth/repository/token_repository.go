@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/auth/domain"
+	"gorm.io/gorm"
+)
+
+// TokenRepository handles personal access token data operations
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository creates a new token repository instance
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// Create creates a new personal access token
+func (r *TokenRepository) Create(token *domain.PersonalAccessToken) error {
+	if token.ID == "" {
+		token.ID = uuid.NewString()
+	}
+	return r.db.Create(token).Error
+}
+
+// GetByHash retrieves a token by the hash of its value
+func (r *TokenRepository) GetByHash(hash string) (*domain.PersonalAccessToken, error) {
+	var token domain.PersonalAccessToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetByUser retrieves all tokens a user has not revoked
+func (r *TokenRepository) GetByUser(userID string) ([]domain.PersonalAccessToken, error) {
+	var tokens []domain.PersonalAccessToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// CountActiveByUser counts the tokens a user has not revoked
+func (r *TokenRepository) CountActiveByUser(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Revoke marks a user's token as revoked
+func (r *TokenRepository) Revoke(userID, tokenID string) error {
+	result := r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("token not found")
+	}
+	return nil
+}
+
+// RevokeAllForUser revokes every active token of a user
+func (r *TokenRepository) RevokeAllForUser(userID string) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// ExportUser returns a user's personal access tokens without their hashes, for their personal data export
+func (r *TokenRepository) ExportUser(userID string) (map[string]interface{}, error) {
+	var tokens []domain.PersonalAccessToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"personal_access_tokens.json": tokens,
+	}, nil
+}
+
+// PurgeUser deletes all of a user's tokens inside the account deletion transaction
+func (r *TokenRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	return tx.Where("user_id = ?", userID).Delete(&domain.PersonalAccessToken{}).Error
+}
+
+// TouchLastUsed records when a token was last used
+func (r *TokenRepository) TouchLastUsed(tokenID string, usedAt time.Time) error {
+	return r.db.Model(&domain.PersonalAccessToken{}).
+		Where("id = ?", tokenID).
+		UpdateColumn("last_used_at", usedAt).Error
+}
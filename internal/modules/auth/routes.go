@@ -8,18 +8,49 @@ import (
 )
 
 // RegisterRoutes registers all auth-related routes
-func RegisterRoutes(router fiber.Router, authHandler *handler.AuthHandler) {
+func RegisterRoutes(router fiber.Router, authHandler *handler.AuthHandler, tokenHandler *handler.TokenHandler, mfaHandler *handler.MFAHandler) {
 	auth := router.Group("/auth")
 
-	// OAuth routes
-	auth.Get("/google", authHandler.GoogleAuth)
-	auth.Get("/google/callback", authHandler.GoogleCallback)
-	
+	// Protected routes (require JWT authentication). Registered with route-level middleware
+	// ahead of /:provider so "me", "identities", "tokens" and "mfa" are never treated as provider names
+	jwt := middleware.JWTMiddleware(config.Get())
+	auth.Get("/me", jwt, authHandler.Me)
+
+	// Linked login providers. Only a signed-in session can see or change them, never a personal access token
+	interactive := middleware.InteractiveOnly()
+	auth.Get("/identities", jwt, interactive, authHandler.GetIdentities)
+	auth.Get("/identities/:provider/link", jwt, interactive, authHandler.LinkProvider)
+	auth.Post("/identities/:provider/link", jwt, interactive, authHandler.CompleteLink)
+	auth.Delete("/identities/:provider", jwt, interactive, authHandler.UnlinkProvider)
+
+	// Personal access tokens. Creating and revoking needs "auth:write", which no token can hold,
+	// so only a signed-in session can manage tokens
+	auth.Get("/tokens", jwt, tokenHandler.GetTokens)
+	auth.Post("/tokens", jwt, tokenHandler.CreateToken)
+	auth.Delete("/tokens/:id", jwt, tokenHandler.RevokeToken)
+
+	// Two-factor authentication. The challenge is public: it is authenticated by the mfa_token from /exchange
+	auth.Post("/mfa/challenge", mfaHandler.Challenge)
+	auth.Get("/mfa", jwt, mfaHandler.GetStatus)
+	auth.Post("/mfa/enroll", jwt, mfaHandler.Enroll)
+	auth.Post("/mfa/verify", jwt, mfaHandler.Verify)
+	auth.Post("/mfa/recovery-codes", jwt, mfaHandler.RegenerateRecoveryCodes)
+	auth.Delete("/mfa", jwt, mfaHandler.Disable)
+
+	// Passwordless sign-in with an emailed link
+	auth.Post("/magic-link", authHandler.RequestMagicLink)
+	auth.Post("/magic-link/verify", authHandler.VerifyMagicLink)
+
 	// JWT token routes
 	auth.Post("/exchange", authHandler.ExchangeCode)
 	auth.Post("/refresh", authHandler.RefreshToken)
-	
-	// Protected routes (require JWT authentication)
-	protected := auth.Group("", middleware.JWTMiddleware(config.Get()))
-	protected.Get("/me", authHandler.Me)
+
+	// OAuth routes
+	auth.Get("/:provider", authHandler.ProviderAuth)
+	auth.Get("/:provider/callback", authHandler.ProviderCallback)
+}
+
+// RegisterWellKnownRoutes registers discovery routes served outside the versioned API
+func RegisterWellKnownRoutes(router fiber.Router, authHandler *handler.AuthHandler) {
+	router.Get("/.well-known/jwks.json", authHandler.JWKS)
 }
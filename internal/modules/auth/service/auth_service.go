@@ -5,15 +5,19 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/redis/go-redis/v9"
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/mailer"
 	authdto "github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/oauth"
 	"github.com/topboyasante/pitstop/internal/modules/user/dto"
 	"github.com/topboyasante/pitstop/internal/modules/user/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
@@ -26,10 +30,13 @@ type AuthService struct {
 	validator   *validator.Validate
 	eventBus    *events.EventBus
 	userService *service.UserService
+	providers   *oauth.Registry
+	mfaService  *MFAService
+	mailer      mailer.Mailer
 }
 
 // NewAuthService creates a new instance of AuthService with the provided configuration
-func NewAuthService(config *config.Config, redis *redis.Client, eventBus *events.EventBus, validator *validator.Validate, userService *service.UserService) *AuthService {
+func NewAuthService(config *config.Config, redis *redis.Client, eventBus *events.EventBus, validator *validator.Validate, userService *service.UserService, providers *oauth.Registry, mfaService *MFAService, mailer mailer.Mailer) *AuthService {
 	logger.Info("Initializing auth service")
 	return &AuthService{
 		config:      config,
@@ -37,24 +44,90 @@ func NewAuthService(config *config.Config, redis *redis.Client, eventBus *events
 		validator:   validator,
 		eventBus:    eventBus,
 		userService: userService,
+		providers:   providers,
+		mfaService:  mfaService,
+		mailer:      mailer,
 	}
 }
 
-// Authenticate generates a CSRF state token and returns the OAuth authorization URL
-func (as *AuthService) Authenticate() string {
+var (
+	// ErrInvalidClient is returned for a client_id that is not registered
+	ErrInvalidClient = errors.New("invalid client")
+	// ErrInvalidRedirectURI is returned when the redirect_uri is not registered for the client
+	ErrInvalidRedirectURI = errors.New("redirect_uri is not registered for this client")
+	// ErrPKCERequired is returned when a public client omits the code_challenge
+	ErrPKCERequired = errors.New("code_challenge is required for this client")
+	// ErrInvalidCodeChallenge is returned for a malformed challenge or a method other than S256
+	ErrInvalidCodeChallenge = errors.New("code_challenge must be a S256 challenge")
+	// ErrInvalidCodeVerifier is returned when the code_verifier does not match the stored challenge
+	ErrInvalidCodeVerifier = errors.New("invalid code_verifier")
+)
+
+// Authenticate generates a CSRF state token and returns the authorization URL for the given provider
+func (as *AuthService) Authenticate(providerName string, req authdto.AuthorizeRequest) (string, error) {
+	return as.authCodeURL(oauthState{Provider: providerName}, req)
+}
+
+// AuthenticateLink returns the authorization URL for linking a provider to an authenticated user
+func (as *AuthService) AuthenticateLink(providerName, userID string, req authdto.AuthorizeRequest) (string, error) {
+	return as.authCodeURL(oauthState{Provider: providerName, LinkUserID: userID}, req)
+}
+
+// authCodeURL checks the client parameters, stores the state entry and builds the provider's authorization URL
+func (as *AuthService) authCodeURL(entry oauthState, req authdto.AuthorizeRequest) (string, error) {
+	providerName := entry.Provider
+	provider, err := as.providers.Get(providerName)
+	if err != nil {
+		return "", err
+	}
+
+	if req.ClientID == "" {
+		req.ClientID = config.ClientWeb
+	}
+	client, ok := as.config.Client(req.ClientID)
+	if !ok {
+		return "", ErrInvalidClient
+	}
+
+	if req.RedirectURI == "" {
+		req.RedirectURI = client.RedirectURIs[0]
+	} else if !client.AllowsRedirectURI(req.RedirectURI) {
+		logger.Warn("Unregistered redirect URI",
+			"event", "auth.redirect_uri_rejected",
+			"client_id", client.ID,
+			"redirect_uri", req.RedirectURI)
+		return "", ErrInvalidRedirectURI
+	}
+
+	if req.CodeChallenge == "" {
+		if client.RequirePKCE {
+			return "", ErrPKCERequired
+		}
+	} else if !oauth.ValidCodeChallenge(req.CodeChallenge, req.CodeChallengeMethod) {
+		return "", ErrInvalidCodeChallenge
+	}
+
+	entry.ClientID = client.ID
+	entry.RedirectURI = req.RedirectURI
+	entry.CodeChallenge = req.CodeChallenge
+
 	logger.Info("OAuth authentication initiated",
 		"event", "auth.oauth_started",
-		"provider", "google")
+		"provider", providerName,
+		"client_id", entry.ClientID,
+		"pkce", entry.CodeChallenge != "",
+		"link", entry.LinkUserID != "")
 
 	state := as.generateState()
 
-	// Store state in Redis with 10 minute expiration
+	// Store state in Redis with 10 minute expiration. The value records the provider and client
+	// that started the flow, the PKCE challenge and, for link requests, the user.
 	key := fmt.Sprintf("oauth:state:%s", state)
-	err := as.redis.Set(context.Background(), key, "1", 10*time.Minute).Err()
+	err = as.redis.Set(context.Background(), key, entry.encode(), 10*time.Minute).Err()
 	if err != nil {
 		logger.Error("Failed to store OAuth state in Redis",
 			"event", "auth.state_store_failed",
-			"provider", "google",
+			"provider", providerName,
 			"operation", "redis_set",
 			"ttl_minutes", 10,
 			"error", err)
@@ -62,20 +135,19 @@ func (as *AuthService) Authenticate() string {
 	} else {
 		logger.Info("OAuth state stored successfully",
 			"event", "auth.state_stored",
-			"provider", "google",
+			"provider", providerName,
 			"operation", "redis_set",
 			"ttl_minutes", 10)
 	}
 
-	url := as.config.OAuth.AuthCodeURL(state)
+	authURL := provider.AuthCodeURL(state)
 
 	logger.Info("OAuth URL generated",
 		"event", "auth.oauth_url_generated",
-		"provider", "google",
-		"redirect_host", "accounts.google.com",
+		"provider", providerName,
 		"state_stored", err == nil)
 
-	return url
+	return authURL, nil
 }
 
 // generateState creates a cryptographically secure random state token for CSRF protection
@@ -85,29 +157,60 @@ func (as *AuthService) generateState() string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
-// ValidateState verifies the CSRF state token and removes it to prevent reuse
-func (as *AuthService) ValidateState(state string) bool {
+// CallbackRedirectURL builds the client redirect for a provider callback without consuming the state.
+// The code is handed to the redirect URI registered when the flow started; unknown states go to the web error page.
+func (as *AuthService) CallbackRedirectURL(providerName, code, state string) string {
+	value, err := as.redis.Get(context.Background(), fmt.Sprintf("oauth:state:%s", state)).Result()
+	if err != nil {
+		return as.config.Server.FrontendURL + "/auth/error?error=invalid_state"
+	}
+	entry := decodeOAuthState(value)
+
+	redirectURI := entry.RedirectURI
+	if redirectURI == "" {
+		redirectURI = as.config.Server.FrontendURL + "/auth/callback"
+	}
+
+	query := url.Values{}
+	query.Set("code", code)
+	query.Set("state", state)
+	query.Set("provider", providerName)
+	if entry.LinkUserID != "" {
+		query.Set("link", "true")
+	}
+
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+	return redirectURI + separator + query.Encode()
+}
+
+// ValidateState verifies the CSRF state token and removes it to prevent reuse.
+// It returns the state entry recorded when the flow started.
+func (as *AuthService) ValidateState(state string) (oauthState, bool) {
 	key := fmt.Sprintf("oauth:state:%s", state)
 
 	// Check if state exists in Redis
-	exists, err := as.redis.Get(context.Background(), key).Result()
-	if err != nil || exists == "" {
+	value, err := as.redis.Get(context.Background(), key).Result()
+	if err != nil || value == "" {
 		logger.Warn("State validation failed",
 			"event", "auth.state_validation_failed",
-			"provider", "google",
 			"operation", "redis_get",
 			"reason", "state_not_found_or_expired",
 			"redis_error", err != nil,
 			"error", err)
-		return false
+		return oauthState{}, false
 	}
+	entry := decodeOAuthState(value)
+	providerName := entry.Provider
 
 	// Delete the state immediately (one-time use)
 	err = as.redis.Del(context.Background(), key).Err()
 	if err != nil {
 		logger.Error("Failed to delete OAuth state from Redis",
 			"event", "auth.state_delete_failed",
-			"provider", "google",
+			"provider", providerName,
 			"operation", "redis_del",
 			"error", err)
 		// Continue anyway - state was valid
@@ -115,55 +218,50 @@ func (as *AuthService) ValidateState(state string) bool {
 
 	logger.Info("State validation successful",
 		"event", "auth.state_validated",
-		"provider", "google",
+		"provider", providerName,
 		"operation", "redis_del",
 		"state_deleted", err == nil)
-	return true
+	return entry, true
 }
 
 // ExchangeCode validates the state token and exchanges the authorization code for JWT tokens
-func (as *AuthService) ExchangeCode(code, state string) (*authdto.JWTTokenResponse, error) {
+func (as *AuthService) ExchangeCode(req authdto.ExchangeCodeRequest) (*authdto.JWTTokenResponse, error) {
 	logger.Info("Token exchange initiated",
 		"event", "auth.token_exchange_started")
 
-	if !as.ValidateState(state) {
+	entry, ok := as.ValidateState(req.State)
+	if !ok || entry.LinkUserID != "" {
 		logger.Error("Token exchange failed",
 			"event", "auth.token_exchange_failed",
 			"reason", "invalid_state")
 		return nil, fmt.Errorf("invalid state")
 	}
+	providerName := entry.Provider
 
-	token, err := as.config.OAuth.Exchange(context.TODO(), code)
-	if err != nil {
+	if err := entry.verifyClient(req.ClientID, req.CodeVerifier); err != nil {
 		logger.Error("Token exchange failed",
 			"event", "auth.token_exchange_failed",
-			"reason", "oauth_exchange_error",
-			"error", err.Error())
-		return nil, fmt.Errorf("failed to exchange code: %w", err)
+			"provider", providerName,
+			"client_id", entry.ClientID,
+			"reason", err.Error())
+		return nil, err
 	}
 
-	profile, err := as.GetGoogleProfile(token.AccessToken)
+	profile, err := as.fetchProfile(providerName, req.Code)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create or get existing user synchronously
-	userReq := dto.CreateUserRequest{
-		Provider:   "google",
-		ProviderID: profile.ID,
-		FirstName:  profile.FirstName,
-		LastName:   profile.LastName,
-		Email:      profile.Email,
-		AvatarURL:  profile.Picture,
-		Locale:     profile.Locale,
-	}
-
-	user, err := as.userService.CreateUser(userReq)
+	user, err := as.userService.CreateUser(newCreateUserRequest(providerName, profile))
 	if err != nil {
+		if errors.Is(err, service.ErrEmailInUse) {
+			return nil, err
+		}
 		logger.Error("Failed to create/get user during OAuth",
 			"event", "auth.user_creation_failed",
-			"provider", "google",
-			"google_id", profile.ID,
+			"provider", providerName,
+			"provider_id", profile.ProviderID,
 			"email", profile.Email,
 			"error", err)
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -171,12 +269,58 @@ func (as *AuthService) ExchangeCode(code, state string) (*authdto.JWTTokenRespon
 
 	logger.Info("User created/retrieved for OAuth",
 		"event", "auth.user_ready",
-		"provider", "google",
+		"provider", providerName,
 		"internal_user_id", user.ID,
-		"google_id", profile.ID)
+		"provider_id", profile.ProviderID)
+
+	// Publish event after successful user creation and JWT generation. The plan's to make sure that the email service would send an email if the user is a first time user or sth
+	event := events.NewAuthenticationSuccessful(
+		providerName,
+		profile.ProviderID,
+		profile.Email,
+		profile.FirstName,
+		profile.LastName,
+		profile.AvatarURL,
+		profile.Locale,
+	)
+
+	return as.completeSignIn(user, entry.ClientID, providerName, event)
+}
+
+// completeSignIn issues the session for a signed-in user, or an mfa_pending token when the user has
+// two-factor authentication enabled. The event is only published once tokens are issued.
+func (as *AuthService) completeSignIn(user *dto.UserResponse, clientID, providerName string, event *events.AuthenticationSuccessful) (*authdto.JWTTokenResponse, error) {
+	// Users with two-factor authentication get an mfa_pending token instead of a session
+	mfaEnabled, err := as.mfaService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfaEnabled {
+		mfaToken, err := as.mfaService.IssuePendingToken(user.ID, clientID)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("Sign-in awaiting second factor",
+			"event", "auth.mfa_challenge_issued",
+			"internal_user_id", user.ID,
+			"provider", providerName)
+
+		return &authdto.JWTTokenResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	// Signing in cancels a pending account deletion
+	deletionCancelled, err := as.userService.CancelDeletion(user.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Generate JWT tokens using internal user ID
-	accessToken, refreshToken, expiresAt, err := utils.CreateJWTTokens(as.config, user.ID, "web")
+	// The client ID is the token audience, which also selects the token lifetimes
+	accessToken, refreshToken, expiresAt, err := utils.CreateJWTTokens(as.config, user.ID, clientID)
 	if err != nil {
 		logger.Error("Failed to create JWT tokens",
 			"event", "auth.jwt_creation_failed",
@@ -185,22 +329,12 @@ func (as *AuthService) ExchangeCode(code, state string) (*authdto.JWTTokenRespon
 		return nil, fmt.Errorf("failed to create JWT tokens: %w", err)
 	}
 
-	// Publish event after successful user creation and JWT generation. The plan's to make sure that the email service would send an email if the user is a first time user or sth
-	event := events.NewAuthenticationSuccessful(
-		"google",
-		profile.ID,
-		profile.Email,
-		profile.FirstName,
-		profile.LastName,
-		profile.Picture,
-		profile.Locale,
-	)
 	as.eventBus.Publish("AuthenticationSuccessful", event)
 
-	logger.Info("Token exchange successful",
+	logger.Info("Sign-in successful",
 		"event", "auth.token_exchange_completed",
 		"internal_user_id", user.ID,
-		"google_id", profile.ID,
+		"provider", providerName,
 		"expiresAt", expiresAt)
 
 	return &authdto.JWTTokenResponse{
@@ -208,48 +342,114 @@ func (as *AuthService) ExchangeCode(code, state string) (*authdto.JWTTokenRespon
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresAt:    expiresAt,
+		// The session works, but the role's privileges need two-factor authentication to be set up first
+		MFAEnrollmentRequired: as.config.RequiresMFA(user.Role),
+		DeletionCancelled:     deletionCancelled,
 	}, nil
 }
 
-// GetGoogleProfile fetches user profile from Google using the access token
-func (as *AuthService) GetGoogleProfile(accessToken string) (*dto.GoogleOAuthProfile, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
+// LinkIdentity completes a link flow started by AuthenticateLink and attaches the provider to the user
+func (as *AuthService) LinkIdentity(userID, providerName string, req authdto.ExchangeCodeRequest) ([]dto.IdentityResponse, error) {
+	entry, ok := as.ValidateState(req.State)
+	if !ok || entry.LinkUserID != userID || entry.Provider != providerName {
+		logger.Error("Identity link failed",
+			"event", "auth.identity_link_failed",
+			"provider", providerName,
+			"reason", "invalid_state")
+		return nil, fmt.Errorf("invalid state")
+	}
+
+	if err := entry.verifyClient(req.ClientID, req.CodeVerifier); err != nil {
+		return nil, err
+	}
+
+	profile, err := as.fetchProfile(providerName, req.Code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return as.userService.LinkIdentity(userID, newCreateUserRequest(providerName, profile))
+}
 
-	resp, err := client.Do(req)
+// UnlinkIdentity removes a provider from the user's account
+func (as *AuthService) UnlinkIdentity(userID, providerName string) error {
+	return as.userService.UnlinkIdentity(userID, providerName)
+}
+
+// GetIdentities lists the providers linked to the user's account
+func (as *AuthService) GetIdentities(userID string) ([]dto.IdentityResponse, error) {
+	return as.userService.GetIdentities(userID)
+}
+
+// fetchProfile exchanges the authorization code with the provider and loads the user's profile
+func (as *AuthService) fetchProfile(providerName, code string) (*oauth.Profile, error) {
+	provider, err := as.providers.Get(providerName)
 	if err != nil {
-		logger.Error("Failed to fetch Google profile",
-			"event", "auth.profile_fetch_failed",
-			"error", err)
-		return nil, fmt.Errorf("failed to fetch profile: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("Google profile fetch returned non-200 status",
-			"event", "auth.profile_fetch_failed",
-			"status_code", resp.StatusCode)
-		return nil, fmt.Errorf("profile fetch failed with status: %d", resp.StatusCode)
+	ctx := context.TODO()
+	token, err := provider.Exchange(ctx, code)
+	if err != nil {
+		logger.Error("Token exchange failed",
+			"event", "auth.token_exchange_failed",
+			"provider", providerName,
+			"reason", "oauth_exchange_error",
+			"error", err.Error())
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
-	var profile dto.GoogleOAuthProfile
-	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
-		logger.Error("Failed to decode Google profile response",
-			"event", "auth.profile_decode_failed",
-			"error", err)
-		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	return provider.FetchProfile(ctx, token)
+}
+
+// newCreateUserRequest maps a provider profile to the user service's request
+func newCreateUserRequest(providerName string, profile *oauth.Profile) dto.CreateUserRequest {
+	return dto.CreateUserRequest{
+		Provider:      providerName,
+		ProviderID:    profile.ProviderID,
+		FirstName:     profile.FirstName,
+		LastName:      profile.LastName,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		AvatarURL:     profile.AvatarURL,
+		Locale:        profile.Locale,
 	}
+}
 
-	logger.Info("Google profile fetched successfully",
-		"event", "auth.profile_fetched",
-		"email", profile.Email)
+// oauthState is the value stored against a state token while a provider flow is in progress
+type oauthState struct {
+	Provider      string `json:"provider"`
+	LinkUserID    string `json:"link_user_id,omitempty"`
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	CodeChallenge string `json:"code_challenge,omitempty"`
+}
+
+func (s oauthState) encode() string {
+	value, _ := json.Marshal(s)
+	return string(value)
+}
 
-	return &profile, nil
+// decodeOAuthState parses a stored state entry. States written before clients were
+// registered hold only the provider name and are treated as web logins.
+func decodeOAuthState(value string) oauthState {
+	var entry oauthState
+	if err := json.Unmarshal([]byte(value), &entry); err != nil {
+		return oauthState{Provider: value, ClientID: config.ClientWeb}
+	}
+	return entry
+}
+
+// verifyClient checks that the code is redeemed by the client that requested it and,
+// when a PKCE challenge was sent, that the verifier matches it
+func (s oauthState) verifyClient(clientID, codeVerifier string) error {
+	if clientID != "" && clientID != s.ClientID {
+		return ErrInvalidClient
+	}
+	if s.CodeChallenge != "" && !oauth.VerifyCodeVerifier(codeVerifier, s.CodeChallenge) {
+		return ErrInvalidCodeVerifier
+	}
+	return nil
 }
 
 // RefreshTokens validates the refresh token and creates new JWT tokens
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/keys"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/mailer"
+	authdto "github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// EmailProvider is the identity provider for users who sign in with a magic link
+const EmailProvider = "email"
+
+// MagicLinkAudience marks the token carried by an emailed sign-in link. It is not a client audience,
+// so the JWT middleware never accepts it as an access token.
+const MagicLinkAudience = "magic_link"
+
+const (
+	magicLinkTTL = 15 * time.Minute
+
+	// Link requests allowed per hour for one address and for one IP
+	magicLinkEmailLimit = 5
+	magicLinkIPLimit    = 20
+	magicLinkWindow     = time.Hour
+)
+
+var (
+	// ErrMagicLinkRateLimited is returned when an email or IP has requested too many links
+	ErrMagicLinkRateLimited = errors.New("too many sign-in link requests")
+	// ErrInvalidMagicLink is returned for a link that is malformed, expired or already used
+	ErrInvalidMagicLink = errors.New("invalid or expired sign-in link")
+	// ErrMagicLinkDisabled is returned when magic-link sign-in is turned off
+	ErrMagicLinkDisabled = errors.New("sign-in links are not available")
+)
+
+// RequestMagicLink emails a signed, single-use sign-in link. The response does not depend on
+// whether the address has an account, so it cannot be used to discover registered emails.
+func (as *AuthService) RequestMagicLink(req authdto.MagicLinkRequest, ip string) error {
+	if !as.config.Mail.MagicLinkEnabled {
+		return ErrMagicLinkDisabled
+	}
+	if err := as.validator.Struct(req); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	email := normalizeEmail(req.Email)
+
+	if req.ClientID == "" {
+		req.ClientID = config.ClientWeb
+	}
+	client, ok := as.config.Client(req.ClientID)
+	if !ok {
+		return ErrInvalidClient
+	}
+	if req.RedirectURI == "" {
+		req.RedirectURI = client.RedirectURIs[0]
+	} else if !client.AllowsRedirectURI(req.RedirectURI) {
+		return ErrInvalidRedirectURI
+	}
+
+	ctx := context.Background()
+	if err := as.checkMagicLinkLimit(ctx, "ip:"+ip, magicLinkIPLimit); err != nil {
+		return err
+	}
+	emailHash := sha256.Sum256([]byte(email))
+	if err := as.checkMagicLinkLimit(ctx, "email:"+hex.EncodeToString(emailHash[:]), magicLinkEmailLimit); err != nil {
+		return err
+	}
+
+	jti := uuid.NewString()
+	now := time.Now()
+	token, err := keys.Get().Sign(jwt.MapClaims{
+		"sub":       email,
+		"iss":       as.config.Server.JWTIssuer,
+		"aud":       MagicLinkAudience,
+		"exp":       now.Add(magicLinkTTL).Unix(),
+		"iat":       now.Unix(),
+		"jti":       jti,
+		"client_id": client.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign magic link: %w", err)
+	}
+
+	// The link is single use: verifying deletes this key
+	if err := as.redis.Set(ctx, magicLinkKey(jti), 1, magicLinkTTL).Err(); err != nil {
+		return fmt.Errorf("failed to store magic link: %w", err)
+	}
+
+	separator := "?"
+	if strings.Contains(req.RedirectURI, "?") {
+		separator = "&"
+	}
+	link := req.RedirectURI + separator + url.Values{"token": {token}}.Encode()
+
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Your Pitstop sign-in link",
+		Text: fmt.Sprintf("Use this link to sign in to Pitstop:\n\n%s\n\nThe link expires in %d minutes and can be used once. If you did not request it, you can ignore this email.",
+			link, int(magicLinkTTL.Minutes())),
+		HTML: fmt.Sprintf(`<p>Use this link to sign in to Pitstop:</p><p><a href="%s">Sign in</a></p><p>The link expires in %d minutes and can be used once. If you did not request it, you can ignore this email.</p>`,
+			html.EscapeString(link), int(magicLinkTTL.Minutes())),
+	}
+	if err := as.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send magic link: %w", err)
+	}
+
+	logger.Info("Magic link sent",
+		"event", "auth.magic_link_sent",
+		"client_id", client.ID)
+	return nil
+}
+
+// VerifyMagicLink consumes a sign-in link and signs the user in with the email provider,
+// creating or merging the account the same way an OAuth sign-in does
+func (as *AuthService) VerifyMagicLink(req authdto.MagicLinkVerifyRequest) (*authdto.JWTTokenResponse, error) {
+	if !as.config.Mail.MagicLinkEnabled {
+		return nil, ErrMagicLinkDisabled
+	}
+	token, err := utils.ValidateJWTToken(as.config, req.Token)
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidMagicLink
+	}
+	email, _ := claims["sub"].(string)
+	audience, _ := claims["aud"].(string)
+	jti, _ := claims["jti"].(string)
+	clientID, _ := claims["client_id"].(string)
+	if email == "" || jti == "" || audience != MagicLinkAudience {
+		return nil, ErrInvalidMagicLink
+	}
+
+	if err := as.redis.GetDel(context.Background(), magicLinkKey(jti)).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			logger.Warn("Magic link reused or expired",
+				"event", "auth.magic_link_rejected")
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+
+	// Opening the link proves control of the address, so the email counts as verified
+	user, err := as.userService.CreateUser(dto.CreateUserRequest{
+		Provider:      EmailProvider,
+		ProviderID:    email,
+		Email:         email,
+		EmailVerified: true,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrEmailInUse) {
+			return nil, err
+		}
+		logger.Error("Failed to create/get user during magic link sign-in",
+			"event", "auth.user_creation_failed",
+			"provider", EmailProvider,
+			"error", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	event := events.NewAuthenticationSuccessful(EmailProvider, email, email, "", "", "", "")
+	return as.completeSignIn(user, clientID, EmailProvider, event)
+}
+
+// checkMagicLinkLimit counts a link request against a fixed hourly window. The window's expiry is
+// set in the same transaction that counts the first request, so a counter never outlives it.
+func (as *AuthService) checkMagicLinkLimit(ctx context.Context, subject string, limit int64) error {
+	key := "rate_limit:magic:" + subject
+	pipe := as.redis.TxPipeline()
+	pipe.SetNX(ctx, key, 0, magicLinkWindow)
+	incr := pipe.Incr(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to check magic link rate limit: %w", err)
+	}
+	if count := incr.Val(); count > limit {
+		logger.Warn("Magic link rate limit exceeded",
+			"event", "auth.magic_link_rate_limited",
+			"subject", strings.SplitN(subject, ":", 2)[0])
+		return ErrMagicLinkRateLimited
+	}
+	return nil
+}
+
+// magicLinkKey is the Redis key that marks a link as unused
+func magicLinkKey(jti string) string {
+	return fmt.Sprintf("magic:link:%s", jti)
+}
+
+// normalizeEmail lowercases and trims an address so each inbox maps to one identity
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
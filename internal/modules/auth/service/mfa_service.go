@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/keys"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
+	"github.com/topboyasante/pitstop/internal/modules/auth/domain"
+	authdto "github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/mfa"
+	"github.com/topboyasante/pitstop/internal/modules/auth/repository"
+	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	"github.com/topboyasante/pitstop/internal/shared/utils"
+	"gorm.io/gorm"
+)
+
+// maxChallengeAttempts is how many wrong codes an mfa_pending token tolerates before it is discarded
+const maxChallengeAttempts = 5
+
+var (
+	// ErrMFAAlreadyEnabled is returned when enrolling a user whose enrollment is already confirmed
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	// ErrMFANotEnrolled is returned when confirming or using two-factor authentication before enrolling
+	ErrMFANotEnrolled = errors.New("two-factor authentication not enrolled")
+	// ErrInvalidMFACode is returned for a wrong, reused or malformed code
+	ErrInvalidMFACode = errors.New("invalid two-factor code")
+	// ErrMFARequiredForRole is returned when a user whose role requires two-factor authentication tries to disable it
+	ErrMFARequiredForRole = errors.New("two-factor authentication is required for your role")
+	// ErrInvalidMFAToken is returned for an expired, used or exhausted mfa_pending token
+	ErrInvalidMFAToken = errors.New("invalid or expired mfa token")
+)
+
+var totpCode = regexp.MustCompile(`^\d{6}$`)
+
+// MFAService manages TOTP two-factor authentication and recovery codes
+type MFAService struct {
+	config      *config.Config
+	mfaRepo     *repository.MFARepository
+	redis       *redis.Client
+	userService *service.UserService
+	cipher      *mfa.Cipher
+	hashKey     []byte
+}
+
+// NewMFAService creates a new MFA service instance. Secrets are encrypted with MFA_ENCRYPTION_KEY,
+// or with a key derived from JWT_SECRET when it is not set.
+func NewMFAService(config *config.Config, mfaRepo *repository.MFARepository, redis *redis.Client, userService *service.UserService) (*MFAService, error) {
+	secret := config.MFA.EncryptionKey
+	if secret == "" {
+		secret = config.Server.JWTSecret
+	}
+	cipherKey := sha256.Sum256([]byte("pitstop-mfa-secret:" + secret))
+	hashKey := sha256.Sum256([]byte("pitstop-mfa-recovery:" + secret))
+
+	cipher, err := mfa.NewCipher(cipherKey[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MFA cipher: %w", err)
+	}
+
+	return &MFAService{
+		config:      config,
+		mfaRepo:     mfaRepo,
+		redis:       redis,
+		userService: userService,
+		cipher:      cipher,
+		hashKey:     hashKey[:],
+	}, nil
+}
+
+// GetStatus returns whether two-factor authentication is enabled or required for the user
+func (s *MFAService) GetStatus(userID string) (*authdto.MFAStatusResponse, error) {
+	required, err := s.isRequired(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &authdto.MFAStatusResponse{Required: required}
+
+	enrollment, err := s.mfaRepo.GetEnrollment(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve enrollment: %w", err)
+	}
+
+	status.Enabled = enrollment.IsConfirmed()
+	if status.Enabled {
+		remaining, err := s.mfaRepo.CountUnusedRecoveryCodes(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return status, nil
+}
+
+// IsEnabled reports whether the user has a confirmed TOTP enrollment
+func (s *MFAService) IsEnabled(userID string) (bool, error) {
+	enrollment, err := s.mfaRepo.GetEnrollment(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to retrieve enrollment: %w", err)
+	}
+	return enrollment.IsConfirmed(), nil
+}
+
+// Enroll starts TOTP enrollment with a new secret. An unconfirmed enrollment is replaced.
+func (s *MFAService) Enroll(userID string) (*authdto.MFAEnrollmentResponse, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.mfaRepo.GetEnrollment(userID)
+	if err == nil && existing.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to retrieve enrollment: %w", err)
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	encrypted, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	enrollment := &domain.MFAEnrollment{
+		UserID:          userID,
+		EncryptedSecret: encrypted,
+	}
+	if err := s.mfaRepo.SaveEnrollment(enrollment); err != nil {
+		return nil, fmt.Errorf("failed to save enrollment: %w", err)
+	}
+
+	logger.Info("MFA enrollment started",
+		"event", "auth.mfa_enroll_started",
+		"user_id", userID)
+
+	return &authdto.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: mfa.ProvisioningURI(s.config.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm verifies the first TOTP code of an enrollment, enables it and returns the recovery codes
+func (s *MFAService) Confirm(userID, code string) ([]string, error) {
+	enrollment, err := s.mfaRepo.GetEnrollment(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to retrieve enrollment: %w", err)
+	}
+	if enrollment.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, err := s.validateTOTP(enrollment, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	enrollment.ConfirmedAt = &now
+	enrollment.LastUsedStep = step
+	if err := s.mfaRepo.ConfirmEnrollment(enrollment, hashes); err != nil {
+		return nil, fmt.Errorf("failed to confirm enrollment: %w", err)
+	}
+
+	logger.Info("MFA enabled",
+		"event", "auth.mfa_enabled",
+		"user_id", userID)
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a TOTP code
+func (s *MFAService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := s.verifyTOTP(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+
+	logger.Info("MFA recovery codes regenerated",
+		"event", "auth.mfa_recovery_regenerated",
+		"user_id", userID)
+
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking a TOTP or recovery code.
+// Users whose role requires two-factor authentication cannot disable it.
+func (s *MFAService) Disable(userID, code string) error {
+	required, err := s.isRequired(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredForRole
+	}
+
+	if err := s.verifyCode(userID, code); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteEnrollment(userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	logger.Info("MFA disabled",
+		"event", "auth.mfa_disabled",
+		"user_id", userID)
+
+	return nil
+}
+
+// IssuePendingToken creates the short-lived mfa_pending token returned by a sign-in that still needs
+// a second factor. It remembers the client the full tokens will be issued to.
+func (s *MFAService) IssuePendingToken(userID, clientID string) (string, error) {
+	jti := uuid.NewString()
+	now := time.Now()
+	ttl := s.config.MFA.PendingTokenTTL
+
+	token, err := keys.Get().Sign(jwt.MapClaims{
+		"sub":       userID,
+		"iss":       s.config.Server.JWTIssuer,
+		"aud":       middleware.MFAPendingAudience,
+		"exp":       now.Add(ttl).Unix(),
+		"iat":       now.Unix(),
+		"jti":       jti,
+		"client_id": clientID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign mfa token: %w", err)
+	}
+
+	// The token is single use: the challenge deletes this key, and counts attempts on it starting from 1
+	if err := s.redis.Set(context.Background(), pendingTokenKey(jti), 1, ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to store mfa token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Challenge completes a sign-in with a TOTP or recovery code and issues full tokens marked with the "mfa" claim
+func (s *MFAService) Challenge(req authdto.MFAChallengeRequest) (*authdto.JWTTokenResponse, error) {
+	token, err := utils.ValidateJWTToken(s.config, req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidMFAToken
+	}
+	userID, _ := claims["sub"].(string)
+	audience, _ := claims["aud"].(string)
+	jti, _ := claims["jti"].(string)
+	clientID, _ := claims["client_id"].(string)
+	if userID == "" || jti == "" || audience != middleware.MFAPendingAudience {
+		return nil, ErrInvalidMFAToken
+	}
+
+	ctx := context.Background()
+	key := pendingTokenKey(jti)
+	attempts, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mfa token: %w", err)
+	}
+	// INCR on a missing key creates it at 1, which means the token was already used or expired
+	if attempts == 1 {
+		s.redis.Del(ctx, key)
+		return nil, ErrInvalidMFAToken
+	}
+	if attempts > maxChallengeAttempts+1 {
+		s.redis.Del(ctx, key)
+		logger.Warn("MFA challenge attempts exhausted",
+			"event", "auth.mfa_challenge_exhausted",
+			"user_id", userID)
+		return nil, ErrInvalidMFAToken
+	}
+
+	if err := s.verifyCode(userID, req.Code); err != nil {
+		logger.Warn("MFA challenge failed",
+			"event", "auth.mfa_challenge_failed",
+			"user_id", userID,
+			"attempt", attempts-1)
+		return nil, err
+	}
+	s.redis.Del(ctx, key)
+
+	// Completing the sign-in cancels a pending account deletion
+	deletionCancelled, err := s.userService.CancelDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, expiresAt, err := utils.CreateJWTTokensWithClaims(s.config, userID, clientID, jwt.MapClaims{"mfa": true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT tokens: %w", err)
+	}
+
+	logger.Info("MFA challenge passed",
+		"event", "auth.mfa_challenge_passed",
+		"user_id", userID,
+		"client_id", clientID)
+
+	return &authdto.JWTTokenResponse{
+		AccessToken:       accessToken,
+		RefreshToken:      refreshToken,
+		TokenType:         "Bearer",
+		ExpiresAt:         expiresAt,
+		DeletionCancelled: deletionCancelled,
+	}, nil
+}
+
+// isRequired reports whether the user's role requires two-factor authentication
+func (s *MFAService) isRequired(userID string) (bool, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return s.config.RequiresMFA(user.Role), nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code
+func (s *MFAService) verifyCode(userID, code string) error {
+	if totpCode.MatchString(code) {
+		return s.verifyTOTP(userID, code)
+	}
+
+	if err := s.mfaRepo.UseRecoveryCode(userID, mfa.HashRecoveryCode(s.hashKey, code)); err != nil {
+		return ErrInvalidMFACode
+	}
+
+	logger.Info("MFA recovery code used",
+		"event", "auth.mfa_recovery_used",
+		"user_id", userID)
+	return nil
+}
+
+// verifyTOTP checks a TOTP code against the user's confirmed enrollment and consumes its time step
+func (s *MFAService) verifyTOTP(userID, code string) error {
+	enrollment, err := s.mfaRepo.GetEnrollment(userID)
+	if err != nil || !enrollment.IsConfirmed() {
+		return ErrMFANotEnrolled
+	}
+
+	step, err := s.validateTOTP(enrollment, code)
+	if err != nil {
+		return err
+	}
+	if err := s.mfaRepo.AdvanceStep(userID, step); err != nil {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// validateTOTP checks a code against the enrollment's secret, rejecting steps that were already used
+func (s *MFAService) validateTOTP(enrollment *domain.MFAEnrollment, code string) (int64, error) {
+	secret, err := s.cipher.Decrypt(enrollment.EncryptedSecret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	step, ok := mfa.Validate(secret, code, time.Now())
+	if !ok || step <= enrollment.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// newRecoveryCodes generates a set of recovery codes and their hashes
+func (s *MFAService) newRecoveryCodes() ([]string, []string, error) {
+	codes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = mfa.HashRecoveryCode(s.hashKey, code)
+	}
+	return codes, hashes, nil
+}
+
+func pendingTokenKey(jti string) string {
+	return fmt.Sprintf("mfa:pending:%s", jti)
+}
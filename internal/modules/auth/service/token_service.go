@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/redis/go-redis/v9"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
+	"github.com/topboyasante/pitstop/internal/modules/auth/domain"
+	authdto "github.com/topboyasante/pitstop/internal/modules/auth/dto"
+	"github.com/topboyasante/pitstop/internal/modules/auth/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTokenRateLimit is the requests per minute allowed when a token does not set its own limit
+	defaultTokenRateLimit = 60
+	// maxTokensPerUser caps how many active tokens a user can hold
+	maxTokensPerUser = 50
+	// lastUsedResolution limits how often last_used_at is written for a busy token
+	lastUsedResolution = time.Minute
+)
+
+// ErrTokenLimitReached is returned when a user already holds the maximum number of tokens
+var ErrTokenLimitReached = errors.New("personal access token limit reached")
+
+// TokenService manages personal access tokens
+type TokenService struct {
+	config    *config.Config
+	tokenRepo *repository.TokenRepository
+	redis     *redis.Client
+	validator *validator.Validate
+}
+
+// NewTokenService creates a new token service instance
+func NewTokenService(config *config.Config, tokenRepo *repository.TokenRepository, redis *redis.Client, validator *validator.Validate) *TokenService {
+	return &TokenService{
+		config:    config,
+		tokenRepo: tokenRepo,
+		redis:     redis,
+		validator: validator,
+	}
+}
+
+// CreateToken creates a personal access token. The plain token is only available in the returned response.
+func (s *TokenService) CreateToken(userID string, req authdto.CreateTokenRequest) (*authdto.CreatedTokenResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	count, err := s.tokenRepo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if count >= maxTokensPerUser {
+		return nil, ErrTokenLimitReached
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	plain := middleware.PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	rateLimit := req.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultTokenRateLimit
+	}
+
+	token := &domain.PersonalAccessToken{
+		UserID:      userID,
+		Name:        req.Name,
+		TokenHash:   hashToken(plain),
+		TokenPrefix: plain[:len(middleware.PersonalTokenPrefix)+6],
+		Scopes:      strings.Join(uniqueScopes(req.Scopes), " "),
+		RateLimit:   rateLimit,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.tokenRepo.Create(token); err != nil {
+		logger.Error("Failed to create personal access token",
+			"event", "auth.token_create_failed",
+			"user_id", userID,
+			"error", err)
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	logger.Info("Personal access token created",
+		"event", "auth.token_created",
+		"user_id", userID,
+		"token_id", token.ID,
+		"scopes", token.Scopes)
+
+	return &authdto.CreatedTokenResponse{
+		TokenResponse: *s.mapTokenToResponse(token),
+		Token:         plain,
+	}, nil
+}
+
+// GetTokens lists a user's personal access tokens that have not been revoked
+func (s *TokenService) GetTokens(userID string) ([]authdto.TokenResponse, error) {
+	tokens, err := s.tokenRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tokens: %w", err)
+	}
+
+	responses := make([]authdto.TokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = *s.mapTokenToResponse(&token)
+	}
+	return responses, nil
+}
+
+// RevokeToken revokes one of the user's personal access tokens
+func (s *TokenService) RevokeToken(userID, tokenID string) error {
+	if err := s.tokenRepo.Revoke(userID, tokenID); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	logger.Info("Personal access token revoked",
+		"event", "auth.token_revoked",
+		"user_id", userID,
+		"token_id", tokenID)
+	return nil
+}
+
+// AuthenticatePersonalToken implements middleware.PersonalTokenAuthenticator. It checks the token
+// against its stored hash, enforces the token's per-minute rate limit and records last use.
+func (s *TokenService) AuthenticatePersonalToken(ctx context.Context, plain string) (*middleware.TokenPrincipal, error) {
+	token, err := s.tokenRepo.GetByHash(hashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unknown token")
+		}
+		return nil, fmt.Errorf("failed to look up token: %w", err)
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, errors.New("token revoked or expired")
+	}
+
+	if err := s.checkRateLimit(ctx, token, now); err != nil {
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := s.tokenRepo.TouchLastUsed(token.ID, now); err != nil {
+			logger.Warn("Failed to record token use",
+				"event", "auth.token_touch_failed",
+				"token_id", token.ID,
+				"error", err)
+		}
+	}
+
+	return &middleware.TokenPrincipal{
+		UserID:  token.UserID,
+		TokenID: token.ID,
+		Scopes:  token.ScopeList(),
+	}, nil
+}
+
+// RevokeUserSessions signs a user out everywhere: personal access tokens are revoked and JWTs
+// issued up to now stop validating. Tokens issued afterwards, by signing in again, are unaffected.
+func (s *TokenService) RevokeUserSessions(ctx context.Context, userID string) error {
+	if err := s.tokenRepo.RevokeAllForUser(userID); err != nil {
+		return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	// The marker only needs to outlive the longest refresh token issued before it
+	var ttl time.Duration
+	for _, client := range s.config.Clients {
+		ttl = max(ttl, client.RefreshTokenTTL)
+	}
+	if err := s.redis.Set(ctx, sessionsRevokedKey(userID), time.Now().UnixMilli(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	logger.Info("User sessions revoked",
+		"event", "auth.sessions_revoked",
+		"user_id", userID)
+	return nil
+}
+
+// SessionRevoked reports whether a JWT issued to the user at issuedAt was revoked by RevokeUserSessions
+func (s *TokenService) SessionRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	revokedAt, err := s.redis.Get(ctx, sessionsRevokedKey(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// Markers written before millisecond precision hold seconds
+	if revokedAt < secondMarkerLimit {
+		revokedAt *= 1000
+	}
+	return issuedAt.UnixMilli() <= revokedAt, nil
+}
+
+// secondMarkerLimit tells revocation markers in seconds from those in milliseconds: a time in
+// milliseconds passed it in 2001, a time in seconds will not reach it for thousands of years
+const secondMarkerLimit = 1_000_000_000_000
+
+// sessionsRevokedKey is the Redis key holding when a user's sessions were last revoked, in Unix milliseconds
+func sessionsRevokedKey(userID string) string {
+	return fmt.Sprintf("auth:sessions_revoked:%s", userID)
+}
+
+// checkRateLimit counts the request against the token's fixed one-minute window
+func (s *TokenService) checkRateLimit(ctx context.Context, token *domain.PersonalAccessToken, now time.Time) error {
+	key := fmt.Sprintf("rate_limit:pat:%s:%s", token.ID, now.Format("2006-01-02-15-04"))
+
+	count, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("rate limiter error: %w", err)
+	}
+	if count == 1 {
+		if err := s.redis.Expire(ctx, key, time.Minute).Err(); err != nil {
+			logger.Error("Failed to set expiration on token rate limit key",
+				"event", "redis.error",
+				"key", key,
+				"error", err)
+		}
+	}
+
+	if count > int64(token.RateLimit) {
+		return middleware.ErrTokenRateLimited
+	}
+	return nil
+}
+
+// mapTokenToResponse converts a domain token to a TokenResponse DTO
+func (s *TokenService) mapTokenToResponse(token *domain.PersonalAccessToken) *authdto.TokenResponse {
+	return &authdto.TokenResponse{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.ScopeList(),
+		RateLimit:   token.RateLimit,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+// hashToken returns the hex encoded SHA-256 of a token. Tokens carry 256 bits of randomness,
+// so a fast hash is enough to make a leaked table useless.
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// uniqueScopes removes duplicate scopes while keeping their order
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+)
+
+// UserBadge records one award of a badge. One-time badges are only awarded at milestone 1;
+// repeatable badges are awarded again at every further milestone. The unique index on user,
+// badge and milestone is what keeps awarding idempotent.
+type UserBadge struct {
+	ID        string    `gorm:"primarykey" json:"id"`
+	UserID    string    `gorm:"not null;uniqueIndex:idx_user_badge_milestone,priority:1" json:"user_id"`
+	Badge     string    `gorm:"size:40;not null;uniqueIndex:idx_user_badge_milestone,priority:2" json:"badge"`
+	Tier      string    `gorm:"size:10;not null" json:"tier"`
+	Milestone int       `gorm:"not null;uniqueIndex:idx_user_badge_milestone,priority:3" json:"milestone"`
+	AwardedAt time.Time `gorm:"not null" json:"awarded_at"`
+}
+
+// TableName specifies the table name for the UserBadge model
+func (UserBadge) TableName() string {
+	return "user_badges"
+}
+
+// Badge tiers, from the easiest to the hardest to earn
+const (
+	TierBronze = "bronze"
+	TierSilver = "silver"
+	TierGold   = "gold"
+)
+
+// Metrics the badge rules are measured by. The modules that own the data register them with
+// the badge service.
+const (
+	MetricPosts           = "posts"
+	MetricAcceptedAnswers = "accepted_answers"
+	MetricFollowers       = "followers"
+	MetricMembershipDays  = "membership_days"
+)
+
+// Rule declares a badge and when it is earned: a user earns it once their metric reaches
+// Threshold. A repeatable badge is earned again at every further multiple of Threshold, and the
+// multiple reached is the award's milestone.
+type Rule struct {
+	Badge       string
+	Name        string
+	Description string
+	Tier        string
+	Repeatable  bool
+	Metric      string
+	Threshold   int64
+	// Events are the domain events after which the rule is evaluated for the users they involve
+	Events []string
+}
+
+// Rules is the badge catalogue
+var Rules = []Rule{
+	{
+		Badge:       "first_post",
+		Name:        "First Post",
+		Description: "Wrote a first post",
+		Tier:        TierBronze,
+		Metric:      MetricPosts,
+		Threshold:   1,
+		Events:      []string{"PostCreated"},
+	},
+	{
+		Badge:       "problem_solver",
+		Name:        "Problem Solver",
+		Description: "Had 10 answers accepted. Awarded again for every 10 more.",
+		Tier:        TierSilver,
+		Repeatable:  true,
+		Metric:      MetricAcceptedAnswers,
+		Threshold:   10,
+		Events:      []string{"AnswerAccepted"},
+	},
+	{
+		Badge:       "popular",
+		Name:        "Popular",
+		Description: "Reached 100 followers",
+		Tier:        TierGold,
+		Metric:      MetricFollowers,
+		Threshold:   100,
+		Events:      []string{"UserFollowed", "FollowRequestApproved"},
+	},
+	{
+		Badge:       "yearling",
+		Name:        "Yearling",
+		Description: "Active member for a year. Awarded again every year.",
+		Tier:        TierBronze,
+		Repeatable:  true,
+		Metric:      MetricMembershipDays,
+		Threshold:   365,
+		Events:      []string{"PostCreated", "QuestionCreated", "AnswerCreated", "VoteCast"},
+	},
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+)
+
+// BadgeResponse is a badge a user has earned. Count is how many times it was awarded, which is
+// only above 1 for repeatable badges.
+type BadgeResponse struct {
+	Badge          string    `json:"badge"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Tier           string    `json:"tier"`
+	Repeatable     bool      `json:"repeatable"`
+	Count          int       `json:"count"`
+	FirstAwardedAt time.Time `json:"first_awarded_at"`
+	LastAwardedAt  time.Time `json:"last_awarded_at"`
+}
+
+// UserBadgesResponse lists the badges on a user's profile with the number of awards per tier
+type UserBadgesResponse struct {
+	UserID string          `json:"user_id"`
+	Gold   int             `json:"gold"`
+	Silver int             `json:"silver"`
+	Bronze int             `json:"bronze"`
+	Badges []BadgeResponse `json:"badges"`
+}
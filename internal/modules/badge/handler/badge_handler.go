@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/badge/service"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+)
+
+// BadgeHandler handles HTTP requests for badges
+type BadgeHandler struct {
+	badgeService *service.BadgeService
+}
+
+// NewBadgeHandler creates a new badge handler instance
+func NewBadgeHandler(badgeService *service.BadgeService) *BadgeHandler {
+	return &BadgeHandler{
+		badgeService: badgeService,
+	}
+}
+
+// GetUserBadges retrieves the badges a user has earned
+// @Summary Get a user's badges
+// @Description Retrieve the badges a user has earned, gold first, with the number of awards per tier. Repeatable badges carry how many times they were awarded.
+// @Tags badges
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/{user_id}/badges [get]
+func (h *BadgeHandler) GetUserBadges(c *fiber.Ctx) error {
+	userID := c.Params("user_id")
+	if strings.TrimSpace(userID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
+	}
+
+	viewerID, _ := c.Locals("userID").(string)
+	badges, err := h.badgeService.GetUserBadges(userID, viewerID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return response.NotFoundJSON(c, "User")
+		}
+		if errors.Is(err, userService.ErrPrivateAccount) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "PRIVATE_ACCOUNT", "This account is private", "")
+		}
+		logger.Error("Failed to get badges", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve badges")
+	}
+
+	return response.SuccessJSON(c, badges, "Badges retrieved successfully")
+}
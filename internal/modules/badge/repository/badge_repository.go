@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/badge/domain"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BadgeRepository handles badge data operations
+type BadgeRepository struct {
+	db *gorm.DB
+}
+
+// NewBadgeRepository creates a new badge repository instance
+func NewBadgeRepository(db *gorm.DB) *BadgeRepository {
+	return &BadgeRepository{db: db}
+}
+
+// Award awards a badge at milestones 1 through upTo that the user does not hold yet and returns
+// the milestones it awarded. Milestones awarded concurrently or earlier are skipped, so awarding
+// the same milestones again changes nothing.
+func (r *BadgeRepository) Award(userID, badge, tier string, upTo int) ([]int, error) {
+	var held []int
+	if err := r.db.Model(&domain.UserBadge{}).
+		Where("user_id = ? AND badge = ?", userID, badge).
+		Pluck("milestone", &held).Error; err != nil {
+		return nil, err
+	}
+
+	heldSet := make(map[int]bool, len(held))
+	for _, milestone := range held {
+		heldSet[milestone] = true
+	}
+
+	var awarded []int
+	for milestone := 1; milestone <= upTo; milestone++ {
+		if heldSet[milestone] {
+			continue
+		}
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.UserBadge{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			Badge:     badge,
+			Tier:      tier,
+			Milestone: milestone,
+			AwardedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return awarded, result.Error
+		}
+		if result.RowsAffected > 0 {
+			awarded = append(awarded, milestone)
+		}
+	}
+
+	return awarded, nil
+}
+
+// GetByUser returns every badge award of a user, oldest first
+func (r *BadgeRepository) GetByUser(userID string) ([]domain.UserBadge, error) {
+	var badges []domain.UserBadge
+	if err := r.db.Where("user_id = ?", userID).Order("awarded_at ASC").Find(&badges).Error; err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
+
+// UserExists reports whether a user exists
+func (r *BadgeRepository) UserExists(userID string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&userDomain.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetUserEmail returns the email address badge notifications are sent to
+func (r *BadgeRepository) GetUserEmail(userID string) (string, error) {
+	var user userDomain.User
+	if err := r.db.Select("email").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", err
+	}
+	return user.Email, nil
+}
+
+// ExportUser returns a user's badge awards, for their personal data export
+func (r *BadgeRepository) ExportUser(userID string) (map[string]interface{}, error) {
+	badges, err := r.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"badges.json": badges,
+	}, nil
+}
+
+// PurgeUser deletes a user's badge awards. It runs inside the account deletion transaction.
+func (r *BadgeRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	return tx.Where("user_id = ?", userID).Delete(&domain.UserBadge{}).Error
+}
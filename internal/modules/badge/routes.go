@@ -0,0 +1,16 @@
+package badge
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
+	"github.com/topboyasante/pitstop/internal/modules/badge/handler"
+)
+
+// RegisterRoutes registers all badge-related routes
+func RegisterRoutes(router fiber.Router, badgeHandler *handler.BadgeHandler) {
+	users := router.Group("/users")
+
+	// Badges are shown on the profile; a private account's badges only to its followers
+	users.Get("/:user_id/badges", middleware.OptionalJWTMiddleware(config.Get()), badgeHandler.GetUserBadges)
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/mailer"
+	"github.com/topboyasante/pitstop/internal/modules/badge/domain"
+	"github.com/topboyasante/pitstop/internal/modules/badge/dto"
+	"github.com/topboyasante/pitstop/internal/modules/badge/repository"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+)
+
+// ErrUserNotFound is returned when the user whose badges are asked for does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// Metric measures a user's progress towards the badges of a rule, such as how many posts they wrote
+type Metric func(userID string) (int64, error)
+
+// tierRanks orders badges on a profile, hardest to earn first
+var tierRanks = map[string]int{
+	domain.TierGold:   0,
+	domain.TierSilver: 1,
+	domain.TierBronze: 2,
+}
+
+// BadgeService awards badges by evaluating the badge rules after the domain events they list
+type BadgeService struct {
+	badgeRepo     *repository.BadgeRepository
+	followService *userService.FollowService
+	eventBus      *events.EventBus
+	mailer        mailer.Mailer
+	rules         []domain.Rule
+	metrics       map[string]Metric
+}
+
+// NewBadgeService creates a new badge service instance with the badge catalogue. Modules provide
+// the metrics the rules are measured by with RegisterMetric.
+func NewBadgeService(badgeRepo *repository.BadgeRepository, followService *userService.FollowService, eventBus *events.EventBus, mailer mailer.Mailer) *BadgeService {
+	return &BadgeService{
+		badgeRepo:     badgeRepo,
+		followService: followService,
+		eventBus:      eventBus,
+		mailer:        mailer,
+		rules:         domain.Rules,
+		metrics:       make(map[string]Metric),
+	}
+}
+
+// RegisterMetric provides a metric badge rules are measured by. Rules whose metric is not
+// registered are never awarded.
+func (s *BadgeService) RegisterMetric(name string, metric Metric) {
+	s.metrics[name] = metric
+}
+
+// SubscribeToEvents evaluates the rules after each event they list, and emails users the badges
+// they are awarded
+func (s *BadgeService) SubscribeToEvents() {
+	s.eventBus.Subscribe("BadgeAwarded", func(event events.Event) {
+		awarded := event.(*events.BadgeAwarded)
+		if err := s.notify(awarded); err != nil {
+			logger.Error("Failed to send badge notification", "badge", awarded.Badge, "user_id", awarded.UserID, "error", err)
+		}
+	})
+
+	subscribed := make(map[string]bool)
+	for _, rule := range s.rules {
+		for _, name := range rule.Events {
+			if subscribed[name] {
+				continue
+			}
+			subscribed[name] = true
+
+			eventName := name
+			s.eventBus.Subscribe(eventName, func(event events.Event) {
+				s.evaluate(eventName, involvedUsers(event))
+			})
+		}
+	}
+}
+
+// involvedUsers returns the users an event involves, whose progress towards a badge it may have changed
+func involvedUsers(event events.Event) []string {
+	switch e := event.(type) {
+	case *events.PostCreated:
+		return []string{e.AuthorID}
+	case *events.QuestionCreated:
+		return []string{e.AuthorID}
+	case *events.AnswerCreated:
+		return []string{e.AuthorID}
+	case *events.AnswerAccepted:
+		return []string{e.AnswerAuthorID, e.QuestionAuthorID}
+	case *events.VoteCast:
+		return []string{e.VoterID, e.AuthorID}
+	case *events.UserFollowed:
+		return []string{e.FollowingID, e.FollowerID}
+	case *events.FollowRequestApproved:
+		return []string{e.TargetID, e.RequesterID}
+	}
+	return nil
+}
+
+// evaluate checks the rules listing an event for each user the event involves
+func (s *BadgeService) evaluate(eventName string, userIDs []string) {
+	for _, rule := range s.rules {
+		if !listsEvent(rule, eventName) {
+			continue
+		}
+		for _, userID := range userIDs {
+			if userID == "" || userID == userDomain.DeletedUserID {
+				continue
+			}
+			if err := s.evaluateRule(rule, userID); err != nil {
+				logger.Error("Failed to evaluate badge rule", "badge", rule.Badge, "user_id", userID, "event", eventName, "error", err)
+			}
+		}
+	}
+}
+
+func listsEvent(rule domain.Rule, eventName string) bool {
+	for _, name := range rule.Events {
+		if name == eventName {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateRule awards the milestones of a rule the user has reached and not been awarded yet.
+// Evaluating a rule again, for instance when an event is replayed, awards nothing new.
+func (s *BadgeService) evaluateRule(rule domain.Rule, userID string) error {
+	metric, ok := s.metrics[rule.Metric]
+	if !ok || rule.Threshold < 1 {
+		return nil
+	}
+
+	value, err := metric(userID)
+	if err != nil {
+		return fmt.Errorf("failed to measure %s: %w", rule.Metric, err)
+	}
+
+	milestones := value / rule.Threshold
+	if !rule.Repeatable && milestones > 1 {
+		milestones = 1
+	}
+	if milestones < 1 {
+		return nil
+	}
+
+	awarded, err := s.badgeRepo.Award(userID, rule.Badge, rule.Tier, int(milestones))
+	for _, milestone := range awarded {
+		logger.Info("Badge awarded", "badge", rule.Badge, "user_id", userID, "milestone", milestone)
+		s.eventBus.Publish("BadgeAwarded", events.NewBadgeAwarded(userID, rule.Badge, rule.Tier, milestone))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to award badge: %w", err)
+	}
+	return nil
+}
+
+// notify emails the user the badge they were awarded
+func (s *BadgeService) notify(awarded *events.BadgeAwarded) error {
+	email, err := s.badgeRepo.GetUserEmail(awarded.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user email: %w", err)
+	}
+	if email == "" {
+		return nil
+	}
+
+	rule := domain.Rule{Badge: awarded.Badge, Name: awarded.Badge}
+	for _, candidate := range s.rules {
+		if candidate.Badge == awarded.Badge {
+			rule = candidate
+			break
+		}
+	}
+
+	earned := fmt.Sprintf("You earned the %s %s badge on Pitstop.", awarded.Tier, rule.Name)
+	if awarded.Milestone > 1 {
+		earned = fmt.Sprintf("You earned the %s %s badge on Pitstop again, %d times so far.", awarded.Tier, rule.Name, awarded.Milestone)
+	}
+	description := strings.TrimSuffix(rule.Description, ".")
+
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("New badge: %s", rule.Name),
+		Text:    fmt.Sprintf("%s\n\n%s.", earned, description),
+		HTML:    fmt.Sprintf("<p>%s</p><p>%s.</p>", html.EscapeString(earned), html.EscapeString(description)),
+	})
+}
+
+// GetUserBadges returns the badges a user has earned, hardest tier first. A private account's
+// badges are only shown to its followers.
+func (s *BadgeService) GetUserBadges(userID, viewerID string) (*dto.UserBadgesResponse, error) {
+	exists, err := s.badgeRepo.UserExists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user: %w", err)
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	visible, err := s.followService.CanViewContent(viewerID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check badge visibility: %w", err)
+	}
+	if !visible {
+		return nil, userService.ErrPrivateAccount
+	}
+
+	awards, err := s.badgeRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badges: %w", err)
+	}
+
+	rules := make(map[string]domain.Rule, len(s.rules))
+	for _, rule := range s.rules {
+		rules[rule.Badge] = rule
+	}
+
+	result := &dto.UserBadgesResponse{UserID: userID, Badges: []dto.BadgeResponse{}}
+	byBadge := make(map[string]*dto.BadgeResponse)
+	var order []string
+	for _, award := range awards {
+		switch award.Tier {
+		case domain.TierGold:
+			result.Gold++
+		case domain.TierSilver:
+			result.Silver++
+		case domain.TierBronze:
+			result.Bronze++
+		}
+
+		// Awards come oldest first, so the first one seen is the first awarded
+		badge, ok := byBadge[award.Badge]
+		if !ok {
+			// Badges retired from the catalogue keep their awards and are shown by their ID
+			rule, known := rules[award.Badge]
+			if !known {
+				rule = domain.Rule{Badge: award.Badge, Name: award.Badge, Tier: award.Tier}
+			}
+			badge = &dto.BadgeResponse{
+				Badge:          award.Badge,
+				Name:           rule.Name,
+				Description:    rule.Description,
+				Tier:           award.Tier,
+				Repeatable:     rule.Repeatable,
+				FirstAwardedAt: award.AwardedAt,
+			}
+			byBadge[award.Badge] = badge
+			order = append(order, award.Badge)
+		}
+		badge.Count++
+		badge.LastAwardedAt = award.AwardedAt
+	}
+
+	for _, name := range order {
+		result.Badges = append(result.Badges, *byBadge[name])
+	}
+	sort.SliceStable(result.Badges, func(i, j int) bool {
+		return tierRanks[result.Badges[i].Tier] < tierRanks[result.Badges[j].Tier]
+	})
+
+	return result, nil
+}
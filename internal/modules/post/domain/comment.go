@@ -6,22 +6,35 @@ import (
 	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
 )
 
-// Comment represents a comment entity
+// Comment represents a polymorphic comment entity. CommentableID and CommentableType identify
+// what the comment was left on, so posts, questions and answers share one comments table:
+// - Comment on a post: CommentableID="post-123", CommentableType="post"
+// - Comment on an answer: CommentableID="answer-456", CommentableType="answer"
+//
+// Replies carry the same commentable as the comment they reply to.
 type Comment struct {
-	ID        string                   `gorm:"primarykey" json:"id"`
-	PostID    string                   `gorm:"not null" json:"post_id" validate:"required"`
-	UserID    string                   `gorm:"not null" json:"user_id" validate:"required"`
-	User      *userDomain.User         `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
-	ParentID  *string                  `gorm:"default:null" json:"parent_id,omitempty"`
-	Parent    *Comment                 `gorm:"foreignKey:ParentID;references:ID" json:"parent,omitempty"`
-	Replies   []Comment                `gorm:"foreignKey:ParentID" json:"replies,omitempty"`
-	Content   string                   `gorm:"type:text" json:"content" validate:"required"`
-	LikeCount int64                    `gorm:"-" json:"like_count"`
-	CreatedAt time.Time                `json:"created_at"`
-	UpdatedAt time.Time                `json:"updated_at"`
+	ID              string                   `gorm:"primarykey" json:"id"`
+	CommentableID   string                   `gorm:"not null;index:idx_commentable" json:"commentable_id" validate:"required"`
+	CommentableType string                   `gorm:"size:20;not null;index:idx_commentable" json:"commentable_type" validate:"required,oneof=post question answer"`
+	UserID          string                   `gorm:"not null" json:"user_id" validate:"required"`
+	User            *userDomain.User         `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	ParentID        *string                  `gorm:"default:null" json:"parent_id,omitempty"`
+	Parent          *Comment                 `gorm:"foreignKey:ParentID;references:ID" json:"parent,omitempty"`
+	Replies         []Comment                `gorm:"foreignKey:ParentID" json:"replies,omitempty"`
+	Content         string                   `gorm:"type:text" json:"content" validate:"required"`
+	LikeCount       int64                    `gorm:"-" json:"like_count"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
 }
 
 // TableName specifies the table name for the Comment model
 func (Comment) TableName() string {
 	return "comments"
-}
\ No newline at end of file
+}
+
+// Commentable type constants
+const (
+	CommentableTypePost     = "post"
+	CommentableTypeQuestion = "question"
+	CommentableTypeAnswer   = "answer"
+)
\ No newline at end of file
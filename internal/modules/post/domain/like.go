@@ -8,12 +8,12 @@ import (
 
 // Like represents a polymorphic like entity that can be applied to different types of content.
 // This uses a polymorphic association pattern where:
-// - LikableID stores the ID of the entity being liked (post ID, comment ID, etc.)
-// - LikableType identifies what type of entity it is ("post", "comment", etc.)
+// - LikableID stores the ID of the entity being liked (post ID, comment ID, question ID, answer ID)
+// - LikableType identifies what type of entity it is ("post", "comment", "question", "answer")
 //
 // This approach allows us to:
 // 1. Use a single likes table for all content types instead of separate tables
-// 2. Reuse the same like functionality across posts, comments, and future content
+// 2. Reuse the same like functionality across posts, comments, questions and answers
 // 3. Maintain consistent like behavior and counting across all "likable" entities
 //
 // Examples:
@@ -22,7 +22,7 @@ import (
 type Like struct {
 	ID          string                   `gorm:"primarykey" json:"id"`
 	LikableID   string                   `gorm:"not null;index:idx_likable_user_like,unique" json:"likable_id" validate:"required"`
-	LikableType string                   `gorm:"not null;index:idx_likable_user_like,unique" json:"likable_type" validate:"required,oneof=post comment"`
+	LikableType string                   `gorm:"not null;index:idx_likable_user_like,unique" json:"likable_type" validate:"required,oneof=post comment question answer"`
 	UserID      string                   `gorm:"not null;index:idx_likable_user_like,unique" json:"user_id" validate:"required"`
 	User        *userDomain.User         `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
 	CreatedAt   time.Time                `json:"created_at"`
@@ -35,6 +35,8 @@ func (Like) TableName() string {
 
 // Like type constants
 const (
-	LikableTypePost    = "post"
-	LikableTypeComment = "comment"
+	LikableTypePost     = "post"
+	LikableTypeComment  = "comment"
+	LikableTypeQuestion = "question"
+	LikableTypeAnswer   = "answer"
 )
\ No newline at end of file
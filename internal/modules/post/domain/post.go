@@ -12,9 +12,10 @@ type Post struct {
 	UserID       string                   `gorm:"not null" json:"user_id" validate:"required"`
 	User         *userDomain.User         `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
 	Content      string                   `gorm:"type:text" json:"content" validate:"required"`
-	Comments     []Comment                `gorm:"foreignKey:PostID" json:"comments,omitempty"`
+	Comments     []Comment                `gorm:"polymorphic:Commentable;polymorphicValue:post" json:"comments,omitempty"`
 	CommentCount int64                    `gorm:"-" json:"comment_count"`
 	LikeCount    int64                    `gorm:"-" json:"like_count"`
+	ViewCount    int64                    `gorm:"not null;default:0" json:"view_count"` // Distinct viewers, added by the view flusher
 	CreatedAt    time.Time                `json:"created_at"`
 	UpdatedAt    time.Time                `json:"updated_at"`
 }
@@ -7,17 +7,19 @@ type CreateCommentRequest struct {
 
 // CommentResponse represents a comment response
 type CommentResponse struct {
-	ID        string          `json:"id"`
-	PostID    string          `json:"post_id"`
-	UserID    string          `json:"user_id"`
-	User      *UserResponse   `json:"user,omitempty"`
-	ParentID  *string         `json:"parent_id,omitempty"`
-	Parent    *CommentResponse `json:"parent,omitempty"`
-	Replies   []CommentResponse `json:"replies,omitempty"`
-	Content   string          `json:"content"`
-	LikeCount int64           `json:"like_count"`
-	CreatedAt string          `json:"created_at"`
-	UpdatedAt string          `json:"updated_at"`
+	ID              string            `json:"id"`
+	CommentableID   string            `json:"commentable_id"`
+	CommentableType string            `json:"commentable_type"`
+	PostID          string            `json:"post_id,omitempty"`
+	UserID          string            `json:"user_id"`
+	User            *UserResponse     `json:"user,omitempty"`
+	ParentID        *string           `json:"parent_id,omitempty"`
+	Parent          *CommentResponse  `json:"parent,omitempty"`
+	Replies         []CommentResponse `json:"replies,omitempty"`
+	Content         string            `json:"content"`
+	LikeCount       int64             `json:"like_count"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
 }
 
 // UserResponse represents a simplified user response for comments
@@ -13,14 +13,16 @@ type LikeUserResponse struct {
 
 // LikeResponse represents a like in API responses
 type LikeResponse struct {
-	ID        string            `json:"id"`
-	PostID    string            `json:"post_id"`
-	UserID    string            `json:"user_id"`
-	User      *LikeUserResponse `json:"user,omitempty"`
-	CreatedAt time.Time         `json:"created_at"`
+	ID          string            `json:"id"`
+	LikableID   string            `json:"likable_id"`
+	LikableType string            `json:"likable_type"`
+	PostID      string            `json:"post_id,omitempty"`
+	UserID      string            `json:"user_id"`
+	User        *LikeUserResponse `json:"user,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
 }
 
-// LikesResponse represents a list of likes for a post
+// LikesResponse represents a list of likes for a post, comment, question or answer
 type LikesResponse struct {
 	Likes      []LikeResponse `json:"likes"`
 	TotalCount int64          `json:"total_count"`
@@ -30,6 +30,7 @@ type PostResponse struct {
 	User         *PostUserResponse `json:"user"`
 	CommentCount int64             `json:"comment_count"`
 	LikeCount    int64             `json:"like_count"`
+	ViewCount    int64             `json:"view_count"`
 	CreatedAt    time.Time         `json:"created_at"`
 	UpdatedAt    time.Time         `json:"updated_at"`
 }
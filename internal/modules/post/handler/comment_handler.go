@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/core/response"
@@ -51,6 +54,9 @@ func (h *CommentHandler) CreateComment(c *fiber.Ctx) error {
 		if err.Error() == "post not found" {
 			return response.NotFoundJSON(c, "Post not found")
 		}
+		if errors.Is(err, service.ErrBlocked) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot comment on this post", "")
+		}
 		logger.Error("Failed to create comment", "error", err)
 		return response.InternalErrorJSON(c, "Failed to create comment")
 	}
@@ -96,6 +102,9 @@ func (h *CommentHandler) CreateReply(c *fiber.Ctx) error {
 		if err.Error() == "parent comment doesn't belong to this post" {
 			return response.ValidationErrorJSON(c, err.Error(), "")
 		}
+		if errors.Is(err, service.ErrBlocked) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot reply to this comment", "")
+		}
 		logger.Error("Failed to create reply", "error", err)
 		return response.InternalErrorJSON(c, "Failed to create reply")
 	}
@@ -105,21 +114,142 @@ func (h *CommentHandler) CreateReply(c *fiber.Ctx) error {
 
 // GetComments handles retrieving comments for a post
 // @Summary Get comments for a post
-// @Description Retrieve all comments for a specific post
+// @Description Retrieve all comments for a specific post. When signed in, comments by users you blocked, who blocked you or whom you muted are left out.
 // @Tags Comments
 // @Produce json
 // @Param post_id path string true "Post ID"
 // @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /posts/{post_id}/comments [get]
 func (h *CommentHandler) GetComments(c *fiber.Ctx) error {
 	postID := c.Params("post_id")
 
-	comments, err := h.commentService.GetCommentsByPostID(postID)
+	viewerID, _ := c.Locals("userID").(string)
+
+	comments, err := h.commentService.GetCommentsByPostID(postID, viewerID)
 	if err != nil {
+		if err.Error() == "post not found" {
+			return response.NotFoundJSON(c, "Post not found")
+		}
 		logger.Error("Failed to retrieve comments", "error", err)
 		return response.InternalErrorJSON(c, "Failed to retrieve comments")
 	}
 
 	return response.SuccessJSON(c, comments, "Comments retrieved successfully")
+}
+
+// CreateCommentOn returns a handler that creates a comment on a question or answer. The content
+// ID is read from the idParam route parameter, and the ID of the question an answer belongs to
+// from containerParam, which is empty for questions.
+func (h *CommentHandler) CreateCommentOn(commentableType, idParam, containerParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		commentableID := c.Params(idParam)
+		containerID := routeParam(c, containerParam)
+		userID := c.Locals("userID").(string)
+
+		var req dto.CreateCommentRequest
+		if err := c.BodyParser(&req); err != nil {
+			logger.Error("Invalid request body", "error", err)
+			return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+		}
+
+		// Basic validation
+		if req.Content == "" {
+			return response.ValidationErrorJSON(c, "Content is required", "")
+		}
+
+		comment, err := h.commentService.CreateCommentOn(commentableType, commentableID, containerID, userID, &req)
+		if err != nil {
+			if err.Error() == commentableType+" not found" {
+				return response.NotFoundJSON(c, resourceName(commentableType))
+			}
+			if errors.Is(err, service.ErrBlocked) {
+				return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot comment on this "+commentableType, "")
+			}
+			logger.Error("Failed to create comment", "commentable_type", commentableType, "error", err)
+			return response.InternalErrorJSON(c, "Failed to create comment")
+		}
+
+		return response.SuccessJSON(c, comment, "Comment created successfully")
+	}
+}
+
+// CreateReplyOn returns a handler that replies to a comment on a question or answer
+func (h *CommentHandler) CreateReplyOn(commentableType, idParam, containerParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		commentableID := c.Params(idParam)
+		containerID := routeParam(c, containerParam)
+		parentCommentID := c.Params("parent_comment_id")
+		userID := c.Locals("userID").(string)
+
+		var req dto.CreateCommentRequest
+		if err := c.BodyParser(&req); err != nil {
+			logger.Error("Invalid request body", "error", err)
+			return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+		}
+
+		// Basic validation
+		if req.Content == "" {
+			return response.ValidationErrorJSON(c, "Content is required", "")
+		}
+
+		reply, err := h.commentService.CreateReplyOn(commentableType, commentableID, containerID, parentCommentID, userID, &req)
+		if err != nil {
+			if err.Error() == commentableType+" not found" {
+				return response.NotFoundJSON(c, resourceName(commentableType))
+			}
+			if err.Error() == "parent comment not found" {
+				return response.NotFoundJSON(c, "Parent comment")
+			}
+			if strings.HasPrefix(err.Error(), "parent comment doesn't belong") {
+				return response.ValidationErrorJSON(c, err.Error(), "")
+			}
+			if errors.Is(err, service.ErrBlocked) {
+				return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot reply to this comment", "")
+			}
+			logger.Error("Failed to create reply", "commentable_type", commentableType, "error", err)
+			return response.InternalErrorJSON(c, "Failed to create reply")
+		}
+
+		return response.SuccessJSON(c, reply, "Reply created successfully")
+	}
+}
+
+// GetCommentsOn returns a handler that lists the comments on a question or answer. When signed
+// in, comments by users you blocked, who blocked you or whom you muted are left out.
+func (h *CommentHandler) GetCommentsOn(commentableType, idParam, containerParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		commentableID := c.Params(idParam)
+		containerID := routeParam(c, containerParam)
+
+		viewerID, _ := c.Locals("userID").(string)
+
+		comments, err := h.commentService.GetCommentsOn(commentableType, commentableID, containerID, viewerID)
+		if err != nil {
+			if err.Error() == commentableType+" not found" {
+				return response.NotFoundJSON(c, resourceName(commentableType))
+			}
+			logger.Error("Failed to retrieve comments", "commentable_type", commentableType, "error", err)
+			return response.InternalErrorJSON(c, "Failed to retrieve comments")
+		}
+
+		return response.SuccessJSON(c, comments, "Comments retrieved successfully")
+	}
+}
+
+// routeParam reads a route parameter, or returns "" when the route has none to read
+func routeParam(c *fiber.Ctx, name string) string {
+	if name == "" {
+		return ""
+	}
+	return c.Params(name)
+}
+
+// resourceName turns a commentable or likable type into the resource name used in error messages
+func resourceName(contentType string) string {
+	if contentType == "" {
+		return contentType
+	}
+	return strings.ToUpper(contentType[:1]) + contentType[1:]
 }
\ No newline at end of file
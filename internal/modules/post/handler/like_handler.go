@@ -82,7 +82,9 @@ func (h *LikeHandler) GetLikesByPost(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid post ID", "Post ID cannot be empty")
 	}
 
-	likes, err := h.likeService.GetLikesByPost(postID)
+	viewerID, _ := c.Locals("userID").(string)
+
+	likes, err := h.likeService.GetLikesByPost(postID, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve likes", "post_id", postID, "error", err)
 		if strings.Contains(err.Error(), "post not found") {
@@ -204,7 +206,9 @@ func (h *LikeHandler) GetLikesByComment(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid comment ID", "Comment ID cannot be empty")
 	}
 
-	likes, err := h.likeService.GetLikesByComment(postID, commentID)
+	viewerID, _ := c.Locals("userID").(string)
+
+	likes, err := h.likeService.GetLikesByComment(postID, commentID, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve comment likes", "post_id", postID, "comment_id", commentID, "error", err)
 		if strings.Contains(err.Error(), "post not found") {
@@ -254,4 +258,90 @@ func (h *LikeHandler) CheckUserLikedComment(c *fiber.Ctx) error {
 	}
 
 	return response.SuccessJSON(c, map[string]bool{"liked": liked}, "Comment like status retrieved successfully")
+}
+
+// ToggleLikeOn returns a handler that likes or unlikes a question or answer. The content ID is
+// read from the idParam route parameter, and the ID of the question an answer belongs to from
+// containerParam, which is empty for questions.
+func (h *LikeHandler) ToggleLikeOn(likableType, idParam, containerParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		likableID := c.Params(idParam)
+		containerID := routeParam(c, containerParam)
+		if strings.TrimSpace(likableID) == "" {
+			return response.ValidationErrorJSON(c, "Invalid "+likableType+" ID", resourceName(likableType)+" ID cannot be empty")
+		}
+
+		// Extract user ID from JWT claims
+		userID, err := utils.ExtractUserIDFromContext(c)
+		if err != nil {
+			logger.Error("Failed to extract user ID from context", "error", err)
+			return response.UnauthorizedJSON(c)
+		}
+
+		result, err := h.likeService.ToggleLike(likableType, likableID, containerID, userID)
+		if err != nil {
+			logger.Error("Failed to toggle like", "likable_type", likableType, "likable_id", likableID, "user_id", userID, "error", err)
+			if strings.Contains(err.Error(), likableType+" not found") {
+				return response.NotFoundJSON(c, resourceName(likableType))
+			}
+			return response.InternalErrorJSON(c, "Failed to toggle like")
+		}
+
+		action := "liked"
+		if !result.Liked {
+			action = "unliked"
+		}
+
+		logger.Info("Like toggled successfully", "likable_type", likableType, "likable_id", likableID, "user_id", userID, "action", action)
+		return response.SuccessJSON(c, result, resourceName(likableType)+" "+action+" successfully")
+	}
+}
+
+// GetLikesOn returns a handler that lists the users who liked a question or answer
+func (h *LikeHandler) GetLikesOn(likableType, idParam, containerParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		likableID := c.Params(idParam)
+		containerID := routeParam(c, containerParam)
+		if strings.TrimSpace(likableID) == "" {
+			return response.ValidationErrorJSON(c, "Invalid "+likableType+" ID", resourceName(likableType)+" ID cannot be empty")
+		}
+
+		viewerID, _ := c.Locals("userID").(string)
+
+		likes, err := h.likeService.GetLikes(likableType, likableID, containerID, viewerID)
+		if err != nil {
+			logger.Error("Failed to retrieve likes", "likable_type", likableType, "likable_id", likableID, "error", err)
+			if strings.Contains(err.Error(), likableType+" not found") {
+				return response.NotFoundJSON(c, resourceName(likableType))
+			}
+			return response.InternalErrorJSON(c, "Failed to retrieve likes")
+		}
+
+		return response.SuccessJSON(c, likes, "Likes retrieved successfully")
+	}
+}
+
+// CheckUserLikedOn returns a handler that reports whether the authenticated user liked a question or answer
+func (h *LikeHandler) CheckUserLikedOn(likableType, idParam string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		likableID := c.Params(idParam)
+		if strings.TrimSpace(likableID) == "" {
+			return response.ValidationErrorJSON(c, "Invalid "+likableType+" ID", resourceName(likableType)+" ID cannot be empty")
+		}
+
+		// Extract user ID from JWT claims
+		userID, err := utils.ExtractUserIDFromContext(c)
+		if err != nil {
+			logger.Error("Failed to extract user ID from context", "error", err)
+			return response.UnauthorizedJSON(c)
+		}
+
+		liked, err := h.likeService.CheckUserLiked(likableType, likableID, userID)
+		if err != nil {
+			logger.Error("Failed to check user like status", "likable_type", likableType, "likable_id", likableID, "user_id", userID, "error", err)
+			return response.InternalErrorJSON(c, "Failed to check like status")
+		}
+
+		return response.SuccessJSON(c, map[string]bool{"liked": liked}, "Like status retrieved successfully")
+	}
 }
\ No newline at end of file
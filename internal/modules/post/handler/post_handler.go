@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -25,20 +26,28 @@ func NewPostHandler(postService *service.PostService) *PostHandler {
 
 // GetAllPosts retrieves all posts
 // @Summary Get all posts
-// @Description Retrieve a paginated list of posts
+// @Description Retrieve a paginated list of posts. When signed in, posts by users you blocked, who blocked you or whom you muted are left out.
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Posts per page" default(20)
+// @Param sort query string false "Sort order" Enums(newest, hot) default(newest)
 // @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
 // @Router /posts [get]
 func (h *PostHandler) GetAllPosts(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	sort := c.Query("sort")
+
+	viewerID, _ := c.Locals("userID").(string)
 
-	posts, err := h.postService.GetAllPosts(page, limit)
+	posts, err := h.postService.GetAllPosts(page, limit, sort, viewerID)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidSort) {
+			return response.ValidationErrorJSON(c, "Invalid sort", err.Error())
+		}
 		logger.Error("Failed to retrieve posts", "error", err)
 		return response.InternalErrorJSON(c, "Failed to retrieve posts")
 	}
@@ -77,7 +86,7 @@ func (h *PostHandler) CreatePost(c *fiber.Ctx) error {
 
 // GetPost retrieves a specific post by ID
 // @Summary Get a post by ID
-// @Description Retrieve a specific post
+// @Description Retrieve a specific post and count the view. Posts by private accounts are only shown to their followers.
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -91,10 +100,14 @@ func (h *PostHandler) GetPost(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid post ID", "ID cannot be empty")
 	}
 
-	post, err := h.postService.GetPostByID(id)
+	viewerID, _ := c.Locals("userID").(string)
+
+	post, err := h.postService.GetPostByID(id, viewerID)
 	if err != nil {
 		return response.NotFoundJSON(c, "Post")
 	}
 
+	h.postService.RecordView(id, viewerID, c.IP(), c.Get(fiber.HeaderUserAgent))
+
 	return response.SuccessJSON(c, post, "Post retrieved successfully")
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/google/uuid"
 	"github.com/topboyasante/pitstop/internal/modules/post/domain"
+	userRepository "github.com/topboyasante/pitstop/internal/modules/user/repository"
 	"gorm.io/gorm"
 )
 
@@ -40,14 +41,18 @@ func (r *CommentRepository) GetByID(id string) (*domain.Comment, error) {
 	return &comment, nil
 }
 
-// GetByPostID retrieves all comments for a specific post with nested replies
-func (r *CommentRepository) GetByPostID(postID string) ([]domain.Comment, error) {
+// GetByCommentable retrieves all comments on a post, question or answer with nested replies.
+// Comments and replies by users the viewer blocked, was blocked by or muted are left out.
+func (r *CommentRepository) GetByCommentable(commentableType, commentableID, viewerID string) ([]domain.Comment, error) {
 	var comments []domain.Comment
 	err := r.db.Preload("User").
 		Preload("Replies", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("User").Order("created_at ASC")
+			return db.Preload("User").
+				Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID)).
+				Order("created_at ASC")
 		}).
-		Where("post_id = ? AND parent_id IS NULL", postID).
+		Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID)).
+		Where("commentable_id = ? AND commentable_type = ? AND parent_id IS NULL", commentableID, commentableType).
 		Order("created_at DESC").
 		Find(&comments).Error
 		
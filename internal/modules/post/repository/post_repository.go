@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/topboyasante/pitstop/internal/modules/post/domain"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	userRepository "github.com/topboyasante/pitstop/internal/modules/user/repository"
+	viewDomain "github.com/topboyasante/pitstop/internal/modules/view/domain"
+	"github.com/topboyasante/pitstop/internal/shared/suggestion"
 	"gorm.io/gorm"
 )
 
@@ -43,7 +49,7 @@ func (r *PostRepository) GetByID(id string) (*domain.Post, error) {
 
 	// Calculate total comment count (including replies)
 	var commentCount int64
-	r.db.Model(&domain.Comment{}).Where("post_id = ?", id).Count(&commentCount)
+	r.db.Model(&domain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", id, domain.CommentableTypePost).Count(&commentCount)
 	post.CommentCount = commentCount
 
 	// Calculate like count
@@ -54,23 +60,47 @@ func (r *PostRepository) GetByID(id string) (*domain.Post, error) {
 	return &post, nil
 }
 
-// GetAll retrieves all posts with pagination and comment counts
-func (r *PostRepository) GetAll(page, limit int) ([]domain.Post, int64, error) {
+// Post sort orders
+const (
+	PostSortNewest = "newest"
+	PostSortHot    = "hot"
+)
+
+var postSortOrders = map[string]string{
+	PostSortNewest: "created_at DESC",
+	PostSortHot:    viewDomain.HotRank("posts") + " DESC, created_at DESC",
+}
+
+// ValidPostSort reports whether sort is a supported post sort order
+func ValidPostSort(sort string) bool {
+	_, ok := postSortOrders[sort]
+	return ok
+}
+
+// GetAll retrieves all posts with pagination and comment counts, in the given sort order. Posts by users the viewer
+// blocked, was blocked by or muted are left out, as are posts by private accounts the viewer does not follow.
+func (r *PostRepository) GetAll(page, limit int, sort, viewerID string) ([]domain.Post, int64, error) {
 	var posts []domain.Post
 	var totalCount int64
 
 	offset := (page - 1) * limit
 
 	// Get total count
-	if err := r.db.Model(&domain.Post{}).Count(&totalCount).Error; err != nil {
+	if err := r.db.Model(&domain.Post{}).Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID), userRepository.ExcludePrivateUsers("user_id", viewerID)).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
+	order, ok := postSortOrders[sort]
+	if !ok {
+		order = postSortOrders[PostSortNewest]
+	}
+
 	// Get posts with comment counts
 	if err := r.db.Preload("User").
+		Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID), userRepository.ExcludePrivateUsers("user_id", viewerID)).
 		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
+		Order(order).
 		Find(&posts).Error; err != nil {
 		return nil, 0, err
 	}
@@ -78,7 +108,7 @@ func (r *PostRepository) GetAll(page, limit int) ([]domain.Post, int64, error) {
 	// Calculate comment and like counts for each post
 	for i := range posts {
 		var commentCount int64
-		r.db.Model(&domain.Comment{}).Where("post_id = ?", posts[i].ID).Count(&commentCount)
+		r.db.Model(&domain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", posts[i].ID, domain.CommentableTypePost).Count(&commentCount)
 		posts[i].CommentCount = commentCount
 		
 		var likeCount int64
@@ -89,12 +119,105 @@ func (r *PostRepository) GetAll(page, limit int) ([]domain.Post, int64, error) {
 	return posts, totalCount, nil
 }
 
-// Update updates a post
+// Update updates a post. The view count is left alone, it only changes through view flushes.
 func (r *PostRepository) Update(post *domain.Post) error {
-	return r.db.Save(post).Error
+	return r.db.Omit("view_count").Save(post).Error
 }
 
 // Delete soft deletes a post
 func (r *PostRepository) Delete(id string) error {
 	return r.db.Where("id = ?", id).Delete(&domain.Post{}).Error
 }
+
+// AddViews adds flushed view counts, keyed by post ID, to the posts' view counts
+func (r *PostRepository) AddViews(counts map[string]int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for postID, views := range counts {
+			if err := tx.Model(&domain.Post{}).
+				Where("id = ?", postID).
+				UpdateColumn("view_count", gorm.Expr("view_count + ?", views)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountByUser counts the posts a user has written
+func (r *PostRepository) CountByUser(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Post{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ActiveUsers scores users by the posts and comments they wrote during the activity window.
+// It ranks candidates for user suggestions and does not depend on who is asking.
+func (r *PostRepository) ActiveUsers(userID string, limit int) (map[string]float64, error) {
+	since := time.Now().Add(-suggestion.ActivityWindow)
+	posts := r.db.Model(&domain.Post{}).Select("user_id").Where("created_at > ?", since)
+	comments := r.db.Model(&domain.Comment{}).Select("user_id").Where("created_at > ?", since)
+
+	var rows []suggestion.ScoredUser
+	err := r.db.Table("(?) AS activity", r.db.Raw("? UNION ALL ?", posts, comments)).
+		Select("user_id, COUNT(*) AS score").
+		Where("user_id <> ?", userID).
+		Group("user_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestion.ScoresByUser(rows), nil
+}
+
+// ExportUser returns the posts, comments and likes a user created, for their personal data export
+func (r *PostRepository) ExportUser(userID string) (map[string]interface{}, error) {
+	var posts []domain.Post
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []domain.Comment
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	var likes []domain.Like
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&likes).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"posts.json":    posts,
+		"comments.json": comments,
+		"likes.json":    likes,
+	}, nil
+}
+
+// PurgeUser erases a user's posts, with the comments and likes on them, and the user's likes.
+// The user's comments on other posts are reassigned to the deleted user placeholder so reply
+// threads stay intact. It runs inside the account deletion transaction.
+func (r *PostRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	postIDs := tx.Model(&domain.Post{}).Select("id").Where("user_id = ?", userID)
+	commentIDs := tx.Model(&domain.Comment{}).Select("id").
+		Where("commentable_type = ? AND commentable_id IN (?)", domain.CommentableTypePost, postIDs)
+
+	if err := tx.Where("user_id = ? OR (likable_type = ? AND likable_id IN (?)) OR (likable_type = ? AND likable_id IN (?))",
+		userID, domain.LikableTypePost, postIDs, domain.LikableTypeComment, commentIDs).
+		Delete(&domain.Like{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&domain.Comment{}).
+		Where("user_id = ? AND NOT (commentable_type = ? AND commentable_id IN (?))", userID, domain.CommentableTypePost, postIDs).
+		Update("user_id", userDomain.DeletedUserID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("commentable_type = ? AND commentable_id IN (?)", domain.CommentableTypePost, postIDs).Delete(&domain.Comment{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("user_id = ?", userID).Delete(&domain.Post{}).Error
+}
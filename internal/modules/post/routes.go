@@ -10,13 +10,15 @@ import (
 // RegisterRoutes registers all post-related routes
 func RegisterRoutes(router fiber.Router, postHandler *handler.PostHandler, commentHandler *handler.CommentHandler, likeHandler *handler.LikeHandler) {
 	posts := router.Group("/posts")
+	optionalJWT := middleware.OptionalJWTMiddleware(config.Get())
 	
-	// Public routes
-	posts.Get("/", postHandler.GetAllPosts)
-	posts.Get("/:id", postHandler.GetPost)
-	posts.Get("/:post_id/comments", commentHandler.GetComments)
-	posts.Get("/:post_id/likes", likeHandler.GetLikesByPost)
-	posts.Get("/:post_id/comments/:comment_id/likes", likeHandler.GetLikesByComment)
+	// Public routes. A signed-in viewer does not see content from users they blocked, were blocked by or muted,
+	// and posts by private accounts are only shown to their followers
+	posts.Get("/", optionalJWT, postHandler.GetAllPosts)
+	posts.Get("/:id", optionalJWT, postHandler.GetPost)
+	posts.Get("/:post_id/comments", optionalJWT, commentHandler.GetComments)
+	posts.Get("/:post_id/likes", optionalJWT, likeHandler.GetLikesByPost)
+	posts.Get("/:post_id/comments/:comment_id/likes", optionalJWT, likeHandler.GetLikesByComment)
 	
 	// Protected routes
 	protected := posts.Group("", middleware.JWTMiddleware(config.Get()))
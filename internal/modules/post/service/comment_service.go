@@ -7,37 +7,72 @@ import (
 	"github.com/topboyasante/pitstop/internal/modules/post/domain"
 	"github.com/topboyasante/pitstop/internal/modules/post/dto"
 	"github.com/topboyasante/pitstop/internal/modules/post/repository"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
 )
 
+// ErrBlocked is returned when the commenter and the author of the post or parent comment have blocked each other
+var ErrBlocked = userService.ErrBlocked
+
 // CommentService handles comment business logic
 type CommentService struct {
-	commentRepo *repository.CommentRepository
-	postRepo    *repository.PostRepository
+	commentRepo  *repository.CommentRepository
+	blockService *userService.BlockService
+	commentables map[string]ContentResolver
 }
 
 // NewCommentService creates a new comment service instance
-func NewCommentService(commentRepo *repository.CommentRepository, postRepo *repository.PostRepository) *CommentService {
-	return &CommentService{
-		commentRepo: commentRepo,
-		postRepo:    postRepo,
+func NewCommentService(commentRepo *repository.CommentRepository, postRepo *repository.PostRepository, blockService *userService.BlockService, followService *userService.FollowService) *CommentService {
+	s := &CommentService{
+		commentRepo:  commentRepo,
+		blockService: blockService,
+		commentables: make(map[string]ContentResolver),
+	}
+	s.RegisterCommentable(domain.CommentableTypePost, resolvePost(postRepo, followService))
+	return s
+}
+
+// RegisterCommentable lets a module open its content to comments. Questions and answers are
+// registered by the question module.
+func (s *CommentService) RegisterCommentable(commentableType string, resolve ContentResolver) {
+	s.commentables[commentableType] = resolve
+}
+
+// resolveCommentable returns the author of the content being commented on
+func (s *CommentService) resolveCommentable(commentableType, commentableID, containerID, viewerID string) (string, error) {
+	resolve, ok := s.commentables[commentableType]
+	if !ok {
+		return "", fmt.Errorf("unsupported commentable type: %s", commentableType)
 	}
+	return resolve(commentableID, containerID, viewerID)
 }
 
 // CreateComment creates a new comment on a post
 func (s *CommentService) CreateComment(postID, userID string, req *dto.CreateCommentRequest) (*dto.CommentResponse, error) {
-	logger.Info("Creating comment", "postID", postID, "userID", userID)
+	return s.CreateCommentOn(domain.CommentableTypePost, postID, "", userID, req)
+}
+
+// CreateCommentOn creates a new comment on a post, question or answer. containerID is the
+// question an answer belongs to and is empty for posts and questions.
+func (s *CommentService) CreateCommentOn(commentableType, commentableID, containerID, userID string, req *dto.CreateCommentRequest) (*dto.CommentResponse, error) {
+	logger.Info("Creating comment", "commentableType", commentableType, "commentableID", commentableID, "userID", userID)
+
+	// Verify the content exists and the user may see it
+	authorID, err := s.resolveCommentable(commentableType, commentableID, containerID, userID)
+	if err != nil {
+		logger.Error("Commentable not found", "commentableType", commentableType, "commentableID", commentableID, "error", err)
+		return nil, err
+	}
 
-	// Verify post exists
-	if _, err := s.postRepo.GetByID(postID); err != nil {
-		logger.Error("Post not found", "postID", postID, "error", err)
-		return nil, fmt.Errorf("post not found")
+	if err := s.blockService.CheckInteraction(userID, authorID); err != nil {
+		return nil, err
 	}
 
 	comment := &domain.Comment{
-		PostID:   postID,
-		UserID:   userID,
-		Content:  req.Content,
-		ParentID: nil,
+		CommentableID:   commentableID,
+		CommentableType: commentableType,
+		UserID:          userID,
+		Content:         req.Content,
+		ParentID:        nil,
 	}
 
 	if err := s.commentRepo.Create(comment); err != nil {
@@ -55,33 +90,49 @@ func (s *CommentService) CreateComment(postID, userID string, req *dto.CreateCom
 	return s.mapCommentToResponse(createdComment), nil
 }
 
-// CreateReply creates a reply to an existing comment
+// CreateReply creates a reply to an existing comment on a post
 func (s *CommentService) CreateReply(postID, parentCommentID, userID string, req *dto.CreateCommentRequest) (*dto.CommentResponse, error) {
-	logger.Info("Creating reply", "postID", postID, "parentCommentID", parentCommentID, "userID", userID)
+	return s.CreateReplyOn(domain.CommentableTypePost, postID, "", parentCommentID, userID, req)
+}
+
+// CreateReplyOn creates a reply to an existing comment on a post, question or answer.
+// containerID is the question an answer belongs to and is empty for posts and questions.
+func (s *CommentService) CreateReplyOn(commentableType, commentableID, containerID, parentCommentID, userID string, req *dto.CreateCommentRequest) (*dto.CommentResponse, error) {
+	logger.Info("Creating reply", "commentableType", commentableType, "commentableID", commentableID, "parentCommentID", parentCommentID, "userID", userID)
 
-	// Verify post exists
-	if _, err := s.postRepo.GetByID(postID); err != nil {
-		logger.Error("Post not found", "postID", postID, "error", err)
-		return nil, fmt.Errorf("post not found")
+	// Verify the content exists and the user may see it
+	authorID, err := s.resolveCommentable(commentableType, commentableID, containerID, userID)
+	if err != nil {
+		logger.Error("Commentable not found", "commentableType", commentableType, "commentableID", commentableID, "error", err)
+		return nil, err
 	}
 
-	// Verify parent comment exists and belongs to the post
+	// Verify parent comment exists and belongs to the same content
 	parentComment, err := s.commentRepo.GetByID(parentCommentID)
 	if err != nil {
 		logger.Error("Parent comment not found", "parentCommentID", parentCommentID, "error", err)
 		return nil, fmt.Errorf("parent comment not found")
 	}
 
-	if parentComment.PostID != postID {
-		logger.Error("Parent comment doesn't belong to post", "parentCommentID", parentCommentID, "postID", postID)
-		return nil, fmt.Errorf("parent comment doesn't belong to this post")
+	if parentComment.CommentableType != commentableType || parentComment.CommentableID != commentableID {
+		logger.Error("Parent comment doesn't belong to commentable", "parentCommentID", parentCommentID, "commentableType", commentableType, "commentableID", commentableID)
+		return nil, fmt.Errorf("parent comment doesn't belong to this %s", commentableType)
+	}
+
+	// A reply reaches both the content author and the author of the comment being replied to
+	if err := s.blockService.CheckInteraction(userID, authorID); err != nil {
+		return nil, err
+	}
+	if err := s.blockService.CheckInteraction(userID, parentComment.UserID); err != nil {
+		return nil, err
 	}
 
 	comment := &domain.Comment{
-		PostID:   postID,
-		UserID:   userID,
-		Content:  req.Content,
-		ParentID: &parentCommentID,
+		CommentableID:   commentableID,
+		CommentableType: commentableType,
+		UserID:          userID,
+		Content:         req.Content,
+		ParentID:        &parentCommentID,
 	}
 
 	if err := s.commentRepo.Create(comment); err != nil {
@@ -99,11 +150,21 @@ func (s *CommentService) CreateReply(postID, parentCommentID, userID string, req
 	return s.mapCommentToResponse(createdComment), nil
 }
 
-// GetCommentsByPostID retrieves all comments for a post
-func (s *CommentService) GetCommentsByPostID(postID string) ([]dto.CommentResponse, error) {
-	logger.Info("Getting comments for post", "postID", postID)
+// GetCommentsByPostID retrieves the comments on a post that the viewer may see
+func (s *CommentService) GetCommentsByPostID(postID, viewerID string) ([]dto.CommentResponse, error) {
+	return s.GetCommentsOn(domain.CommentableTypePost, postID, "", viewerID)
+}
+
+// GetCommentsOn retrieves the comments on a post, question or answer that the viewer may see.
+// containerID is the question an answer belongs to and is empty for posts and questions.
+func (s *CommentService) GetCommentsOn(commentableType, commentableID, containerID, viewerID string) ([]dto.CommentResponse, error) {
+	logger.Info("Getting comments", "commentableType", commentableType, "commentableID", commentableID)
+
+	if _, err := s.resolveCommentable(commentableType, commentableID, containerID, viewerID); err != nil {
+		return nil, err
+	}
 
-	comments, err := s.commentRepo.GetByPostID(postID)
+	comments, err := s.commentRepo.GetByCommentable(commentableType, commentableID, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve comments", "error", err)
 		return nil, fmt.Errorf("failed to retrieve comments: %w", err)
@@ -120,13 +181,18 @@ func (s *CommentService) GetCommentsByPostID(postID string) ([]dto.CommentRespon
 // mapCommentToResponse converts domain comment to DTO response
 func (s *CommentService) mapCommentToResponse(comment *domain.Comment) *dto.CommentResponse {
 	response := &dto.CommentResponse{
-		ID:        comment.ID,
-		PostID:    comment.PostID,
-		UserID:    comment.UserID,
-		Content:   comment.Content,
-		LikeCount: comment.LikeCount,
-		CreatedAt: comment.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: comment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:              comment.ID,
+		CommentableID:   comment.CommentableID,
+		CommentableType: comment.CommentableType,
+		UserID:          comment.UserID,
+		Content:         comment.Content,
+		LikeCount:       comment.LikeCount,
+		CreatedAt:       comment.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       comment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+
+	if comment.CommentableType == domain.CommentableTypePost {
+		response.PostID = comment.CommentableID
 	}
 
 	if comment.ParentID != nil {
@@ -1,96 +1,92 @@
 package service
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/modules/post/domain"
 	"github.com/topboyasante/pitstop/internal/modules/post/dto"
 	"github.com/topboyasante/pitstop/internal/modules/post/repository"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
-	"gorm.io/gorm"
 )
 
 // LikeService handles like business logic
 type LikeService struct {
 	likeRepo *repository.LikeRepository
-	postRepo *repository.PostRepository
+	likables map[string]ContentResolver
 	eventBus *events.EventBus
 }
 
 // NewLikeService creates a new like service instance
-func NewLikeService(likeRepo *repository.LikeRepository, postRepo *repository.PostRepository, eventBus *events.EventBus) *LikeService {
-	return &LikeService{
+func NewLikeService(likeRepo *repository.LikeRepository, postRepo *repository.PostRepository, followService *userService.FollowService, eventBus *events.EventBus) *LikeService {
+	s := &LikeService{
 		likeRepo: likeRepo,
-		postRepo: postRepo,
+		likables: make(map[string]ContentResolver),
 		eventBus: eventBus,
 	}
+	s.RegisterLikable(domain.LikableTypePost, resolvePost(postRepo, followService))
+	return s
 }
 
-// TogglePostLike toggles a like for a post (like/unlike)
-func (s *LikeService) TogglePostLike(postID, userID string) (*dto.LikeToggleResponse, error) {
-	// Check if post exists
-	_, err := s.postRepo.GetByID(postID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("post not found")
-		}
-		return nil, fmt.Errorf("failed to check post: %w", err)
-	}
+// RegisterLikable lets a module open its content to likes. Questions and answers are
+// registered by the question module.
+func (s *LikeService) RegisterLikable(likableType string, resolve ContentResolver) {
+	s.likables[likableType] = resolve
+}
 
-	// Toggle the like
-	liked, err := s.likeRepo.ToggleLike(postID, domain.LikableTypePost, userID)
-	if err != nil {
-		logger.Error("Failed to toggle post like", "post_id", postID, "user_id", userID, "error", err)
-		return nil, fmt.Errorf("failed to toggle like: %w", err)
+// resolveLikable checks that the content being liked exists and the viewer may see it
+func (s *LikeService) resolveLikable(likableType, likableID, containerID, viewerID string) error {
+	resolve, ok := s.likables[likableType]
+	if !ok {
+		return fmt.Errorf("unsupported likable type: %s", likableType)
 	}
+	_, err := resolve(likableID, containerID, viewerID)
+	return err
+}
 
-	// Get updated like count
-	likeCount, err := s.likeRepo.CountLikesByLikable(postID, domain.LikableTypePost)
-	if err != nil {
-		logger.Error("Failed to get post like count", "post_id", postID, "error", err)
-		return nil, fmt.Errorf("failed to get like count: %w", err)
-	}
+// TogglePostLike toggles a like for a post (like/unlike)
+func (s *LikeService) TogglePostLike(postID, userID string) (*dto.LikeToggleResponse, error) {
+	return s.ToggleLike(domain.LikableTypePost, postID, "", userID)
+}
 
-	// Log the action
-	action := "liked"
-	if !liked {
-		action = "unliked"
+// ToggleLike toggles a like for a post, question or answer (like/unlike). containerID is the
+// question an answer belongs to and is empty for posts and questions.
+func (s *LikeService) ToggleLike(likableType, likableID, containerID, userID string) (*dto.LikeToggleResponse, error) {
+	// Check the content exists and the user may see it
+	if err := s.resolveLikable(likableType, likableID, containerID, userID); err != nil {
+		return nil, err
 	}
-	logger.Info(fmt.Sprintf("Post %s", action), "post_id", postID, "user_id", userID)
 
-	return &dto.LikeToggleResponse{
-		Liked:     liked,
-		LikeCount: likeCount,
-	}, nil
+	return s.toggle(likableType, likableID, userID)
 }
 
 // ToggleCommentLike toggles a like for a comment (like/unlike)
 func (s *LikeService) ToggleCommentLike(postID, commentID, userID string) (*dto.LikeToggleResponse, error) {
-	// Check if post exists
-	_, err := s.postRepo.GetByID(postID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("post not found")
-		}
-		return nil, fmt.Errorf("failed to check post: %w", err)
+	// Check if post exists and the user may see it
+	if err := s.resolveLikable(domain.LikableTypePost, postID, "", userID); err != nil {
+		return nil, err
 	}
 
 	// TODO: Add comment repository to validate comment exists and belongs to post
 	// For now we'll trust the comment ID is valid
 
+	return s.toggle(domain.LikableTypeComment, commentID, userID)
+}
+
+// toggle flips the user's like on a likable entity and returns the updated like count
+func (s *LikeService) toggle(likableType, likableID, userID string) (*dto.LikeToggleResponse, error) {
 	// Toggle the like
-	liked, err := s.likeRepo.ToggleLike(commentID, domain.LikableTypeComment, userID)
+	liked, err := s.likeRepo.ToggleLike(likableID, likableType, userID)
 	if err != nil {
-		logger.Error("Failed to toggle comment like", "comment_id", commentID, "user_id", userID, "error", err)
+		logger.Error("Failed to toggle like", "likable_type", likableType, "likable_id", likableID, "user_id", userID, "error", err)
 		return nil, fmt.Errorf("failed to toggle like: %w", err)
 	}
 
 	// Get updated like count
-	likeCount, err := s.likeRepo.CountLikesByLikable(commentID, domain.LikableTypeComment)
+	likeCount, err := s.likeRepo.CountLikesByLikable(likableID, likableType)
 	if err != nil {
-		logger.Error("Failed to get comment like count", "comment_id", commentID, "error", err)
+		logger.Error("Failed to get like count", "likable_type", likableType, "likable_id", likableID, "error", err)
 		return nil, fmt.Errorf("failed to get like count: %w", err)
 	}
 
@@ -99,7 +95,7 @@ func (s *LikeService) ToggleCommentLike(postID, commentID, userID string) (*dto.
 	if !liked {
 		action = "unliked"
 	}
-	logger.Info(fmt.Sprintf("Comment %s", action), "comment_id", commentID, "user_id", userID)
+	logger.Info(fmt.Sprintf("%s %s", likableType, action), "likable_id", likableID, "user_id", userID)
 
 	return &dto.LikeToggleResponse{
 		Liked:     liked,
@@ -107,75 +103,63 @@ func (s *LikeService) ToggleCommentLike(postID, commentID, userID string) (*dto.
 	}, nil
 }
 
-// GetLikesByPost retrieves all likes for a post
-func (s *LikeService) GetLikesByPost(postID string) (*dto.LikesResponse, error) {
-	// Check if post exists
-	_, err := s.postRepo.GetByID(postID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("post not found")
-		}
-		return nil, fmt.Errorf("failed to check post: %w", err)
-	}
+// GetLikesByPost retrieves all likes for a post the viewer may see
+func (s *LikeService) GetLikesByPost(postID, viewerID string) (*dto.LikesResponse, error) {
+	return s.GetLikes(domain.LikableTypePost, postID, "", viewerID)
+}
 
-	// Get likes
-	likes, err := s.likeRepo.GetLikesByLikable(postID, domain.LikableTypePost)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve likes: %w", err)
+// GetLikes retrieves all likes for a post, question or answer the viewer may see. containerID is
+// the question an answer belongs to and is empty for posts and questions.
+func (s *LikeService) GetLikes(likableType, likableID, containerID, viewerID string) (*dto.LikesResponse, error) {
+	if err := s.resolveLikable(likableType, likableID, containerID, viewerID); err != nil {
+		return nil, err
 	}
 
-	// Convert to response DTOs
-	likeResponses := make([]dto.LikeResponse, len(likes))
-	for i, like := range likes {
-		likeResponses[i] = dto.LikeResponse{
-			ID:        like.ID,
-			PostID:    like.LikableID, // For backward compatibility in DTO
-			UserID:    like.UserID,
-			CreatedAt: like.CreatedAt,
-		}
+	return s.listLikes(likableType, likableID)
+}
 
-		if like.User != nil {
-			likeResponses[i].User = &dto.LikeUserResponse{
-				Username:    like.User.Username,
-				DisplayName: like.User.DisplayName,
-				AvatarURL:   like.User.AvatarURL,
-			}
-		}
+// GetLikesByComment retrieves all likes for a comment on a post the viewer may see
+func (s *LikeService) GetLikesByComment(postID, commentID, viewerID string) (*dto.LikesResponse, error) {
+	// Check if post exists and the viewer may see it
+	if err := s.resolveLikable(domain.LikableTypePost, postID, "", viewerID); err != nil {
+		return nil, err
 	}
 
-	return &dto.LikesResponse{
-		Likes:      likeResponses,
-		TotalCount: int64(len(likeResponses)),
-	}, nil
-}
+	// TODO: Add comment validation
 
-// GetLikesByComment retrieves all likes for a comment
-func (s *LikeService) GetLikesByComment(postID, commentID string) (*dto.LikesResponse, error) {
-	// Check if post exists
-	_, err := s.postRepo.GetByID(postID)
+	likes, err := s.listLikes(domain.LikableTypeComment, commentID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("post not found")
-		}
-		return nil, fmt.Errorf("failed to check post: %w", err)
+		return nil, err
 	}
 
-	// TODO: Add comment validation
+	// Include post ID for context
+	for i := range likes.Likes {
+		likes.Likes[i].PostID = postID
+	}
+
+	return likes, nil
+}
 
-	// Get likes
-	likes, err := s.likeRepo.GetLikesByLikable(commentID, domain.LikableTypeComment)
+// listLikes returns the likes on a likable entity with the users who left them
+func (s *LikeService) listLikes(likableType, likableID string) (*dto.LikesResponse, error) {
+	likes, err := s.likeRepo.GetLikesByLikable(likableID, likableType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve comment likes: %w", err)
+		return nil, fmt.Errorf("failed to retrieve likes: %w", err)
 	}
 
 	// Convert to response DTOs
 	likeResponses := make([]dto.LikeResponse, len(likes))
 	for i, like := range likes {
 		likeResponses[i] = dto.LikeResponse{
-			ID:        like.ID,
-			PostID:    postID, // Include post ID for context
-			UserID:    like.UserID,
-			CreatedAt: like.CreatedAt,
+			ID:          like.ID,
+			LikableID:   like.LikableID,
+			LikableType: like.LikableType,
+			UserID:      like.UserID,
+			CreatedAt:   like.CreatedAt,
+		}
+
+		if like.LikableType == domain.LikableTypePost {
+			likeResponses[i].PostID = like.LikableID // For backward compatibility in DTO
 		}
 
 		if like.User != nil {
@@ -195,16 +179,17 @@ func (s *LikeService) GetLikesByComment(postID, commentID string) (*dto.LikesRes
 
 // CheckUserLikedPost checks if a user has liked a specific post
 func (s *LikeService) CheckUserLikedPost(postID, userID string) (bool, error) {
-	exists, err := s.likeRepo.Exists(postID, domain.LikableTypePost, userID)
-	if err != nil {
-		return false, fmt.Errorf("failed to check user like status: %w", err)
-	}
-	return exists, nil
+	return s.CheckUserLiked(domain.LikableTypePost, postID, userID)
 }
 
 // CheckUserLikedComment checks if a user has liked a specific comment
 func (s *LikeService) CheckUserLikedComment(commentID, userID string) (bool, error) {
-	exists, err := s.likeRepo.Exists(commentID, domain.LikableTypeComment, userID)
+	return s.CheckUserLiked(domain.LikableTypeComment, commentID, userID)
+}
+
+// CheckUserLiked checks if a user has liked a specific likable entity
+func (s *LikeService) CheckUserLiked(likableType, likableID, userID string) (bool, error) {
+	exists, err := s.likeRepo.Exists(likableID, likableType, userID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user like status: %w", err)
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
@@ -8,22 +9,33 @@ import (
 	"github.com/topboyasante/pitstop/internal/modules/post/domain"
 	"github.com/topboyasante/pitstop/internal/modules/post/dto"
 	"github.com/topboyasante/pitstop/internal/modules/post/repository"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	viewDomain "github.com/topboyasante/pitstop/internal/modules/view/domain"
+	viewService "github.com/topboyasante/pitstop/internal/modules/view/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
+	"gorm.io/gorm"
 )
 
+// ErrInvalidSort is returned when posts are requested in an unsupported sort order
+var ErrInvalidSort = errors.New("sort must be one of newest or hot")
+
 // PostService handles post business logic
 type PostService struct {
-	postRepo  *repository.PostRepository
-	validator *validator.Validate
-	eventBus  *events.EventBus
+	postRepo      *repository.PostRepository
+	followService *userService.FollowService
+	viewService   *viewService.ViewService
+	validator     *validator.Validate
+	eventBus      *events.EventBus
 }
 
 // NewPostService creates a new post service instance
-func NewPostService(postRepo *repository.PostRepository, validator *validator.Validate, eventBus *events.EventBus) *PostService {
+func NewPostService(postRepo *repository.PostRepository, followService *userService.FollowService, viewService *viewService.ViewService, validator *validator.Validate, eventBus *events.EventBus) *PostService {
 	return &PostService{
-		postRepo:  postRepo,
-		validator: validator,
-		eventBus:  eventBus,
+		postRepo:      postRepo,
+		followService: followService,
+		viewService:   viewService,
+		validator:     validator,
+		eventBus:      eventBus,
 	}
 }
 
@@ -44,6 +56,7 @@ func (s *PostService) CreatePost(req dto.CreatePostRequest) (*dto.PostResponse,
 	}
 
 	logger.Info("Post created successfully", "post_id", post.ID)
+	s.eventBus.Publish("PostCreated", events.NewPostCreated(post.ID, post.UserID))
 
 	return &dto.PostResponse{
 		ID:        post.ID,
@@ -54,19 +67,25 @@ func (s *PostService) CreatePost(req dto.CreatePostRequest) (*dto.PostResponse,
 	}, nil
 }
 
-// GetPostByID retrieves a post by ID
-func (s *PostService) GetPostByID(id string) (*dto.PostResponse, error) {
+// GetPostByID retrieves a post by ID. A post by a private account is reported as not found
+// unless the viewer follows the account.
+func (s *PostService) GetPostByID(id, viewerID string) (*dto.PostResponse, error) {
 	post, err := s.postRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
+	if err := checkPostVisible(s.followService, post, viewerID); err != nil {
+		return nil, err
+	}
+
 	response := &dto.PostResponse{
 		ID:           post.ID,
 		UserID:       post.UserID,
 		Content:      post.Content,
 		CommentCount: post.CommentCount,
 		LikeCount:    post.LikeCount,
+		ViewCount:    post.ViewCount,
 		CreatedAt:    post.CreatedAt,
 		UpdatedAt:    post.UpdatedAt,
 	}
@@ -82,16 +101,28 @@ func (s *PostService) GetPostByID(id string) (*dto.PostResponse, error) {
 	return response, nil
 }
 
-// GetAllPosts retrieves all posts with pagination
-func (s *PostService) GetAllPosts(page, limit int) (*dto.PostsResponse, error) {
+// RecordView counts a view of a post by the viewer, once per viewer within the view window
+func (s *PostService) RecordView(id, viewerID, ip, userAgent string) {
+	s.viewService.RecordView(viewDomain.ViewableTypePost, id, viewerID, ip, userAgent)
+}
+
+// GetAllPosts retrieves the posts the viewer may see with pagination. Posts are sorted newest
+// first unless sort asks for the hot ones first.
+func (s *PostService) GetAllPosts(page, limit int, sort, viewerID string) (*dto.PostsResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
+	if sort == "" {
+		sort = repository.PostSortNewest
+	}
+	if !repository.ValidPostSort(sort) {
+		return nil, ErrInvalidSort
+	}
 
-	posts, totalCount, err := s.postRepo.GetAll(page, limit)
+	posts, totalCount, err := s.postRepo.GetAll(page, limit, sort, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve posts: %w", err)
 	}
@@ -104,6 +135,7 @@ func (s *PostService) GetAllPosts(page, limit int) (*dto.PostsResponse, error) {
 			Content:      post.Content,
 			CommentCount: post.CommentCount,
 			LikeCount:    post.LikeCount,
+			ViewCount:    post.ViewCount,
 			CreatedAt:    post.CreatedAt,
 			UpdatedAt:    post.UpdatedAt,
 		}
@@ -127,3 +159,40 @@ func (s *PostService) GetAllPosts(page, limit int) (*dto.PostsResponse, error) {
 		HasNext:    hasNext,
 	}, nil
 }
+
+// checkPostVisible reports a post by a private account the viewer does not follow as not found,
+// so the post's existence is not revealed
+func checkPostVisible(followService *userService.FollowService, post *domain.Post, viewerID string) error {
+	visible, err := followService.CanViewContent(viewerID, post.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to check post visibility: %w", err)
+	}
+	if !visible {
+		return fmt.Errorf("post not found")
+	}
+	return nil
+}
+
+// ContentResolver looks up the author of a post, question or answer that people comment on or
+// like. containerID is the content the route nests it under, such as the question of an answer,
+// and is empty for top-level content. It returns a "<type> not found" error when the content does
+// not exist, does not belong to the container or the viewer may not see it.
+type ContentResolver func(id, containerID, viewerID string) (authorID string, err error)
+
+// resolvePost is the ContentResolver for posts. Posts by private accounts are only resolved
+// for their followers.
+func resolvePost(postRepo *repository.PostRepository, followService *userService.FollowService) ContentResolver {
+	return func(postID, _, viewerID string) (string, error) {
+		post, err := postRepo.GetByID(postID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", fmt.Errorf("post not found")
+			}
+			return "", fmt.Errorf("failed to check post: %w", err)
+		}
+		if err := checkPostVisible(followService, post, viewerID); err != nil {
+			return "", err
+		}
+		return post.UserID, nil
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+)
+
+// Bounty is reputation a question author offers for a good answer. The amount is taken from the
+// author's reputation when the bounty is placed and goes to the awarded answer's author. A
+// question has at most one open bounty at a time.
+type Bounty struct {
+	ID              string     `gorm:"primarykey" json:"id"`
+	QuestionID      string     `gorm:"not null;index;uniqueIndex:idx_bounty_open_question,where:status = 'open'" json:"question_id"`
+	UserID          string     `gorm:"not null;index" json:"user_id"`
+	Amount          int        `gorm:"not null" json:"amount"`
+	Status          string     `gorm:"size:20;not null;index:idx_bounty_status_expires,priority:1" json:"status"`
+	AwardedAnswerID *string    `json:"awarded_answer_id,omitempty"`
+	ExpiresAt       time.Time  `gorm:"not null;index:idx_bounty_status_expires,priority:2" json:"expires_at"`
+	SettledAt       *time.Time `json:"settled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Bounty model
+func (Bounty) TableName() string {
+	return "bounties"
+}
+
+// Bounty statuses. An open bounty is awarded by the question author or, once it expires, to the
+// highest-scored answer. A bounty that expires without an answer to award is not refunded.
+const (
+	BountyStatusOpen    = "open"
+	BountyStatusAwarded = "awarded"
+	BountyStatusExpired = "expired"
+)
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+)
+
+// Question lifecycle states. Only open questions take new answers. Closed and duplicate
+// questions are reopened by reopen votes; locked questions only by a moderator.
+const (
+	QuestionStatusOpen      = "open"
+	QuestionStatusClosed    = "closed"
+	QuestionStatusDuplicate = "duplicate"
+	QuestionStatusLocked    = "locked"
+)
+
+// Reasons a question can be voted closed. A duplicate vote has CloseReasonDuplicate and names
+// the canonical question.
+const (
+	CloseReasonOffTopic     = "off_topic"
+	CloseReasonUnclear      = "unclear"
+	CloseReasonTooBroad     = "too_broad"
+	CloseReasonOpinionBased = "opinion_based"
+	CloseReasonDuplicate    = "duplicate"
+)
+
+// Kinds of close votes
+const (
+	CloseVoteKindClose  = "close"
+	CloseVoteKindReopen = "reopen"
+)
+
+// CloseVote is a vote to close or reopen a question. A user has one vote of each kind per
+// question; the votes are cleared when the question changes state, so the next round starts over.
+type CloseVote struct {
+	ID          string    `gorm:"primarykey" json:"id"`
+	QuestionID  string    `gorm:"not null;uniqueIndex:idx_close_vote_question_user,priority:1" json:"question_id"`
+	UserID      string    `gorm:"not null;uniqueIndex:idx_close_vote_question_user,priority:3;index" json:"user_id"`
+	Kind        string    `gorm:"size:10;not null;uniqueIndex:idx_close_vote_question_user,priority:2" json:"kind"`
+	Reason      string    `gorm:"size:40" json:"reason,omitempty"`
+	CanonicalID *string   `json:"canonical_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the CloseVote model
+func (CloseVote) TableName() string {
+	return "close_votes"
+}
@@ -15,11 +15,20 @@ type Question struct {
 	Title        string                     `gorm:"type:varchar(255)" json:"title" validate:"required"`
 	Content      string                     `gorm:"type:text" json:"content" validate:"required"`
 	Tags         string                     `gorm:"type:varchar(500)" json:"tags"` // Comma-separated tags
-	IsAnswered   bool                       `gorm:"default:false" json:"is_answered"`
+	CarMake      string                     `gorm:"size:50;index" json:"car_make,omitempty"`
+	CarModel     string                     `gorm:"size:50;index" json:"car_model,omitempty"`
+	CarYear      int                        `gorm:"index" json:"car_year,omitempty"`
+	IsAnswered   bool                       `gorm:"default:false;index" json:"is_answered"`
+	Score        int                        `gorm:"not null;default:0" json:"score"` // Sum of vote values, kept in step with the votes table
+	ViewCount    int64                      `gorm:"not null;default:0" json:"view_count"`
+	Status       string                     `gorm:"size:20;not null;default:open;index" json:"status"` // Lifecycle state, changed by close votes and moderators
+	StatusReason string                     `gorm:"size:255" json:"status_reason,omitempty"`
+	CanonicalID  *string                    `gorm:"index" json:"canonical_id,omitempty"` // The question a duplicate links to
 	Comments     []postDomain.Comment       `gorm:"polymorphic:Commentable;polymorphicValue:question" json:"comments,omitempty"`
 	CommentCount int64                      `gorm:"-" json:"comment_count"`
 	LikeCount    int64                      `gorm:"-" json:"like_count"`
 	AnswerCount  int64                      `gorm:"-" json:"answer_count"`
+	Bounty       *Bounty                    `gorm:"-" json:"bounty,omitempty"` // Most recent bounty, if any
 	CreatedAt    time.Time                  `json:"created_at"`
 	UpdatedAt    time.Time                  `json:"updated_at"`
 }
@@ -31,17 +40,19 @@ func (Question) TableName() string {
 
 // Answer represents an answer to a question
 type Answer struct {
-	ID         string               `gorm:"primarykey" json:"id"`
-	QuestionID string               `gorm:"not null" json:"question_id" validate:"required"`
-	Question   *Question            `gorm:"foreignKey:QuestionID;references:ID" json:"question,omitempty"`
-	UserID     string               `gorm:"not null" json:"user_id" validate:"required"`
-	User       *userDomain.User     `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
-	Content    string               `gorm:"type:text" json:"content" validate:"required"`
-	IsAccepted bool                 `gorm:"default:false" json:"is_accepted"`
-	Comments   []postDomain.Comment `gorm:"polymorphic:Commentable;polymorphicValue:answer" json:"comments,omitempty"`
-	LikeCount  int64                `gorm:"-" json:"like_count"`
-	CreatedAt  time.Time            `json:"created_at"`
-	UpdatedAt  time.Time            `json:"updated_at"`
+	ID           string               `gorm:"primarykey" json:"id"`
+	QuestionID   string               `gorm:"not null;uniqueIndex:idx_answer_accepted_question,where:is_accepted = true" json:"question_id" validate:"required"` // At most one accepted answer per question
+	Question     *Question            `gorm:"foreignKey:QuestionID;references:ID" json:"question,omitempty"`
+	UserID       string               `gorm:"not null" json:"user_id" validate:"required"`
+	User         *userDomain.User     `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	Content      string               `gorm:"type:text" json:"content" validate:"required"`
+	IsAccepted   bool                 `gorm:"default:false" json:"is_accepted"`
+	Score        int                  `gorm:"not null;default:0" json:"score"` // Sum of vote values, kept in step with the votes table
+	Comments     []postDomain.Comment `gorm:"polymorphic:Commentable;polymorphicValue:answer" json:"comments,omitempty"`
+	CommentCount int64                `gorm:"-" json:"comment_count"`
+	LikeCount    int64                `gorm:"-" json:"like_count"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
 }
 
 // TableName specifies the table name for the Answer model
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+)
+
+// Vote is an up or down vote on a question or answer. A user has at most one vote per item:
+// changing a vote updates Value and retracting it deletes the row. The Score of the question
+// or answer is updated in the same transaction.
+type Vote struct {
+	ID          string    `gorm:"primarykey" json:"id"`
+	UserID      string    `gorm:"not null;uniqueIndex:idx_vote_user_votable" json:"user_id" validate:"required"`
+	VotableID   string    `gorm:"not null;uniqueIndex:idx_vote_user_votable;index:idx_votable" json:"votable_id" validate:"required"`
+	VotableType string    `gorm:"size:20;not null;uniqueIndex:idx_vote_user_votable;index:idx_votable" json:"votable_type" validate:"required,oneof=question answer"`
+	Value       int       `gorm:"not null" json:"value" validate:"required,oneof=1 -1"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Vote model
+func (Vote) TableName() string {
+	return "votes"
+}
+
+// Votable type constants
+const (
+	VotableTypeQuestion = "question"
+	VotableTypeAnswer   = "answer"
+)
+
+// Vote values
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
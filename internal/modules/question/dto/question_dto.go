@@ -13,17 +13,42 @@ type QuestionUserResponse struct {
 
 // CreateQuestionRequest represents a request to create a new question
 type CreateQuestionRequest struct {
-	UserID  string `json:"user_id" validate:"required"`
-	Title   string `json:"title" validate:"required,min=5,max=255"`
-	Content string `json:"content" validate:"required,min=10"`
-	Tags    string `json:"tags,omitempty"` // Comma-separated tags
+	UserID   string `json:"user_id" validate:"required"`
+	Title    string `json:"title" validate:"required,min=5,max=255"`
+	Content  string `json:"content" validate:"required,min=10"`
+	Tags     string `json:"tags,omitempty"` // Comma-separated tags
+	CarMake  string `json:"car_make,omitempty" validate:"omitempty,max=50"`
+	CarModel string `json:"car_model,omitempty" validate:"omitempty,max=50"`
+	CarYear  int    `json:"car_year,omitempty" validate:"omitempty,min=1886,max=2100"`
 }
 
 // UpdateQuestionRequest represents a request to update a question
 type UpdateQuestionRequest struct {
-	Title   string `json:"title,omitempty" validate:"omitempty,min=5,max=255"`
-	Content string `json:"content,omitempty" validate:"omitempty,min=10"`
-	Tags    string `json:"tags,omitempty"`
+	Title    string `json:"title,omitempty" validate:"omitempty,min=5,max=255"`
+	Content  string `json:"content,omitempty" validate:"omitempty,min=10"`
+	Tags     string `json:"tags,omitempty"`
+	CarMake  string `json:"car_make,omitempty" validate:"omitempty,max=50"`
+	CarModel string `json:"car_model,omitempty" validate:"omitempty,max=50"`
+	CarYear  int    `json:"car_year,omitempty" validate:"omitempty,min=1886,max=2100"`
+}
+
+// ListQuestionsQuery represents the filters and sort order for listing questions. Empty fields
+// leave a filter out.
+type ListQuestionsQuery struct {
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+	Answered  *bool  `query:"answered"`                                     // Whether the question has an accepted answer
+	NoAnswers bool   `query:"no_answers"`                                   // Only questions nobody answered yet
+	Tags      string `query:"tags"`                                         // Comma-separated tags
+	TagMatch  string `query:"tag_match" validate:"omitempty,oneof=any all"` // Whether questions need any or all of the tags, any by default
+	CarMake   string `query:"car_make"`
+	CarModel  string `query:"car_model"`
+	CarYear   int    `query:"car_year" validate:"omitempty,min=1886,max=2100"`
+	Author    string `query:"author"`                                                          // Author's user ID
+	Since     string `query:"since"`                                                           // RFC 3339 timestamp or YYYY-MM-DD date
+	Until     string `query:"until"`                                                           // RFC 3339 timestamp or YYYY-MM-DD date, inclusive
+	Bountied  bool   `query:"bountied"`                                                        // Only questions with an open bounty
+	Sort      string `query:"sort" validate:"omitempty,oneof=newest activity votes views hot"` // newest by default
 }
 
 // QuestionResponse represents a question in API responses
@@ -33,11 +58,20 @@ type QuestionResponse struct {
 	Title        string                `json:"title"`
 	Content      string                `json:"content"`
 	Tags         []string              `json:"tags"` // Will be split from comma-separated string
+	CarMake      string                `json:"car_make,omitempty"`
+	CarModel     string                `json:"car_model,omitempty"`
+	CarYear      int                   `json:"car_year,omitempty"`
 	IsAnswered   bool                  `json:"is_answered"`
+	Score        int                   `json:"score"`
+	Status       string                `json:"status"` // open, closed, duplicate or locked
+	StatusReason string                `json:"status_reason,omitempty"`
+	CanonicalID  *string               `json:"canonical_id,omitempty"` // Set on duplicates
 	User         *QuestionUserResponse `json:"user"`
 	CommentCount int64                 `json:"comment_count"`
 	LikeCount    int64                 `json:"like_count"`
 	AnswerCount  int64                 `json:"answer_count"`
+	ViewCount    int64                 `json:"view_count"`
+	Bounty       *BountyResponse       `json:"bounty,omitempty"` // Most recent bounty, if any
 	CreatedAt    time.Time             `json:"created_at"`
 	UpdatedAt    time.Time             `json:"updated_at"`
 }
@@ -67,17 +101,30 @@ type AcceptAnswerRequest struct {
 	IsAccepted bool `json:"is_accepted"`
 }
 
+// VoteRequest represents a request to vote on a question or answer
+type VoteRequest struct {
+	Value int `json:"value" validate:"required,oneof=1 -1"`
+}
+
+// VoteResponse represents the result of voting on a question or answer
+type VoteResponse struct {
+	Score    int `json:"score"`
+	UserVote int `json:"user_vote"` // 1, -1, or 0 after a retraction
+}
+
 // AnswerResponse represents an answer in API responses
 type AnswerResponse struct {
-	ID         string                `json:"id"`
-	QuestionID string                `json:"question_id"`
-	UserID     string                `json:"user_id"`
-	Content    string                `json:"content"`
-	IsAccepted bool                  `json:"is_accepted"`
-	User       *QuestionUserResponse `json:"user"`
-	LikeCount  int64                 `json:"like_count"`
-	CreatedAt  time.Time             `json:"created_at"`
-	UpdatedAt  time.Time             `json:"updated_at"`
+	ID           string                `json:"id"`
+	QuestionID   string                `json:"question_id"`
+	UserID       string                `json:"user_id"`
+	Content      string                `json:"content"`
+	IsAccepted   bool                  `json:"is_accepted"`
+	Score        int                   `json:"score"`
+	User         *QuestionUserResponse `json:"user"`
+	LikeCount    int64                 `json:"like_count"`
+	CommentCount int64                 `json:"comment_count"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
 }
 
 // AnswersResponse represents a paginated list of answers
@@ -87,4 +134,51 @@ type AnswersResponse struct {
 	Page       int              `json:"page"`
 	Limit      int              `json:"limit"`
 	HasNext    bool             `json:"has_next"`
-}
\ No newline at end of file
+}
+// PlaceBountyRequest represents a request to place a bounty on a question
+type PlaceBountyRequest struct {
+	Amount int `json:"amount" validate:"required,min=1"`
+}
+
+// AwardBountyRequest represents a request to award a bounty to an answer
+type AwardBountyRequest struct {
+	AnswerID string `json:"answer_id" validate:"required"`
+}
+
+// BountyResponse represents a bounty in API responses
+type BountyResponse struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	Amount          int        `json:"amount"`
+	Status          string     `json:"status"` // open, awarded or expired
+	AwardedAnswerID *string    `json:"awarded_answer_id,omitempty"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	SettledAt       *time.Time `json:"settled_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// CloseVoteRequest represents a vote to close a question
+type CloseVoteRequest struct {
+	Reason string `json:"reason" validate:"required,oneof=off_topic unclear too_broad opinion_based"`
+}
+
+// DuplicateVoteRequest represents a vote to close a question as a duplicate of another
+type DuplicateVoteRequest struct {
+	CanonicalID string `json:"canonical_id" validate:"required"`
+}
+
+// LockQuestionRequest represents a moderator locking a question
+type LockQuestionRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// QuestionStatusResponse represents a question's lifecycle state and its pending votes
+type QuestionStatusResponse struct {
+	QuestionID    string  `json:"question_id"`
+	Status        string  `json:"status"`
+	StatusReason  string  `json:"status_reason,omitempty"`
+	CanonicalID   *string `json:"canonical_id,omitempty"`
+	CloseVotes    int64   `json:"close_votes"`
+	ReopenVotes   int64   `json:"reopen_votes"`
+	VotesRequired int     `json:"votes_required"`
+}
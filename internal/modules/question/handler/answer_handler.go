@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/topboyasante/pitstop/internal/core/response"
 	"github.com/topboyasante/pitstop/internal/modules/question/dto"
 	"github.com/topboyasante/pitstop/internal/modules/question/service"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
 	utils "github.com/topboyasante/pitstop/internal/shared/utils"
 )
 
@@ -26,7 +28,7 @@ func NewAnswerHandler(answerService *service.AnswerService) *AnswerHandler {
 
 // CreateAnswer creates a new answer for a question
 // @Summary Create an answer
-// @Description Create an answer for a specific question
+// @Description Create an answer for a specific question. Only open questions take new answers.
 // @Tags answers
 // @Accept json
 // @Produce json
@@ -35,6 +37,7 @@ func NewAnswerHandler(answerService *service.AnswerService) *AnswerHandler {
 // @Success 201 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{question_id}/answers [post]
 func (h *AnswerHandler) CreateAnswer(c *fiber.Ctx) error {
@@ -63,6 +66,12 @@ func (h *AnswerHandler) CreateAnswer(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question")
 		}
+		if errors.Is(err, service.ErrBlocked) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot answer this question", "")
+		}
+		if errors.Is(err, service.ErrQuestionClosed) {
+			return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_CLOSED", "This question is closed to new answers", "")
+		}
 		return response.ValidationErrorJSON(c, "Failed to create answer", err.Error())
 	}
 
@@ -72,14 +81,16 @@ func (h *AnswerHandler) CreateAnswer(c *fiber.Ctx) error {
 
 // GetAnswersByQuestionID retrieves all answers for a question
 // @Summary Get answers for a question
-// @Description Retrieve all answers for a specific question
+// @Description Retrieve all answers for a specific question. When signed in, answers by users you blocked, who blocked you or whom you muted are left out.
 // @Tags answers
 // @Accept json
 // @Produce json
 // @Param question_id path string true "Question ID"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Answers per page" default(20)
+// @Param sort query string false "Sort order: score, newest or oldest. The accepted answer is always first" default(score)
 // @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Router /questions/{question_id}/answers [get]
 func (h *AnswerHandler) GetAnswersByQuestionID(c *fiber.Ctx) error {
@@ -91,9 +102,16 @@ func (h *AnswerHandler) GetAnswersByQuestionID(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	answers, err := h.answerService.GetAnswersByQuestionID(questionID, page, limit)
+	sort := c.Query("sort")
+
+	viewerID, _ := c.Locals("userID").(string)
+
+	answers, err := h.answerService.GetAnswersByQuestionID(questionID, page, limit, sort, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve answers", "question_id", questionID, "error", err)
+		if errors.Is(err, service.ErrInvalidSort) {
+			return response.ValidationErrorJSON(c, "Invalid sort", err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question")
 		}
@@ -123,7 +141,8 @@ func (h *AnswerHandler) GetAnswer(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid answer ID", "Answer ID cannot be empty")
 	}
 
-	answer, err := h.answerService.GetAnswerByID(answerID)
+	viewerID, _ := c.Locals("userID").(string)
+	answer, err := h.answerService.GetAnswerByID(answerID, viewerID)
 	if err != nil {
 		return response.NotFoundJSON(c, "Answer")
 	}
@@ -133,7 +152,7 @@ func (h *AnswerHandler) GetAnswer(c *fiber.Ctx) error {
 
 // UpdateAnswer updates an answer
 // @Summary Update an answer
-// @Description Update an answer (only by author)
+// @Description Update an answer (only by author or a moderator)
 // @Tags answers
 // @Accept json
 // @Produce json
@@ -142,6 +161,7 @@ func (h *AnswerHandler) GetAnswer(c *fiber.Ctx) error {
 // @Param request body dto.UpdateAnswerRequest true "Updated answer details"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{question_id}/answers/{answer_id} [put]
@@ -156,21 +176,21 @@ func (h *AnswerHandler) UpdateAnswer(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
 	}
 
-	// Extract user ID from JWT claims
-	userID, err := utils.ExtractUserIDFromContext(c)
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
 	if err != nil {
 		logger.Error("Failed to extract user ID from context", "error", err)
 		return response.UnauthorizedJSON(c)
 	}
 
-	answer, err := h.answerService.UpdateAnswer(answerID, req, userID)
+	answer, err := h.answerService.UpdateAnswer(answerID, req, actor)
 	if err != nil {
 		logger.Error("Failed to update answer", "answer_id", answerID, "error", err)
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Answer")
 		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			return response.UnauthorizedJSON(c)
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
 		}
 		return response.ValidationErrorJSON(c, "Failed to update answer", err.Error())
 	}
@@ -180,13 +200,15 @@ func (h *AnswerHandler) UpdateAnswer(c *fiber.Ctx) error {
 
 // DeleteAnswer deletes an answer
 // @Summary Delete an answer
-// @Description Delete an answer (only by author)
+// @Description Delete an answer (only by author or a moderator). Moderators can pass spam=true to remove it as spam, which penalizes the author's reputation.
 // @Tags answers
 // @Accept json
 // @Produce json
 // @Param question_id path string true "Question ID"
 // @Param answer_id path string true "Answer ID"
+// @Param spam query bool false "Remove as spam (moderators only)"
 // @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{question_id}/answers/{answer_id} [delete]
@@ -196,20 +218,20 @@ func (h *AnswerHandler) DeleteAnswer(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid answer ID", "Answer ID cannot be empty")
 	}
 
-	// Extract user ID from JWT claims
-	userID, err := utils.ExtractUserIDFromContext(c)
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
 	if err != nil {
 		logger.Error("Failed to extract user ID from context", "error", err)
 		return response.UnauthorizedJSON(c)
 	}
 
-	if err := h.answerService.DeleteAnswer(answerID, userID); err != nil {
+	if err := h.answerService.DeleteAnswer(answerID, actor, c.QueryBool("spam")); err != nil {
 		logger.Error("Failed to delete answer", "answer_id", answerID, "error", err)
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Answer")
 		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			return response.UnauthorizedJSON(c)
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
 		}
 		return response.InternalErrorJSON(c, "Failed to delete answer")
 	}
@@ -227,6 +249,7 @@ func (h *AnswerHandler) DeleteAnswer(c *fiber.Ctx) error {
 // @Param answer_id path string true "Answer ID"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{question_id}/answers/{answer_id}/accept [post]
@@ -254,8 +277,8 @@ func (h *AnswerHandler) AcceptAnswer(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question or Answer")
 		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			return response.UnauthorizedJSON(c)
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
 		}
 		return response.ValidationErrorJSON(c, "Failed to accept answer", err.Error())
 	}
@@ -273,6 +296,7 @@ func (h *AnswerHandler) AcceptAnswer(c *fiber.Ctx) error {
 // @Param answer_id path string true "Answer ID"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{question_id}/answers/{answer_id}/unaccept [post]
@@ -300,8 +324,8 @@ func (h *AnswerHandler) UnacceptAnswer(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question or Answer")
 		}
-		if strings.Contains(err.Error(), "unauthorized") {
-			return response.UnauthorizedJSON(c)
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
 		}
 		return response.ValidationErrorJSON(c, "Failed to unaccept answer", err.Error())
 	}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/service"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// BountyHandler handles HTTP requests for bounties on questions
+type BountyHandler struct {
+	bountyService *service.BountyService
+}
+
+// NewBountyHandler creates a new bounty handler instance
+func NewBountyHandler(bountyService *service.BountyService) *BountyHandler {
+	return &BountyHandler{
+		bountyService: bountyService,
+	}
+}
+
+// PlaceBounty places a bounty on a question
+// @Summary Place a bounty on a question
+// @Description Offer reputation for answers to your question. The amount is taken from your reputation right away. When the bounty expires without being awarded, it goes to the highest-scored answer, or is lost when no answer has a positive score.
+// @Tags bounties
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.PlaceBountyRequest true "Bounty amount"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/bounty [post]
+func (h *BountyHandler) PlaceBounty(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.PlaceBountyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	bounty, err := h.bountyService.PlaceBounty(questionID, userID, req)
+	if err != nil {
+		return bountyErrorJSON(c, err, "Failed to place bounty")
+	}
+
+	return response.CreatedJSON(c, bounty, "Bounty placed successfully")
+}
+
+// AwardBounty awards the open bounty on a question to an answer
+// @Summary Award a bounty
+// @Description Pay the open bounty on your question out to an answer (only by the question author)
+// @Tags bounties
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.AwardBountyRequest true "Answer to award"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/bounty/award [post]
+func (h *BountyHandler) AwardBounty(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.AwardBountyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	bounty, err := h.bountyService.AwardBounty(questionID, userID, req)
+	if err != nil {
+		return bountyErrorJSON(c, err, "Failed to award bounty")
+	}
+
+	return response.SuccessJSON(c, bounty, "Bounty awarded successfully")
+}
+
+// bountyErrorJSON maps bounty service errors to responses
+func bountyErrorJSON(c *fiber.Ctx, err error, failureMessage string) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "question not found"):
+		return response.NotFoundJSON(c, "Question")
+	case strings.HasPrefix(err.Error(), "answer not found"), err.Error() == "answer does not belong to this question":
+		return response.NotFoundJSON(c, "Answer")
+	case strings.HasPrefix(err.Error(), "validation failed"):
+		return response.ValidationErrorJSON(c, "Invalid bounty", err.Error())
+	case errors.Is(err, policy.ErrForbidden):
+		return response.ForbiddenJSON(c, err.Error())
+	case errors.Is(err, service.ErrSelfAward):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "SELF_AWARD", err.Error(), "")
+	case errors.Is(err, service.ErrInsufficientReputation):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "INSUFFICIENT_REPUTATION", "Your reputation is lower than the bounty amount", "")
+	case errors.Is(err, service.ErrBountyExists):
+		return response.ErrorJSON(c, fiber.StatusConflict, "BOUNTY_EXISTS", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionClosed):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_CLOSED", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionAnswered):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_ANSWERED", err.Error(), "")
+	case errors.Is(err, service.ErrNoOpenBounty):
+		return response.ErrorJSON(c, fiber.StatusConflict, "NO_OPEN_BOUNTY", err.Error(), "")
+	}
+	logger.Error(failureMessage, "error", err)
+	return response.InternalErrorJSON(c, failureMessage)
+}
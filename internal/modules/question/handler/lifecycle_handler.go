@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/service"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+)
+
+// LifecycleHandler handles HTTP requests for closing, reopening and locking questions
+type LifecycleHandler struct {
+	lifecycleService *service.LifecycleService
+}
+
+// NewLifecycleHandler creates a new lifecycle handler instance
+func NewLifecycleHandler(lifecycleService *service.LifecycleService) *LifecycleHandler {
+	return &LifecycleHandler{
+		lifecycleService: lifecycleService,
+	}
+}
+
+// GetStatus returns a question's lifecycle state
+// @Summary Get question status
+// @Description Get a question's lifecycle state and its pending close and reopen votes
+// @Tags questions
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /questions/{id}/status [get]
+func (h *LifecycleHandler) GetStatus(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	status, err := h.lifecycleService.GetStatus(questionID)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to retrieve question status")
+	}
+
+	return response.SuccessJSON(c, status, "Question status retrieved successfully")
+}
+
+// VoteToClose casts a vote to close a question
+// @Summary Vote to close a question
+// @Description Vote to close an open question for a reason. The question closes once enough users voted; a moderator's vote closes it right away. Requires the close_vote privilege.
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.CloseVoteRequest true "Close reason"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/close [post]
+func (h *LifecycleHandler) VoteToClose(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.CloseVoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.lifecycleService.VoteToClose(questionID, actor, req)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to vote to close question")
+	}
+
+	return response.SuccessJSON(c, status, "Close vote recorded successfully")
+}
+
+// VoteDuplicate casts a vote to close a question as a duplicate
+// @Summary Vote to mark a question as a duplicate
+// @Description Vote to close an open question as a duplicate of another question. Once closed, the question links to the canonical one. Requires the close_vote privilege.
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.DuplicateVoteRequest true "Canonical question"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/duplicate [post]
+func (h *LifecycleHandler) VoteDuplicate(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.DuplicateVoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.lifecycleService.VoteDuplicate(questionID, actor, req)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to vote to mark question as duplicate")
+	}
+
+	return response.SuccessJSON(c, status, "Duplicate vote recorded successfully")
+}
+
+// VoteToReopen casts a vote to reopen a question
+// @Summary Vote to reopen a question
+// @Description Vote to reopen a closed or duplicate question. Requires the close_vote privilege.
+// @Tags questions
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/reopen [post]
+func (h *LifecycleHandler) VoteToReopen(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.lifecycleService.VoteToReopen(questionID, actor)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to vote to reopen question")
+	}
+
+	return response.SuccessJSON(c, status, "Reopen vote recorded successfully")
+}
+
+// LockQuestion locks a question
+// @Summary Lock a question
+// @Description Lock a question so it takes no answers or close votes (moderators only)
+// @Tags questions
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.LockQuestionRequest true "Lock reason"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/lock [post]
+func (h *LifecycleHandler) LockQuestion(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.LockQuestionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.lifecycleService.LockQuestion(questionID, actor, req)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to lock question")
+	}
+
+	return response.SuccessJSON(c, status, "Question locked successfully")
+}
+
+// UnlockQuestion unlocks a question
+// @Summary Unlock a question
+// @Description Reopen a locked question (moderators only)
+// @Tags questions
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/unlock [post]
+func (h *LifecycleHandler) UnlockQuestion(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	status, err := h.lifecycleService.UnlockQuestion(questionID, actor)
+	if err != nil {
+		return lifecycleErrorJSON(c, err, "Failed to unlock question")
+	}
+
+	return response.SuccessJSON(c, status, "Question unlocked successfully")
+}
+
+// lifecycleErrorJSON maps lifecycle service errors to responses
+func lifecycleErrorJSON(c *fiber.Ctx, err error, failureMessage string) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "question not found"):
+		return response.NotFoundJSON(c, "Question")
+	case strings.HasPrefix(err.Error(), "validation failed"):
+		return response.ValidationErrorJSON(c, "Invalid request", err.Error())
+	case errors.Is(err, service.ErrInvalidDuplicate):
+		return response.ValidationErrorJSON(c, "Invalid duplicate", err.Error())
+	case errors.Is(err, service.ErrNoClosePrivilege):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "INSUFFICIENT_REPUTATION", err.Error(), "")
+	case errors.Is(err, policy.ErrForbidden):
+		return response.ForbiddenJSON(c, err.Error())
+	case errors.Is(err, service.ErrAlreadyVoted):
+		return response.ErrorJSON(c, fiber.StatusConflict, "ALREADY_VOTED", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionLocked):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_LOCKED", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionClosed):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_CLOSED", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionNotClosed):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_NOT_CLOSED", err.Error(), "")
+	case errors.Is(err, service.ErrQuestionNotLocked):
+		return response.ErrorJSON(c, fiber.StatusConflict, "QUESTION_NOT_LOCKED", err.Error(), "")
+	}
+	logger.Error(failureMessage, "error", err)
+	return response.InternalErrorJSON(c, failureMessage)
+}
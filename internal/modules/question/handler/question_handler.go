@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/topboyasante/pitstop/internal/core/response"
 	"github.com/topboyasante/pitstop/internal/modules/question/dto"
 	"github.com/topboyasante/pitstop/internal/modules/question/service"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
 	utils "github.com/topboyasante/pitstop/internal/shared/utils"
 )
 
@@ -24,22 +26,42 @@ func NewQuestionHandler(questionService *service.QuestionService) *QuestionHandl
 	}
 }
 
-// GetAllQuestions retrieves all questions
+// GetAllQuestions retrieves questions
 // @Summary Get all questions
-// @Description Retrieve a paginated list of questions
+// @Description Retrieve a paginated list of questions matching the given filters. When signed in, questions by users you blocked, who blocked you or whom you muted are left out.
 // @Tags questions
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Questions per page" default(20)
+// @Param answered query bool false "Only questions with (true) or without (false) an accepted answer"
+// @Param no_answers query bool false "Only questions without answers"
+// @Param tags query string false "Comma-separated tags"
+// @Param tag_match query string false "Whether questions need any or all of the tags" Enums(any, all) default(any)
+// @Param car_make query string false "Car make"
+// @Param car_model query string false "Car model"
+// @Param car_year query int false "Car year"
+// @Param author query string false "Author's user ID"
+// @Param since query string false "Asked at or after this RFC 3339 timestamp or YYYY-MM-DD date"
+// @Param until query string false "Asked at or before this RFC 3339 timestamp or YYYY-MM-DD date"
+// @Param bountied query bool false "Only questions with an open bounty"
+// @Param sort query string false "Sort order" Enums(newest, activity, votes, views, hot) default(newest)
 // @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
 // @Router /questions [get]
 func (h *QuestionHandler) GetAllQuestions(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	var query dto.ListQuestionsQuery
+	if err := c.QueryParser(&query); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid query parameters", err.Error())
+	}
+
+	viewerID, _ := c.Locals("userID").(string)
 
-	questions, err := h.questionService.GetAllQuestions(page, limit)
+	questions, err := h.questionService.GetAllQuestions(query, viewerID)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "validation failed") {
+			return response.ValidationErrorJSON(c, "Invalid query parameters", err.Error())
+		}
 		logger.Error("Failed to retrieve questions", "error", err)
 		return response.InternalErrorJSON(c, "Failed to retrieve questions")
 	}
@@ -88,7 +110,7 @@ func (h *QuestionHandler) CreateQuestion(c *fiber.Ctx) error {
 
 // GetQuestion retrieves a specific question by ID
 // @Summary Get a question by ID
-// @Description Retrieve a specific question
+// @Description Retrieve a specific question and count the view
 // @Tags questions
 // @Accept json
 // @Produce json
@@ -102,17 +124,20 @@ func (h *QuestionHandler) GetQuestion(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid question ID", "ID cannot be empty")
 	}
 
-	question, err := h.questionService.GetQuestionByID(id)
+	viewerID, _ := c.Locals("userID").(string)
+	question, err := h.questionService.GetQuestionByID(id, viewerID)
 	if err != nil {
 		return response.NotFoundJSON(c, "Question")
 	}
 
+	h.questionService.RecordView(id, viewerID, c.IP(), c.Get(fiber.HeaderUserAgent))
+
 	return response.SuccessJSON(c, question, "Question retrieved successfully")
 }
 
 // UpdateQuestion updates a question
 // @Summary Update a question
-// @Description Update a question (only by author)
+// @Description Update a question (only by author or a moderator). Users with the edit_tags privilege may change only the tags of other users' questions.
 // @Tags questions
 // @Accept json
 // @Produce json
@@ -120,6 +145,7 @@ func (h *QuestionHandler) GetQuestion(c *fiber.Ctx) error {
 // @Param request body dto.UpdateQuestionRequest true "Updated question details"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{id} [put]
@@ -134,12 +160,22 @@ func (h *QuestionHandler) UpdateQuestion(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
 	}
 
-	question, err := h.questionService.UpdateQuestion(id, req)
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	question, err := h.questionService.UpdateQuestion(id, req, actor)
 	if err != nil {
 		logger.Error("Failed to update question", "question_id", id, "error", err)
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question")
 		}
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
+		}
 		return response.ValidationErrorJSON(c, "Failed to update question", err.Error())
 	}
 
@@ -148,13 +184,16 @@ func (h *QuestionHandler) UpdateQuestion(c *fiber.Ctx) error {
 
 // DeleteQuestion deletes a question
 // @Summary Delete a question
-// @Description Delete a question (only by author)
+// @Description Delete a question (only by author or a moderator). A question with an open bounty cannot be deleted until the bounty is settled. Moderators can pass spam=true to remove it as spam, which penalizes the author's reputation.
 // @Tags questions
 // @Accept json
 // @Produce json
 // @Param id path string true "Question ID"
+// @Param spam query bool false "Remove as spam (moderators only)"
 // @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
 // @Security BearerAuth
 // @Router /questions/{id} [delete]
 func (h *QuestionHandler) DeleteQuestion(c *fiber.Ctx) error {
@@ -163,11 +202,24 @@ func (h *QuestionHandler) DeleteQuestion(c *fiber.Ctx) error {
 		return response.ValidationErrorJSON(c, "Invalid question ID", "ID cannot be empty")
 	}
 
-	if err := h.questionService.DeleteQuestion(id); err != nil {
+	// Extract the acting user from JWT claims
+	actor, err := policy.ActorFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	if err := h.questionService.DeleteQuestion(id, actor, c.QueryBool("spam")); err != nil {
 		logger.Error("Failed to delete question", "question_id", id, "error", err)
 		if strings.Contains(err.Error(), "not found") {
 			return response.NotFoundJSON(c, "Question")
 		}
+		if errors.Is(err, policy.ErrForbidden) {
+			return response.ForbiddenJSON(c, err.Error())
+		}
+		if errors.Is(err, service.ErrOpenBountyDelete) {
+			return response.ErrorJSON(c, fiber.StatusConflict, "BOUNTY_OPEN", err.Error(), "")
+		}
 		return response.InternalErrorJSON(c, "Failed to delete question")
 	}
 
@@ -194,7 +246,9 @@ func (h *QuestionHandler) GetQuestionsByTag(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	questions, err := h.questionService.GetQuestionsByTag(tag, page, limit)
+	viewerID, _ := c.Locals("userID").(string)
+
+	questions, err := h.questionService.GetQuestionsByTag(tag, page, limit, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve questions by tag", "tag", tag, "error", err)
 		return response.InternalErrorJSON(c, "Failed to retrieve questions by tag")
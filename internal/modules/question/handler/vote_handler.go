@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// VoteHandler handles HTTP requests for votes on questions and answers
+type VoteHandler struct {
+	voteService *service.VoteService
+}
+
+// NewVoteHandler creates a new vote handler instance
+func NewVoteHandler(voteService *service.VoteService) *VoteHandler {
+	return &VoteHandler{
+		voteService: voteService,
+	}
+}
+
+// VoteOnQuestion casts or changes a vote on a question
+// @Summary Vote on a question
+// @Description Upvote (1) or downvote (-1) a question. Voting again with the other value changes the vote
+// @Tags votes
+// @Accept json
+// @Produce json
+// @Param id path string true "Question ID"
+// @Param request body dto.VoteRequest true "Vote value"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/vote [post]
+func (h *VoteHandler) VoteOnQuestion(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	var req dto.VoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	result, err := h.voteService.VoteOnQuestion(questionID, userID, req)
+	if err != nil {
+		logger.Error("Failed to vote on question", "question_id", questionID, "user_id", userID, "error", err)
+		return voteErrorJSON(c, err)
+	}
+
+	return response.SuccessJSON(c, result, "Vote recorded successfully")
+}
+
+// RetractQuestionVote removes a vote on a question
+// @Summary Retract a vote on a question
+// @Description Remove your vote on a question
+// @Tags votes
+// @Produce json
+// @Param id path string true "Question ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{id}/vote [delete]
+func (h *VoteHandler) RetractQuestionVote(c *fiber.Ctx) error {
+	questionID := c.Params("id")
+	if strings.TrimSpace(questionID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid question ID", "Question ID cannot be empty")
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	result, err := h.voteService.RetractQuestionVote(questionID, userID)
+	if err != nil {
+		logger.Error("Failed to retract question vote", "question_id", questionID, "user_id", userID, "error", err)
+		return voteErrorJSON(c, err)
+	}
+
+	return response.SuccessJSON(c, result, "Vote retracted successfully")
+}
+
+// VoteOnAnswer casts or changes a vote on an answer
+// @Summary Vote on an answer
+// @Description Upvote (1) or downvote (-1) an answer. Voting again with the other value changes the vote
+// @Tags votes
+// @Accept json
+// @Produce json
+// @Param question_id path string true "Question ID"
+// @Param answer_id path string true "Answer ID"
+// @Param request body dto.VoteRequest true "Vote value"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{question_id}/answers/{answer_id}/vote [post]
+func (h *VoteHandler) VoteOnAnswer(c *fiber.Ctx) error {
+	questionID := c.Params("question_id")
+	answerID := c.Params("answer_id")
+	if strings.TrimSpace(answerID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid answer ID", "Answer ID cannot be empty")
+	}
+
+	var req dto.VoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	result, err := h.voteService.VoteOnAnswer(questionID, answerID, userID, req)
+	if err != nil {
+		logger.Error("Failed to vote on answer", "answer_id", answerID, "user_id", userID, "error", err)
+		return voteErrorJSON(c, err)
+	}
+
+	return response.SuccessJSON(c, result, "Vote recorded successfully")
+}
+
+// RetractAnswerVote removes a vote on an answer
+// @Summary Retract a vote on an answer
+// @Description Remove your vote on an answer
+// @Tags votes
+// @Produce json
+// @Param question_id path string true "Question ID"
+// @Param answer_id path string true "Answer ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /questions/{question_id}/answers/{answer_id}/vote [delete]
+func (h *VoteHandler) RetractAnswerVote(c *fiber.Ctx) error {
+	questionID := c.Params("question_id")
+	answerID := c.Params("answer_id")
+	if strings.TrimSpace(answerID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid answer ID", "Answer ID cannot be empty")
+	}
+
+	// Extract user ID from JWT claims
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		logger.Error("Failed to extract user ID from context", "error", err)
+		return response.UnauthorizedJSON(c)
+	}
+
+	result, err := h.voteService.RetractAnswerVote(questionID, answerID, userID)
+	if err != nil {
+		logger.Error("Failed to retract answer vote", "answer_id", answerID, "user_id", userID, "error", err)
+		return voteErrorJSON(c, err)
+	}
+
+	return response.SuccessJSON(c, result, "Vote retracted successfully")
+}
+
+// voteErrorJSON maps vote service errors to responses
+func voteErrorJSON(c *fiber.Ctx, err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "question not found"):
+		return response.NotFoundJSON(c, "Question")
+	case strings.HasPrefix(err.Error(), "answer not found"), err.Error() == "answer does not belong to this question":
+		return response.NotFoundJSON(c, "Answer")
+	case strings.HasPrefix(err.Error(), "validation failed"):
+		return response.ValidationErrorJSON(c, "Vote value must be 1 or -1", err.Error())
+	case errors.Is(err, service.ErrSelfVote):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "SELF_VOTE", err.Error(), "")
+	case errors.Is(err, service.ErrBlocked):
+		return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot vote on this content", "")
+	}
+	return response.InternalErrorJSON(c, "Failed to record vote")
+}
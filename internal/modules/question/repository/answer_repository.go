@@ -4,7 +4,9 @@ import (
 	"github.com/google/uuid"
 	postDomain "github.com/topboyasante/pitstop/internal/modules/post/domain"
 	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	userRepository "github.com/topboyasante/pitstop/internal/modules/user/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AnswerRepository handles answer data operations
@@ -34,118 +36,224 @@ func (r *AnswerRepository) GetByID(id string) (*domain.Answer, error) {
 		return nil, err
 	}
 
+	// Calculate comment count
+	var commentCount int64
+	r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", id, postDomain.CommentableTypeAnswer).Count(&commentCount)
+	answer.CommentCount = commentCount
+
 	// Calculate like count
 	var likeCount int64
-	r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", id, "answer").Count(&likeCount)
+	r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", id, postDomain.LikableTypeAnswer).Count(&likeCount)
 	answer.LikeCount = likeCount
 
 	return &answer, nil
 }
 
-// GetByQuestionID retrieves all answers for a question with pagination
-func (r *AnswerRepository) GetByQuestionID(questionID string, page, limit int) ([]domain.Answer, int64, error) {
+// Answer sort orders. The accepted answer is listed first whatever the order.
+const (
+	AnswerSortScore  = "score"
+	AnswerSortNewest = "newest"
+	AnswerSortOldest = "oldest"
+)
+
+var answerSortOrders = map[string]string{
+	AnswerSortScore:  "is_accepted DESC, score DESC, created_at ASC",
+	AnswerSortNewest: "is_accepted DESC, created_at DESC",
+	AnswerSortOldest: "is_accepted DESC, created_at ASC",
+}
+
+// ValidAnswerSort reports whether sort is a supported answer sort order
+func ValidAnswerSort(sort string) bool {
+	_, ok := answerSortOrders[sort]
+	return ok
+}
+
+// GetByQuestionID retrieves all answers for a question with pagination, in the given sort order.
+// Answers by users the viewer blocked, was blocked by or muted are left out.
+func (r *AnswerRepository) GetByQuestionID(questionID string, page, limit int, sort, viewerID string) ([]domain.Answer, int64, error) {
 	var answers []domain.Answer
 	var totalCount int64
 
 	offset := (page - 1) * limit
 
 	// Get total count
-	if err := r.db.Model(&domain.Answer{}).Where("question_id = ?", questionID).Count(&totalCount).Error; err != nil {
+	if err := r.db.Model(&domain.Answer{}).Where("question_id = ?", questionID).Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID), userRepository.ExcludePrivateUsers("user_id", viewerID)).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Get answers - accepted answers first, then ordered by creation date
+	order, ok := answerSortOrders[sort]
+	if !ok {
+		order = answerSortOrders[AnswerSortScore]
+	}
+
+	// Get answers - accepted answers first, then in the requested order
 	if err := r.db.Preload("User").
 		Where("question_id = ?", questionID).
+		Scopes(userRepository.ExcludeHiddenUsers("user_id", viewerID), userRepository.ExcludePrivateUsers("user_id", viewerID)).
 		Offset(offset).
 		Limit(limit).
-		Order("is_accepted DESC, created_at ASC").
+		Order(order).
 		Find(&answers).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Calculate like counts
+	// Calculate comment and like counts
 	for i := range answers {
+		var commentCount int64
+		r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", answers[i].ID, postDomain.CommentableTypeAnswer).Count(&commentCount)
+		answers[i].CommentCount = commentCount
+
 		var likeCount int64
-		r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", answers[i].ID, "answer").Count(&likeCount)
+		r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", answers[i].ID, postDomain.LikableTypeAnswer).Count(&likeCount)
 		answers[i].LikeCount = likeCount
 	}
 
 	return answers, totalCount, nil
 }
 
-// AcceptAnswer marks an answer as accepted and unmarks other accepted answers for the same question
-func (r *AnswerRepository) AcceptAnswer(answerID, questionID string) error {
-	// Start transaction
-	tx := r.db.Begin()
-
-	// First, unmark all other accepted answers for this question
-	if err := tx.Model(&domain.Answer{}).
-		Where("question_id = ? AND id != ?", questionID, answerID).
-		Update("is_accepted", false).Error; err != nil {
-		tx.Rollback()
-		return err
+// GetTopScored returns the highest-scored answer to a question with a positive score, leaving
+// out answers by excludeUserID, or nil when there is none. Ties go to the earliest answer.
+func (r *AnswerRepository) GetTopScored(questionID, excludeUserID string) (*domain.Answer, error) {
+	var answers []domain.Answer
+	if err := r.db.Where("question_id = ? AND score > 0 AND user_id <> ?", questionID, excludeUserID).
+		Order("score DESC, created_at ASC").
+		Limit(1).
+		Find(&answers).Error; err != nil {
+		return nil, err
 	}
-
-	// Mark the specified answer as accepted
-	if err := tx.Model(&domain.Answer{}).
-		Where("id = ?", answerID).
-		Update("is_accepted", true).Error; err != nil {
-		tx.Rollback()
-		return err
+	if len(answers) == 0 {
+		return nil, nil
 	}
+	return &answers[0], nil
+}
 
-	// Update question's is_answered status
-	if err := tx.Model(&domain.Question{}).
-		Where("id = ?", questionID).
-		Update("is_answered", true).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+// CountAcceptedByUser counts a user's answers that were accepted
+func (r *AnswerRepository) CountAcceptedByUser(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Answer{}).Where("user_id = ? AND is_accepted = ?", userID, true).Count(&count).Error
+	return count, err
+}
+
+// AcceptAnswer marks an answer as accepted in place of the question's previously accepted
+// answer and marks the question answered, in one transaction. The question row is locked so
+// accepts on the same question run one after another, and the partial unique index on accepted
+// answers refuses a second accepted answer should they not. It returns the previously accepted
+// answer, or nil; when that is the answer itself nothing is changed.
+func (r *AnswerRepository) AcceptAnswer(answerID, questionID string) (*domain.Answer, error) {
+	var previous *domain.Answer
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockQuestion(tx, questionID); err != nil {
+			return err
+		}
 
-	return tx.Commit().Error
+		var accepted []domain.Answer
+		if err := tx.Where("question_id = ? AND is_accepted = ?", questionID, true).Limit(1).Find(&accepted).Error; err != nil {
+			return err
+		}
+		if len(accepted) > 0 {
+			previous = &accepted[0]
+			if previous.ID == answerID {
+				return nil
+			}
+			if err := tx.Model(&domain.Answer{}).
+				Where("id = ?", previous.ID).
+				Update("is_accepted", false).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&domain.Answer{}).
+			Where("id = ? AND question_id = ?", answerID, questionID).
+			Update("is_accepted", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&domain.Question{}).
+			Where("id = ?", questionID).
+			Update("is_answered", true).Error
+	})
+	return previous, err
 }
 
-// UnacceptAnswer marks an answer as not accepted
-func (r *AnswerRepository) UnacceptAnswer(answerID, questionID string) error {
-	// Start transaction
-	tx := r.db.Begin()
-
-	// Unmark the answer as accepted
-	if err := tx.Model(&domain.Answer{}).
-		Where("id = ?", answerID).
-		Update("is_accepted", false).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+// UnacceptAnswer marks an accepted answer as not accepted and the question as unanswered, in one
+// transaction. It reports false when the answer was not accepted, in which case nothing changed.
+func (r *AnswerRepository) UnacceptAnswer(answerID, questionID string) (bool, error) {
+	unaccepted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockQuestion(tx, questionID); err != nil {
+			return err
+		}
 
-	// Check if there are any other accepted answers for this question
-	var acceptedCount int64
-	if err := tx.Model(&domain.Answer{}).
-		Where("question_id = ? AND is_accepted = ?", questionID, true).
-		Count(&acceptedCount).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		result := tx.Model(&domain.Answer{}).
+			Where("id = ? AND question_id = ? AND is_accepted = ?", answerID, questionID, true).
+			Update("is_accepted", false)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		unaccepted = true
 
-	// Update question's is_answered status based on remaining accepted answers
-	isAnswered := acceptedCount > 0
-	if err := tx.Model(&domain.Question{}).
-		Where("id = ?", questionID).
-		Update("is_answered", isAnswered).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
+		// A question has at most one accepted answer, so it is unanswered now
+		return tx.Model(&domain.Question{}).
+			Where("id = ?", questionID).
+			Update("is_answered", false).Error
+	})
+	return unaccepted, err
+}
 
-	return tx.Commit().Error
+// lockQuestion locks a question's row for the rest of the transaction, so changes to which of
+// its answers is accepted are made one at a time
+func lockQuestion(tx *gorm.DB, questionID string) error {
+	var question domain.Question
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", questionID).
+		First(&question).Error
 }
 
-// Update updates an answer
+// Update updates an answer. The score is left alone, it only changes through votes.
 func (r *AnswerRepository) Update(answer *domain.Answer) error {
-	return r.db.Save(answer).Error
+	return r.db.Omit("score", "is_accepted").Save(answer).Error
 }
 
-// Delete soft deletes an answer
-func (r *AnswerRepository) Delete(id string) error {
-	return r.db.Where("id = ?", id).Delete(&domain.Answer{}).Error
-}
\ No newline at end of file
+// Delete deletes an answer together with the comments and likes on it. When the answer was
+// accepted, its question is marked unanswered in the same transaction. It reports whether the
+// deleted answer was the accepted one.
+func (r *AnswerRepository) Delete(id string) (bool, error) {
+	var wasAccepted bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var answer domain.Answer
+		if err := tx.Select("id", "question_id").Where("id = ?", id).First(&answer).Error; err != nil {
+			return err
+		}
+		if err := lockQuestion(tx, answer.QuestionID); err != nil {
+			return err
+		}
+
+		// Read the accepted flag under the question lock, so an accept running concurrently is seen
+		if err := tx.Select("is_accepted").Where("id = ?", id).First(&answer).Error; err != nil {
+			return err
+		}
+		if err := deleteInteractions(tx, postDomain.CommentableTypeAnswer, id); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&domain.Answer{}).Error; err != nil {
+			return err
+		}
+
+		if !answer.IsAccepted {
+			return nil
+		}
+		wasAccepted = true
+		return tx.Model(&domain.Question{}).
+			Where("id = ?", answer.QuestionID).
+			Update("is_answered", false).Error
+	})
+	return wasAccepted, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"gorm.io/gorm"
+)
+
+// BountyRepository handles bounty data operations
+type BountyRepository struct {
+	db *gorm.DB
+}
+
+// NewBountyRepository creates a new bounty repository instance
+func NewBountyRepository(db *gorm.DB) *BountyRepository {
+	return &BountyRepository{db: db}
+}
+
+// Create stores a bounty and funds it in one transaction. fund takes the amount from the
+// sponsor's reputation; when it fails, the bounty is not stored.
+func (r *BountyRepository) Create(bounty *domain.Bounty, fund func(tx *gorm.DB) error) error {
+	if bounty.ID == "" {
+		bounty.ID = uuid.NewString()
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(bounty).Error; err != nil {
+			return err
+		}
+		return fund(tx)
+	})
+}
+
+// GetByID retrieves a bounty by ID
+func (r *BountyRepository) GetByID(id string) (*domain.Bounty, error) {
+	var bounty domain.Bounty
+	if err := r.db.Where("id = ?", id).First(&bounty).Error; err != nil {
+		return nil, err
+	}
+	return &bounty, nil
+}
+
+// GetOpenByQuestionID returns the open bounty on a question, or nil when there is none
+func (r *BountyRepository) GetOpenByQuestionID(questionID string) (*domain.Bounty, error) {
+	var bounties []domain.Bounty
+	if err := r.db.Where("question_id = ? AND status = ?", questionID, domain.BountyStatusOpen).Limit(1).Find(&bounties).Error; err != nil {
+		return nil, err
+	}
+	if len(bounties) == 0 {
+		return nil, nil
+	}
+	return &bounties[0], nil
+}
+
+// GetDue returns up to limit open bounties that expired before cutoff, soonest expired first
+func (r *BountyRepository) GetDue(cutoff time.Time, limit int) ([]domain.Bounty, error) {
+	var bounties []domain.Bounty
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.BountyStatusOpen, cutoff).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&bounties).Error
+	return bounties, err
+}
+
+// Settle closes an open bounty as awarded to answerID, or as expired when answerID is nil. An
+// award is paid out by pay in the same transaction. It reports false, changing nothing, when the
+// bounty was no longer open, so a bounty is only ever paid out once.
+func (r *BountyRepository) Settle(bountyID string, answerID *string, pay func(tx *gorm.DB) error) (bool, error) {
+	status := domain.BountyStatusExpired
+	if answerID != nil {
+		status = domain.BountyStatusAwarded
+	}
+
+	settled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Bounty{}).
+			Where("id = ? AND status = ?", bountyID, domain.BountyStatusOpen).
+			Updates(map[string]interface{}{
+				"status":            status,
+				"awarded_answer_id": answerID,
+				"settled_at":        time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		settled = true
+		if answerID == nil {
+			return nil
+		}
+		return pay(tx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return settled, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CloseVoteRepository handles close votes and question lifecycle changes
+type CloseVoteRepository struct {
+	db *gorm.DB
+}
+
+// NewCloseVoteRepository creates a new close vote repository instance
+func NewCloseVoteRepository(db *gorm.DB) *CloseVoteRepository {
+	return &CloseVoteRepository{db: db}
+}
+
+// WithLockedQuestion runs fn in a transaction holding the question's row lock, so votes on the
+// same question are counted one after another
+func (r *CloseVoteRepository) WithLockedQuestion(questionID string, fn func(tx *gorm.DB, question *domain.Question) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var question domain.Question
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", questionID).First(&question).Error; err != nil {
+			return err
+		}
+		return fn(tx, &question)
+	})
+}
+
+// AddVote records a close or reopen vote. It reports false when the user already cast a vote of
+// that kind on the question.
+func (r *CloseVoteRepository) AddVote(tx *gorm.DB, vote *domain.CloseVote) (bool, error) {
+	if vote.ID == "" {
+		vote.ID = uuid.NewString()
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetVotes returns the votes of a kind on a question, oldest first
+func (r *CloseVoteRepository) GetVotes(tx *gorm.DB, questionID, kind string) ([]domain.CloseVote, error) {
+	var votes []domain.CloseVote
+	err := tx.Where("question_id = ? AND kind = ?", questionID, kind).Order("created_at ASC").Find(&votes).Error
+	return votes, err
+}
+
+// CountVotes returns the number of pending votes on a question by kind
+func (r *CloseVoteRepository) CountVotes(questionID string) (map[string]int64, error) {
+	var rows []struct {
+		Kind  string
+		Count int64
+	}
+	if err := r.db.Model(&domain.CloseVote{}).
+		Select("kind, COUNT(*) AS count").
+		Where("question_id = ?", questionID).
+		Group("kind").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Kind] = row.Count
+	}
+	return counts, nil
+}
+
+// SetStatus changes a question's lifecycle state and clears its pending votes
+func (r *CloseVoteRepository) SetStatus(tx *gorm.DB, questionID, status, reason string, canonicalID *string) error {
+	if err := tx.Model(&domain.Question{}).Where("id = ?", questionID).Updates(map[string]interface{}{
+		"status":        status,
+		"status_reason": reason,
+		"canonical_id":  canonicalID,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Where("question_id = ?", questionID).Delete(&domain.CloseVote{}).Error
+}
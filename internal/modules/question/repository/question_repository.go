@@ -1,10 +1,18 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	postDomain "github.com/topboyasante/pitstop/internal/modules/post/domain"
 	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	userRepository "github.com/topboyasante/pitstop/internal/modules/user/repository"
+	viewDomain "github.com/topboyasante/pitstop/internal/modules/view/domain"
+	"github.com/topboyasante/pitstop/internal/shared/suggestion"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // QuestionRepository handles question data operations
@@ -34,124 +42,361 @@ func (r *QuestionRepository) GetByID(id string) (*domain.Question, error) {
 		return nil, err
 	}
 
-	// Calculate answer count
-	var answerCount int64
-	r.db.Model(&domain.Answer{}).Where("question_id = ?", id).Count(&answerCount)
-	question.AnswerCount = answerCount
+	r.loadCounts(&question)
 
-	// Calculate comment count
-	var commentCount int64
-	r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", id, "question").Count(&commentCount)
-	question.CommentCount = commentCount
+	return &question, nil
+}
 
-	// Calculate like count
-	var likeCount int64
-	r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", id, "question").Count(&likeCount)
-	question.LikeCount = likeCount
+// latestBounty returns the most recent bounty on a question, or nil when it never had one
+func (r *QuestionRepository) latestBounty(questionID string) *domain.Bounty {
+	var bounties []domain.Bounty
+	r.db.Where("question_id = ?", questionID).Order("created_at DESC").Limit(1).Find(&bounties)
+	if len(bounties) == 0 {
+		return nil
+	}
+	return &bounties[0]
+}
 
-	// Check if question is answered (has at least one accepted answer)
-	var acceptedAnswerCount int64
-	r.db.Model(&domain.Answer{}).Where("question_id = ? AND is_accepted = ?", id, true).Count(&acceptedAnswerCount)
-	question.IsAnswered = acceptedAnswerCount > 0
+// Question sort orders
+const (
+	QuestionSortNewest   = "newest"
+	QuestionSortActivity = "activity"
+	QuestionSortVotes    = "votes"
+	QuestionSortViews    = "views"
+	QuestionSortHot      = "hot"
+)
 
-	return &question, nil
+// lastActivity is when a question was asked, last answered or last commented on
+const lastActivity = `GREATEST(questions.created_at,
+	(SELECT MAX(answers.created_at) FROM answers WHERE answers.question_id = questions.id),
+	(SELECT MAX(comments.created_at) FROM comments WHERE comments.commentable_id = questions.id AND comments.commentable_type = ?))`
+
+// questionTags splits a question's comma-separated tags into a lowercase array
+const questionTags = `regexp_split_to_array(LOWER(TRIM(questions.tags)), '\s*,\s*')`
+
+// QuestionFilter selects and orders the questions to list. Zero values leave a filter out.
+type QuestionFilter struct {
+	Answered     *bool
+	NoAnswers    bool
+	Tags         []string
+	MatchAllTags bool
+	CarMake      string
+	CarModel     string
+	CarYear      int
+	AuthorID     string
+	Since        *time.Time
+	Until        *time.Time // Exclusive
+	Bountied     bool
+	Sort         string
+	ViewerID     string // Questions by users the viewer blocked, was blocked by or muted are left out
+}
+
+// ValidQuestionSort reports whether sort is a supported question sort order
+func ValidQuestionSort(sort string) bool {
+	switch sort {
+	case QuestionSortNewest, QuestionSortActivity, QuestionSortVotes, QuestionSortViews, QuestionSortHot:
+		return true
+	}
+	return false
 }
 
-// GetAll retrieves all questions with pagination
-func (r *QuestionRepository) GetAll(page, limit int) ([]domain.Question, int64, error) {
+// List retrieves the questions matching a filter with pagination, in the filter's sort order
+func (r *QuestionRepository) List(filter QuestionFilter, page, limit int) ([]domain.Question, int64, error) {
 	var questions []domain.Question
 	var totalCount int64
 
 	offset := (page - 1) * limit
 
 	// Get total count
-	if err := r.db.Model(&domain.Question{}).Count(&totalCount).Error; err != nil {
+	if err := r.db.Model(&domain.Question{}).Scopes(r.filtered(filter)).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get questions
 	if err := r.db.Preload("User").
+		Scopes(r.filtered(filter), sortedBy(filter.Sort)).
 		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
 		Find(&questions).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Calculate counts for each question
 	for i := range questions {
-		var answerCount int64
-		r.db.Model(&domain.Answer{}).Where("question_id = ?", questions[i].ID).Count(&answerCount)
-		questions[i].AnswerCount = answerCount
+		r.loadCounts(&questions[i])
+	}
 
-		var commentCount int64
-		r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", questions[i].ID, "question").Count(&commentCount)
-		questions[i].CommentCount = commentCount
+	return questions, totalCount, nil
+}
+
+// filtered applies a filter's conditions to a question query
+func (r *QuestionRepository) filtered(filter QuestionFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(
+			userRepository.ExcludeHiddenUsers("questions.user_id", filter.ViewerID),
+			userRepository.ExcludePrivateUsers("questions.user_id", filter.ViewerID),
+			r.withOpenBounty(filter.Bountied),
+		)
 
-		var likeCount int64
-		r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", questions[i].ID, "question").Count(&likeCount)
-		questions[i].LikeCount = likeCount
+		if filter.Answered != nil {
+			db = db.Where("questions.is_answered = ?", *filter.Answered)
+		}
+		if filter.NoAnswers {
+			db = db.Where("questions.id NOT IN (?)", r.db.Model(&domain.Answer{}).Select("question_id"))
+		}
 
-		// Check if question is answered
-		var acceptedAnswerCount int64
-		r.db.Model(&domain.Answer{}).Where("question_id = ? AND is_accepted = ?", questions[i].ID, true).Count(&acceptedAnswerCount)
-		questions[i].IsAnswered = acceptedAnswerCount > 0
+		if len(filter.Tags) > 0 {
+			conditions := make([]string, len(filter.Tags))
+			args := make([]interface{}, len(filter.Tags))
+			for i, tag := range filter.Tags {
+				conditions[i] = "? = ANY(" + questionTags + ")"
+				args[i] = strings.ToLower(tag)
+			}
+			joiner := " OR "
+			if filter.MatchAllTags {
+				joiner = " AND "
+			}
+			db = db.Where("("+strings.Join(conditions, joiner)+")", args...)
+		}
+
+		if filter.CarMake != "" {
+			db = db.Where("LOWER(questions.car_make) = LOWER(?)", filter.CarMake)
+		}
+		if filter.CarModel != "" {
+			db = db.Where("LOWER(questions.car_model) = LOWER(?)", filter.CarModel)
+		}
+		if filter.CarYear != 0 {
+			db = db.Where("questions.car_year = ?", filter.CarYear)
+		}
+		if filter.AuthorID != "" {
+			db = db.Where("questions.user_id = ?", filter.AuthorID)
+		}
+		if filter.Since != nil {
+			db = db.Where("questions.created_at >= ?", *filter.Since)
+		}
+		if filter.Until != nil {
+			db = db.Where("questions.created_at < ?", *filter.Until)
+		}
+
+		return db
 	}
+}
 
-	return questions, totalCount, nil
+// sortedBy orders a question query, newest first unless sort asks otherwise. Ties go to the newest question.
+func sortedBy(sort string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch sort {
+		case QuestionSortActivity:
+			// An order expression replaces any other order columns, so the tiebreak is part of it
+			return db.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:  lastActivity + " DESC, questions.created_at DESC",
+				Vars: []interface{}{postDomain.CommentableTypeQuestion},
+			}})
+		case QuestionSortVotes:
+			return db.Order("questions.score DESC, questions.created_at DESC")
+		case QuestionSortViews:
+			return db.Order("questions.view_count DESC, questions.created_at DESC")
+		case QuestionSortHot:
+			return db.Order(viewDomain.HotRank("questions") + " DESC, questions.created_at DESC")
+		}
+		return db.Order("questions.created_at DESC")
+	}
 }
 
-// GetByTag retrieves questions filtered by tag with pagination
-func (r *QuestionRepository) GetByTag(tag string, page, limit int) ([]domain.Question, int64, error) {
-	var questions []domain.Question
-	var totalCount int64
+// loadCounts fills in a question's answer, comment and like counts and its latest bounty
+func (r *QuestionRepository) loadCounts(question *domain.Question) {
+	var answerCount int64
+	r.db.Model(&domain.Answer{}).Where("question_id = ?", question.ID).Count(&answerCount)
+	question.AnswerCount = answerCount
 
-	offset := (page - 1) * limit
+	var commentCount int64
+	r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", question.ID, postDomain.CommentableTypeQuestion).Count(&commentCount)
+	question.CommentCount = commentCount
 
-	// Get total count
-	if err := r.db.Model(&domain.Question{}).Where("tags LIKE ?", "%"+tag+"%").Count(&totalCount).Error; err != nil {
-		return nil, 0, err
+	var likeCount int64
+	r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", question.ID, postDomain.LikableTypeQuestion).Count(&likeCount)
+	question.LikeCount = likeCount
+
+	question.Bounty = r.latestBounty(question.ID)
+}
+
+// withOpenBounty limits a query to questions with an open bounty when only is set
+func (r *QuestionRepository) withOpenBounty(only bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !only {
+			return db
+		}
+		return db.Where("questions.id IN (?)", r.db.Model(&domain.Bounty{}).Select("question_id").Where("status = ?", domain.BountyStatusOpen))
 	}
+}
 
-	// Get questions
-	if err := r.db.Preload("User").
-		Where("tags LIKE ?", "%"+tag+"%").
-		Offset(offset).
-		Limit(limit).
-		Order("created_at DESC").
-		Find(&questions).Error; err != nil {
-		return nil, 0, err
+// Update updates a question. The score, view count and lifecycle state are left alone, they
+// only change through votes, view flushes and moderators.
+func (r *QuestionRepository) Update(question *domain.Question) error {
+	return r.db.Omit("score", "view_count", "is_answered", "status", "status_reason", "canonical_id").Save(question).Error
+}
+
+// AddViews adds flushed view counts, keyed by question ID, to the questions' view counts
+func (r *QuestionRepository) AddViews(counts map[string]int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for questionID, views := range counts {
+			if err := tx.Model(&domain.Question{}).
+				Where("id = ?", questionID).
+				UpdateColumn("view_count", gorm.Expr("view_count + ?", views)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete deletes a question together with its bounties, close votes and the comments and likes
+// on it. Duplicates of the question keep their state but lose the link to it.
+func (r *QuestionRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteInteractions(tx, postDomain.CommentableTypeQuestion, id); err != nil {
+			return err
+		}
+		if err := tx.Where("question_id = ?", id).Delete(&domain.Bounty{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id = ?", id).Delete(&domain.CloseVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Question{}).Where("canonical_id = ?", id).Update("canonical_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&domain.Question{}).Error
+	})
+}
+
+// deleteInteractions deletes the votes and likes on a question or answer, the comments on it
+// and the likes on those comments. Questions and answers share the type names used for votes,
+// comments and likes.
+func deleteInteractions(tx *gorm.DB, contentType, id string) error {
+	if err := tx.Where("votable_id = ? AND votable_type = ?", id, contentType).Delete(&domain.Vote{}).Error; err != nil {
+		return err
 	}
 
-	// Calculate counts for each question
-	for i := range questions {
-		var answerCount int64
-		r.db.Model(&domain.Answer{}).Where("question_id = ?", questions[i].ID).Count(&answerCount)
-		questions[i].AnswerCount = answerCount
+	commentIDs := tx.Model(&postDomain.Comment{}).Select("id").
+		Where("commentable_id = ? AND commentable_type = ?", id, contentType)
+
+	if err := tx.Where("(likable_type = ? AND likable_id = ?) OR (likable_type = ? AND likable_id IN (?))",
+		contentType, id, postDomain.LikableTypeComment, commentIDs).
+		Delete(&postDomain.Like{}).Error; err != nil {
+		return err
+	}
 
-		var commentCount int64
-		r.db.Model(&postDomain.Comment{}).Where("commentable_id = ? AND commentable_type = ?", questions[i].ID, "question").Count(&commentCount)
-		questions[i].CommentCount = commentCount
+	return tx.Where("commentable_id = ? AND commentable_type = ?", id, contentType).Delete(&postDomain.Comment{}).Error
+}
 
-		var likeCount int64
-		r.db.Model(&postDomain.Like{}).Where("likable_id = ? AND likable_type = ?", questions[i].ID, "question").Count(&likeCount)
-		questions[i].LikeCount = likeCount
+// SharedTagUsers scores the users who answered questions carrying the tags of the questions a
+// user asked or answered. The score is the number of such answers.
+func (r *QuestionRepository) SharedTagUsers(userID string, limit int) (map[string]float64, error) {
+	answered := r.db.Table("answers").Select("question_id").Where("user_id = ?", userID)
+	userTags := r.db.Table("questions, unnest(string_to_array(questions.tags, ',')) AS tag").
+		Select("DISTINCT LOWER(TRIM(tag))").
+		Where("questions.user_id = ? OR questions.id IN (?)", userID, answered)
 
-		// Check if question is answered
-		var acceptedAnswerCount int64
-		r.db.Model(&domain.Answer{}).Where("question_id = ? AND is_accepted = ?", questions[i].ID, true).Count(&acceptedAnswerCount)
-		questions[i].IsAnswered = acceptedAnswerCount > 0
+	var rows []suggestion.ScoredUser
+	err := r.db.Table("answers").
+		Select("answers.user_id, COUNT(DISTINCT answers.id) AS score").
+		Joins("JOIN questions ON questions.id = answers.question_id").
+		Joins("CROSS JOIN unnest(string_to_array(questions.tags, ',')) AS tag").
+		Where("LOWER(TRIM(tag)) IN (?) AND TRIM(tag) <> ''", userTags).
+		Where("answers.user_id <> ?", userID).
+		Group("answers.user_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
 	}
+	return suggestion.ScoresByUser(rows), nil
+}
 
-	return questions, totalCount, nil
+// ActiveUsers scores users by the questions and answers they wrote during the activity window.
+// It ranks candidates for user suggestions and does not depend on who is asking.
+func (r *QuestionRepository) ActiveUsers(userID string, limit int) (map[string]float64, error) {
+	since := time.Now().Add(-suggestion.ActivityWindow)
+	questions := r.db.Table("questions").Select("user_id").Where("created_at > ?", since)
+	answers := r.db.Table("answers").Select("user_id").Where("created_at > ?", since)
+
+	var rows []suggestion.ScoredUser
+	err := r.db.Table("(?) AS activity", r.db.Raw("? UNION ALL ?", questions, answers)).
+		Select("user_id, COUNT(*) AS score").
+		Where("user_id <> ?", userID).
+		Group("user_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestion.ScoresByUser(rows), nil
 }
 
-// Update updates a question
-func (r *QuestionRepository) Update(question *domain.Question) error {
-	return r.db.Save(question).Error
+// ExportUser returns the questions and answers a user wrote and the votes they cast, for their personal data export
+func (r *QuestionRepository) ExportUser(userID string) (map[string]interface{}, error) {
+	var questions []domain.Question
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+
+	var answers []domain.Answer
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+
+	var votes []domain.Vote
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+
+	var bounties []domain.Bounty
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&bounties).Error; err != nil {
+		return nil, err
+	}
+
+	var closeVotes []domain.CloseVote
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&closeVotes).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"questions.json":   questions,
+		"answers.json":     answers,
+		"votes.json":       votes,
+		"bounties.json":    bounties,
+		"close_votes.json": closeVotes,
+	}, nil
 }
 
-// Delete soft deletes a question
-func (r *QuestionRepository) Delete(id string) error {
-	return r.db.Where("id = ?", id).Delete(&domain.Question{}).Error
-}
\ No newline at end of file
+// PurgeUser anonymizes a user's questions, answers and bounties by reassigning them to the
+// deleted user placeholder, so answers written by others stay reachable and open bounties can
+// still be awarded when they expire. The user's votes and pending close votes are deleted,
+// while the scores they contributed to are kept. It runs inside the account deletion transaction.
+func (r *QuestionRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.Vote{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.CloseVote{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&domain.Answer{}).
+		Where("user_id = ?", userID).
+		Update("user_id", userDomain.DeletedUserID).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&domain.Bounty{}).
+		Where("user_id = ?", userID).
+		Update("user_id", userDomain.DeletedUserID).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&domain.Question{}).
+		Where("user_id = ?", userID).
+		Update("user_id", userDomain.DeletedUserID).Error
+}
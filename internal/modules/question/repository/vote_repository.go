@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteRepository handles vote data operations
+type VoteRepository struct {
+	db *gorm.DB
+}
+
+// NewVoteRepository creates a new vote repository instance
+func NewVoteRepository(db *gorm.DB) *VoteRepository {
+	return &VoteRepository{db: db}
+}
+
+// votableModel returns the model whose score a vote of the given type counts towards
+func votableModel(votableType string) (interface{}, error) {
+	switch votableType {
+	case domain.VotableTypeQuestion:
+		return &domain.Question{}, nil
+	case domain.VotableTypeAnswer:
+		return &domain.Answer{}, nil
+	}
+	return nil, fmt.Errorf("unsupported votable type: %s", votableType)
+}
+
+// SetVote records a user's vote on a question or answer and applies the difference to its
+// score. A value of 0 retracts the vote. It returns the user's previous vote (0 when there was
+// none) and the new score. The voted item's row is locked first so that concurrent votes by the
+// same user are applied one after the other instead of racing to insert the vote.
+func (r *VoteRepository) SetVote(userID, votableType, votableID string, value int) (previous int, score int, err error) {
+	model, err := votableModel(votableType)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("id = ?", votableID).First(model).Error; err != nil {
+			return err
+		}
+
+		var vote domain.Vote
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND votable_id = ? AND votable_type = ?", userID, votableID, votableType).
+			First(&vote).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if value != 0 {
+				vote = domain.Vote{
+					ID:          uuid.NewString(),
+					UserID:      userID,
+					VotableID:   votableID,
+					VotableType: votableType,
+					Value:       value,
+				}
+				if err := tx.Create(&vote).Error; err != nil {
+					return err
+				}
+			}
+		case err != nil:
+			return err
+		default:
+			previous = vote.Value
+			if value == 0 {
+				err = tx.Delete(&vote).Error
+			} else {
+				err = tx.Model(&vote).Update("value", value).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if delta := value - previous; delta != 0 {
+			if err := tx.Model(model).Where("id = ?", votableID).
+				UpdateColumn("score", gorm.Expr("score + ?", delta)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(model).Select("score").Where("id = ?", votableID).Scan(&score).Error
+	})
+	return previous, score, err
+}
@@ -4,36 +4,63 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/middleware"
+	postDomain "github.com/topboyasante/pitstop/internal/modules/post/domain"
+	postHandler "github.com/topboyasante/pitstop/internal/modules/post/handler"
 	"github.com/topboyasante/pitstop/internal/modules/question/handler"
 )
 
 // RegisterRoutes registers all question-related routes
-func RegisterRoutes(app fiber.Router, questionHandler *handler.QuestionHandler, answerHandler *handler.AnswerHandler) {
-	SetupRoutes(app, questionHandler, answerHandler)
+func RegisterRoutes(app fiber.Router, questionHandler *handler.QuestionHandler, answerHandler *handler.AnswerHandler, voteHandler *handler.VoteHandler, bountyHandler *handler.BountyHandler, lifecycleHandler *handler.LifecycleHandler, commentHandler *postHandler.CommentHandler, likeHandler *postHandler.LikeHandler) {
+	SetupRoutes(app, questionHandler, answerHandler, voteHandler, bountyHandler, lifecycleHandler, commentHandler, likeHandler)
 }
 
-// SetupRoutes sets up all question-related routes
-func SetupRoutes(app fiber.Router, questionHandler *handler.QuestionHandler, answerHandler *handler.AnswerHandler) {
+// SetupRoutes sets up all question-related routes. Comments and likes on questions and answers
+// are served by the post module's comment and like handlers.
+func SetupRoutes(app fiber.Router, questionHandler *handler.QuestionHandler, answerHandler *handler.AnswerHandler, voteHandler *handler.VoteHandler, bountyHandler *handler.BountyHandler, lifecycleHandler *handler.LifecycleHandler, commentHandler *postHandler.CommentHandler, likeHandler *postHandler.LikeHandler) {
 	// Question routes
 	questions := app.Group("/questions")
+	questionAnswers := questions.Group("/:question_id/answers")
+
+	// Public routes are registered ahead of the protected groups, whose JWT middleware
+	// applies to every route under the group prefix registered after it.
+	// A signed-in viewer does not see content from users they blocked, were blocked by or muted,
+	// and content by private accounts is only shown to their followers
+	optionalJWT := middleware.OptionalJWTMiddleware(config.Get())
+	questions.Get("/", optionalJWT, questionHandler.GetAllQuestions)
+	questions.Get("/tag", optionalJWT, questionHandler.GetQuestionsByTag)
+	questions.Get("/:id", optionalJWT, questionHandler.GetQuestion)
+	questions.Get("/:id/status", lifecycleHandler.GetStatus)
+	questions.Get("/:id/comments", optionalJWT, commentHandler.GetCommentsOn(postDomain.CommentableTypeQuestion, "id", ""))
+	questions.Get("/:id/likes", likeHandler.GetLikesOn(postDomain.LikableTypeQuestion, "id", ""))
 
-	// Public question routes
-	questions.Get("/", questionHandler.GetAllQuestions)
-	questions.Get("/tag", questionHandler.GetQuestionsByTag)
-	questions.Get("/:id", questionHandler.GetQuestion)
+	// Public answer routes
+	questionAnswers.Get("/", optionalJWT, answerHandler.GetAnswersByQuestionID)
+	questionAnswers.Get("/:answer_id", optionalJWT, answerHandler.GetAnswer)
+	questionAnswers.Get("/:answer_id/comments", optionalJWT, commentHandler.GetCommentsOn(postDomain.CommentableTypeAnswer, "answer_id", "question_id"))
+	questionAnswers.Get("/:answer_id/likes", likeHandler.GetLikesOn(postDomain.LikableTypeAnswer, "answer_id", "question_id"))
 
 	// Protected question routes (require authentication)
 	protected := questions.Group("", middleware.JWTMiddleware(config.Get()))
 	protected.Post("/", questionHandler.CreateQuestion)
 	protected.Put("/:id", questionHandler.UpdateQuestion)
 	protected.Delete("/:id", questionHandler.DeleteQuestion)
+	protected.Post("/:id/vote", voteHandler.VoteOnQuestion)
+	protected.Delete("/:id/vote", voteHandler.RetractQuestionVote)
+	protected.Post("/:id/bounty", bountyHandler.PlaceBounty)
+	protected.Post("/:id/bounty/award", bountyHandler.AwardBounty)
 
-	// Answer routes for questions
-	questionAnswers := questions.Group("/:question_id/answers")
+	// Question lifecycle routes (require authentication)
+	protected.Post("/:id/close", lifecycleHandler.VoteToClose)
+	protected.Post("/:id/duplicate", lifecycleHandler.VoteDuplicate)
+	protected.Post("/:id/reopen", lifecycleHandler.VoteToReopen)
+	protected.Post("/:id/lock", lifecycleHandler.LockQuestion)
+	protected.Post("/:id/unlock", lifecycleHandler.UnlockQuestion)
 
-	// Public answer routes
-	questionAnswers.Get("/", answerHandler.GetAnswersByQuestionID)
-	questionAnswers.Get("/:answer_id", answerHandler.GetAnswer)
+	// Question comment and like routes (require authentication)
+	protected.Post("/:id/comments", commentHandler.CreateCommentOn(postDomain.CommentableTypeQuestion, "id", ""))
+	protected.Post("/:id/comments/:parent_comment_id/reply", commentHandler.CreateReplyOn(postDomain.CommentableTypeQuestion, "id", ""))
+	protected.Post("/:id/like", likeHandler.ToggleLikeOn(postDomain.LikableTypeQuestion, "id", ""))
+	protected.Get("/:id/like/status", likeHandler.CheckUserLikedOn(postDomain.LikableTypeQuestion, "id"))
 
 	// Protected answer routes (require authentication)
 	protectedAnswers := questionAnswers.Group("", middleware.JWTMiddleware(config.Get()))
@@ -44,4 +71,14 @@ func SetupRoutes(app fiber.Router, questionHandler *handler.QuestionHandler, ans
 	// Answer acceptance routes (require authentication)
 	protectedAnswers.Post("/:answer_id/accept", answerHandler.AcceptAnswer)
 	protectedAnswers.Post("/:answer_id/unaccept", answerHandler.UnacceptAnswer)
-}
\ No newline at end of file
+
+	// Answer voting routes (require authentication)
+	protectedAnswers.Post("/:answer_id/vote", voteHandler.VoteOnAnswer)
+	protectedAnswers.Delete("/:answer_id/vote", voteHandler.RetractAnswerVote)
+
+	// Answer comment and like routes (require authentication)
+	protectedAnswers.Post("/:answer_id/comments", commentHandler.CreateCommentOn(postDomain.CommentableTypeAnswer, "answer_id", "question_id"))
+	protectedAnswers.Post("/:answer_id/comments/:parent_comment_id/reply", commentHandler.CreateReplyOn(postDomain.CommentableTypeAnswer, "answer_id", "question_id"))
+	protectedAnswers.Post("/:answer_id/like", likeHandler.ToggleLikeOn(postDomain.LikableTypeAnswer, "answer_id", "question_id"))
+	protectedAnswers.Get("/:answer_id/like/status", likeHandler.CheckUserLikedOn(postDomain.LikableTypeAnswer, "answer_id"))
+}
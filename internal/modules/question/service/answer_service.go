@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/go-playground/validator/v10"
@@ -8,24 +9,38 @@ import (
 	"github.com/topboyasante/pitstop/internal/modules/question/domain"
 	"github.com/topboyasante/pitstop/internal/modules/question/dto"
 	"github.com/topboyasante/pitstop/internal/modules/question/repository"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
 )
 
+// ErrBlocked is returned when the answerer and the question author have blocked each other
+var ErrBlocked = userService.ErrBlocked
+
+// ErrInvalidSort is returned when answers are requested in an unsupported sort order
+var ErrInvalidSort = errors.New("sort must be one of score, newest or oldest")
+
 // AnswerService handles answer business logic
 type AnswerService struct {
-	answerRepo   *repository.AnswerRepository
-	questionRepo *repository.QuestionRepository
-	validator    *validator.Validate
-	eventBus     *events.EventBus
+	answerRepo    *repository.AnswerRepository
+	questionRepo  *repository.QuestionRepository
+	blockService  *userService.BlockService
+	followService *userService.FollowService
+	policy        *policy.Policy
+	validator     *validator.Validate
+	eventBus      *events.EventBus
 }
 
 // NewAnswerService creates a new answer service instance
-func NewAnswerService(answerRepo *repository.AnswerRepository, questionRepo *repository.QuestionRepository, validator *validator.Validate, eventBus *events.EventBus) *AnswerService {
+func NewAnswerService(answerRepo *repository.AnswerRepository, questionRepo *repository.QuestionRepository, blockService *userService.BlockService, followService *userService.FollowService, policy *policy.Policy, validator *validator.Validate, eventBus *events.EventBus) *AnswerService {
 	return &AnswerService{
-		answerRepo:   answerRepo,
-		questionRepo: questionRepo,
-		validator:    validator,
-		eventBus:     eventBus,
+		answerRepo:    answerRepo,
+		questionRepo:  questionRepo,
+		blockService:  blockService,
+		followService: followService,
+		policy:        policy,
+		validator:     validator,
+		eventBus:      eventBus,
 	}
 }
 
@@ -36,10 +51,17 @@ func (s *AnswerService) CreateAnswer(questionID string, req dto.CreateAnswerRequ
 	}
 
 	// Verify question exists
-	_, err := s.questionRepo.GetByID(questionID)
+	question, err := s.questionRepo.GetByID(questionID)
 	if err != nil {
 		return nil, fmt.Errorf("question not found: %w", err)
 	}
+	if question.Status != domain.QuestionStatusOpen {
+		return nil, ErrQuestionClosed
+	}
+
+	if err := s.blockService.CheckInteraction(req.UserID, question.UserID); err != nil {
+		return nil, err
+	}
 
 	answer := &domain.Answer{
 		QuestionID: questionID,
@@ -53,35 +75,57 @@ func (s *AnswerService) CreateAnswer(questionID string, req dto.CreateAnswerRequ
 	}
 
 	logger.Info("Answer created successfully", "answer_id", answer.ID, "question_id", questionID)
+	s.eventBus.Publish("AnswerCreated", events.NewAnswerCreated(questionID, answer.ID, answer.UserID))
 
 	return &dto.AnswerResponse{
-		ID:         answer.ID,
-		QuestionID: answer.QuestionID,
-		UserID:     answer.UserID,
-		Content:    answer.Content,
-		IsAccepted: answer.IsAccepted,
-		LikeCount:  answer.LikeCount,
-		CreatedAt:  answer.CreatedAt,
-		UpdatedAt:  answer.UpdatedAt,
+		ID:           answer.ID,
+		QuestionID:   answer.QuestionID,
+		UserID:       answer.UserID,
+		Content:      answer.Content,
+		IsAccepted:   answer.IsAccepted,
+		Score:        answer.Score,
+		LikeCount:    answer.LikeCount,
+		CommentCount: answer.CommentCount,
+		CreatedAt:    answer.CreatedAt,
+		UpdatedAt:    answer.UpdatedAt,
 	}, nil
 }
 
-// GetAnswerByID retrieves an answer by ID
-func (s *AnswerService) GetAnswerByID(id string) (*dto.AnswerResponse, error) {
+// ResolveAuthor returns the author of an answer to the given question so it can be commented on
+// and liked. Answers by private accounts are only resolved for their followers.
+func (s *AnswerService) ResolveAuthor(id, questionID, viewerID string) (string, error) {
+	answer, err := s.answerRepo.GetByID(id)
+	if err != nil || answer.QuestionID != questionID {
+		return "", fmt.Errorf("answer not found")
+	}
+	if err := checkContentVisible(s.followService, "answer", answer.UserID, viewerID); err != nil {
+		return "", err
+	}
+	return answer.UserID, nil
+}
+
+// GetAnswerByID retrieves an answer by ID. An answer by a private account the viewer does not
+// follow is reported as not found.
+func (s *AnswerService) GetAnswerByID(id, viewerID string) (*dto.AnswerResponse, error) {
 	answer, err := s.answerRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("answer not found: %w", err)
 	}
+	if err := checkContentVisible(s.followService, "answer", answer.UserID, viewerID); err != nil {
+		return nil, err
+	}
 
 	response := &dto.AnswerResponse{
-		ID:         answer.ID,
-		QuestionID: answer.QuestionID,
-		UserID:     answer.UserID,
-		Content:    answer.Content,
-		IsAccepted: answer.IsAccepted,
-		LikeCount:  answer.LikeCount,
-		CreatedAt:  answer.CreatedAt,
-		UpdatedAt:  answer.UpdatedAt,
+		ID:           answer.ID,
+		QuestionID:   answer.QuestionID,
+		UserID:       answer.UserID,
+		Content:      answer.Content,
+		IsAccepted:   answer.IsAccepted,
+		Score:        answer.Score,
+		LikeCount:    answer.LikeCount,
+		CommentCount: answer.CommentCount,
+		CreatedAt:    answer.CreatedAt,
+		UpdatedAt:    answer.UpdatedAt,
 	}
 
 	if answer.User != nil {
@@ -95,22 +139,32 @@ func (s *AnswerService) GetAnswerByID(id string) (*dto.AnswerResponse, error) {
 	return response, nil
 }
 
-// GetAnswersByQuestionID retrieves all answers for a question with pagination
-func (s *AnswerService) GetAnswersByQuestionID(questionID string, page, limit int) (*dto.AnswersResponse, error) {
+// GetAnswersByQuestionID retrieves the answers to a question that the viewer may see, with pagination.
+// Answers are sorted by score unless sort asks for the newest or oldest first.
+func (s *AnswerService) GetAnswersByQuestionID(questionID string, page, limit int, sort, viewerID string) (*dto.AnswersResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
+	if sort == "" {
+		sort = repository.AnswerSortScore
+	}
+	if !repository.ValidAnswerSort(sort) {
+		return nil, ErrInvalidSort
+	}
 
-	// Verify question exists
-	_, err := s.questionRepo.GetByID(questionID)
+	// Verify the question exists and the viewer may see it
+	question, err := s.questionRepo.GetByID(questionID)
 	if err != nil {
 		return nil, fmt.Errorf("question not found: %w", err)
 	}
+	if err := checkContentVisible(s.followService, "question", question.UserID, viewerID); err != nil {
+		return nil, err
+	}
 
-	answers, totalCount, err := s.answerRepo.GetByQuestionID(questionID, page, limit)
+	answers, totalCount, err := s.answerRepo.GetByQuestionID(questionID, page, limit, sort, viewerID)
 	if err != nil {
 		logger.Error("Failed to retrieve answers", "question_id", questionID, "error", err)
 		return nil, fmt.Errorf("failed to retrieve answers: %w", err)
@@ -120,14 +174,16 @@ func (s *AnswerService) GetAnswersByQuestionID(questionID string, page, limit in
 	answerResponses := make([]dto.AnswerResponse, len(answers))
 	for i, answer := range answers {
 		answerResponses[i] = dto.AnswerResponse{
-			ID:         answer.ID,
-			QuestionID: answer.QuestionID,
-			UserID:     answer.UserID,
-			Content:    answer.Content,
-			IsAccepted: answer.IsAccepted,
-			LikeCount:  answer.LikeCount,
-			CreatedAt:  answer.CreatedAt,
-			UpdatedAt:  answer.UpdatedAt,
+			ID:           answer.ID,
+			QuestionID:   answer.QuestionID,
+			UserID:       answer.UserID,
+			Content:      answer.Content,
+			IsAccepted:   answer.IsAccepted,
+			Score:        answer.Score,
+			LikeCount:    answer.LikeCount,
+			CommentCount: answer.CommentCount,
+			CreatedAt:    answer.CreatedAt,
+			UpdatedAt:    answer.UpdatedAt,
 		}
 
 		if answer.User != nil {
@@ -152,14 +208,14 @@ func (s *AnswerService) GetAnswersByQuestionID(questionID string, page, limit in
 
 // AcceptAnswer marks an answer as accepted
 func (s *AnswerService) AcceptAnswer(answerID, questionID, userID string) error {
-	// Verify the question exists and belongs to the user
+	// Verify the question exists; only its author decides which answer is accepted
 	question, err := s.questionRepo.GetByID(questionID)
 	if err != nil {
 		return fmt.Errorf("question not found: %w", err)
 	}
 
-	if question.UserID != userID {
-		return fmt.Errorf("unauthorized: only the question author can accept answers")
+	if err := policy.RequireAuthor(userID, question.UserID, "accept answers"); err != nil {
+		return err
 	}
 
 	// Verify the answer exists and belongs to the question
@@ -172,10 +228,22 @@ func (s *AnswerService) AcceptAnswer(answerID, questionID, userID string) error
 		return fmt.Errorf("answer does not belong to this question")
 	}
 
-	if err := s.answerRepo.AcceptAnswer(answerID, questionID); err != nil {
+	previous, err := s.answerRepo.AcceptAnswer(answerID, questionID)
+	if err != nil {
 		logger.Error("Failed to accept answer", "answer_id", answerID, "question_id", questionID, "error", err)
 		return fmt.Errorf("failed to accept answer: %w", err)
 	}
+	if previous != nil && previous.ID == answerID {
+		return nil
+	}
+
+	// Events go out only once the transaction has committed
+	if previous != nil {
+		s.eventBus.Publish("AnswerUnaccepted", events.NewAnswerUnaccepted(questionID, previous.ID, question.UserID, previous.UserID))
+	} else {
+		s.eventBus.Publish("QuestionAnswered", events.NewQuestionAnswered(questionID, answerID, question.UserID))
+	}
+	s.eventBus.Publish("AnswerAccepted", events.NewAnswerAccepted(questionID, answerID, question.UserID, answer.UserID))
 
 	logger.Info("Answer accepted successfully", "answer_id", answerID, "question_id", questionID)
 	return nil
@@ -183,14 +251,14 @@ func (s *AnswerService) AcceptAnswer(answerID, questionID, userID string) error
 
 // UnacceptAnswer marks an answer as not accepted
 func (s *AnswerService) UnacceptAnswer(answerID, questionID, userID string) error {
-	// Verify the question exists and belongs to the user
+	// Verify the question exists; only its author decides which answer is accepted
 	question, err := s.questionRepo.GetByID(questionID)
 	if err != nil {
 		return fmt.Errorf("question not found: %w", err)
 	}
 
-	if question.UserID != userID {
-		return fmt.Errorf("unauthorized: only the question author can unaccept answers")
+	if err := policy.RequireAuthor(userID, question.UserID, "unaccept answers"); err != nil {
+		return err
 	}
 
 	// Verify the answer exists and belongs to the question
@@ -203,17 +271,23 @@ func (s *AnswerService) UnacceptAnswer(answerID, questionID, userID string) erro
 		return fmt.Errorf("answer does not belong to this question")
 	}
 
-	if err := s.answerRepo.UnacceptAnswer(answerID, questionID); err != nil {
+	unaccepted, err := s.answerRepo.UnacceptAnswer(answerID, questionID)
+	if err != nil {
 		logger.Error("Failed to unaccept answer", "answer_id", answerID, "question_id", questionID, "error", err)
 		return fmt.Errorf("failed to unaccept answer: %w", err)
 	}
+	if !unaccepted {
+		return nil
+	}
+
+	s.eventBus.Publish("AnswerUnaccepted", events.NewAnswerUnaccepted(questionID, answerID, question.UserID, answer.UserID))
 
 	logger.Info("Answer unaccepted successfully", "answer_id", answerID, "question_id", questionID)
 	return nil
 }
 
-// UpdateAnswer updates an answer
-func (s *AnswerService) UpdateAnswer(id string, req dto.UpdateAnswerRequest, userID string) (*dto.AnswerResponse, error) {
+// UpdateAnswer updates an answer on behalf of its author or a moderator
+func (s *AnswerService) UpdateAnswer(id string, req dto.UpdateAnswerRequest, actor policy.Actor) (*dto.AnswerResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
@@ -223,9 +297,8 @@ func (s *AnswerService) UpdateAnswer(id string, req dto.UpdateAnswerRequest, use
 		return nil, fmt.Errorf("answer not found: %w", err)
 	}
 
-	// Verify the answer belongs to the user
-	if answer.UserID != userID {
-		return nil, fmt.Errorf("unauthorized: only the answer author can update this answer")
+	if err := s.policy.RequireAuthorOrModerator(actor, answer.UserID, "update this answer"); err != nil {
+		return nil, err
 	}
 
 	// Update fields if provided
@@ -241,34 +314,55 @@ func (s *AnswerService) UpdateAnswer(id string, req dto.UpdateAnswerRequest, use
 	logger.Info("Answer updated successfully", "answer_id", id)
 
 	return &dto.AnswerResponse{
-		ID:         answer.ID,
-		QuestionID: answer.QuestionID,
-		UserID:     answer.UserID,
-		Content:    answer.Content,
-		IsAccepted: answer.IsAccepted,
-		LikeCount:  answer.LikeCount,
-		CreatedAt:  answer.CreatedAt,
-		UpdatedAt:  answer.UpdatedAt,
+		ID:           answer.ID,
+		QuestionID:   answer.QuestionID,
+		UserID:       answer.UserID,
+		Content:      answer.Content,
+		IsAccepted:   answer.IsAccepted,
+		Score:        answer.Score,
+		LikeCount:    answer.LikeCount,
+		CommentCount: answer.CommentCount,
+		CreatedAt:    answer.CreatedAt,
+		UpdatedAt:    answer.UpdatedAt,
 	}, nil
 }
 
-// DeleteAnswer deletes an answer
-func (s *AnswerService) DeleteAnswer(id string, userID string) error {
+// DeleteAnswer deletes an answer on behalf of its author or a moderator. A moderator may remove
+// the answer as spam, which also penalizes its author.
+func (s *AnswerService) DeleteAnswer(id string, actor policy.Actor, asSpam bool) error {
 	answer, err := s.answerRepo.GetByID(id)
 	if err != nil {
 		return fmt.Errorf("answer not found: %w", err)
 	}
 
-	// Verify the answer belongs to the user
-	if answer.UserID != userID {
-		return fmt.Errorf("unauthorized: only the answer author can delete this answer")
+	if asSpam {
+		if err := s.policy.RequireModerator(actor, "remove content as spam"); err != nil {
+			return err
+		}
+	} else if err := s.policy.RequireAuthorOrModerator(actor, answer.UserID, "delete this answer"); err != nil {
+		return err
 	}
 
-	if err := s.answerRepo.Delete(id); err != nil {
+	wasAccepted, err := s.answerRepo.Delete(id)
+	if err != nil {
 		logger.Error("Failed to delete answer", "answer_id", id, "error", err)
 		return fmt.Errorf("failed to delete answer: %w", err)
 	}
 
 	logger.Info("Answer deleted successfully", "answer_id", id)
+
+	if asSpam {
+		s.eventBus.Publish("ContentRemovedAsSpam", events.NewContentRemovedAsSpam("answer", id, answer.UserID, actor.UserID))
+	}
+
+	// Deleting the accepted answer unaccepts it, which takes back what accepting it earned
+	if wasAccepted {
+		question, err := s.questionRepo.GetByID(answer.QuestionID)
+		if err != nil {
+			logger.Error("Failed to get question of deleted accepted answer", "answer_id", id, "question_id", answer.QuestionID, "error", err)
+			return nil
+		}
+		s.eventBus.Publish("AnswerUnaccepted", events.NewAnswerUnaccepted(answer.QuestionID, id, question.UserID, answer.UserID))
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/repository"
+	reputationDomain "github.com/topboyasante/pitstop/internal/modules/reputation/domain"
+	reputationService "github.com/topboyasante/pitstop/internal/modules/reputation/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQuestionAnswered is returned when a bounty is placed on a question with an accepted answer
+	ErrQuestionAnswered = errors.New("question already has an accepted answer")
+	// ErrBountyExists is returned when a bounty is placed on a question that already has an open one
+	ErrBountyExists = errors.New("question already has an open bounty")
+	// ErrNoOpenBounty is returned when a bounty is awarded on a question without an open one
+	ErrNoOpenBounty = errors.New("question has no open bounty")
+	// ErrSelfAward is returned when a question author awards their bounty to their own answer
+	ErrSelfAward = errors.New("you cannot award a bounty to your own answer")
+	// ErrInsufficientReputation is returned when a bounty is larger than the author's reputation
+	ErrInsufficientReputation = reputationService.ErrInsufficientReputation
+)
+
+// dueBountiesBatch is how many expired bounties one sweep settles at most
+const dueBountiesBatch = 100
+
+// BountyService handles bounties on questions
+type BountyService struct {
+	config            *config.Config
+	bountyRepo        *repository.BountyRepository
+	questionRepo      *repository.QuestionRepository
+	answerRepo        *repository.AnswerRepository
+	reputationService *reputationService.ReputationService
+	validator         *validator.Validate
+	eventBus          *events.EventBus
+}
+
+// NewBountyService creates a new bounty service instance
+func NewBountyService(config *config.Config, bountyRepo *repository.BountyRepository, questionRepo *repository.QuestionRepository, answerRepo *repository.AnswerRepository, reputationService *reputationService.ReputationService, validator *validator.Validate, eventBus *events.EventBus) *BountyService {
+	return &BountyService{
+		config:            config,
+		bountyRepo:        bountyRepo,
+		questionRepo:      questionRepo,
+		answerRepo:        answerRepo,
+		reputationService: reputationService,
+		validator:         validator,
+		eventBus:          eventBus,
+	}
+}
+
+// PlaceBounty offers reputation for answers to the user's question. The amount is taken from
+// their reputation right away and the bounty stays open for the configured duration.
+func (s *BountyService) PlaceBounty(questionID, userID string, req dto.PlaceBountyRequest) (*dto.BountyResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if req.Amount < s.config.Bounties.MinAmount || req.Amount > s.config.Bounties.MaxAmount {
+		return nil, fmt.Errorf("validation failed: amount must be between %d and %d", s.config.Bounties.MinAmount, s.config.Bounties.MaxAmount)
+	}
+
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("question not found: %w", err)
+	}
+	if err := policy.RequireAuthor(userID, question.UserID, "place a bounty on this question"); err != nil {
+		return nil, err
+	}
+	if question.Status != domain.QuestionStatusOpen {
+		return nil, ErrQuestionClosed
+	}
+	if question.IsAnswered {
+		return nil, ErrQuestionAnswered
+	}
+	if question.Bounty != nil && question.Bounty.Status == domain.BountyStatusOpen {
+		return nil, ErrBountyExists
+	}
+
+	bounty := &domain.Bounty{
+		QuestionID: questionID,
+		UserID:     userID,
+		Amount:     req.Amount,
+		Status:     domain.BountyStatusOpen,
+		ExpiresAt:  time.Now().Add(s.config.Bounties.Duration),
+	}
+
+	err = s.bountyRepo.Create(bounty, func(tx *gorm.DB) error {
+		return s.reputationService.Spend(tx, userID, req.Amount, reputationDomain.ReasonBountyPlaced, domain.VotableTypeQuestion, questionID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientReputation) {
+			return nil, ErrInsufficientReputation
+		}
+		// The open bounty index refuses a bounty placed concurrently with another
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrBountyExists
+		}
+		logger.Error("Failed to place bounty", "question_id", questionID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to place bounty: %w", err)
+	}
+
+	logger.Info("Bounty placed", "bounty_id", bounty.ID, "question_id", questionID, "user_id", userID, "amount", bounty.Amount)
+	return bountyResponse(bounty), nil
+}
+
+// AwardBounty pays the open bounty on the user's question out to an answer
+func (s *BountyService) AwardBounty(questionID, userID string, req dto.AwardBountyRequest) (*dto.BountyResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if _, err := s.questionRepo.GetByID(questionID); err != nil {
+		return nil, fmt.Errorf("question not found: %w", err)
+	}
+
+	bounty, err := s.bountyRepo.GetOpenByQuestionID(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bounty: %w", err)
+	}
+	if bounty == nil {
+		return nil, ErrNoOpenBounty
+	}
+	if err := policy.RequireAuthor(userID, bounty.UserID, "award this bounty"); err != nil {
+		return nil, err
+	}
+
+	answer, err := s.answerRepo.GetByID(req.AnswerID)
+	if err != nil {
+		return nil, fmt.Errorf("answer not found: %w", err)
+	}
+	if answer.QuestionID != questionID {
+		return nil, fmt.Errorf("answer does not belong to this question")
+	}
+	if answer.UserID == userID {
+		return nil, ErrSelfAward
+	}
+
+	settled, err := s.settle(bounty, answer, false)
+	if err != nil {
+		return nil, err
+	}
+	if !settled {
+		return nil, ErrNoOpenBounty
+	}
+
+	awarded, err := s.bountyRepo.GetByID(bounty.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bounty: %w", err)
+	}
+	return bountyResponse(awarded), nil
+}
+
+// ExpireDueBounties settles the open bounties whose time is up. Each goes to the highest-scored
+// answer not written by the question author; a bounty without such an answer expires unpaid.
+func (s *BountyService) ExpireDueBounties() (int, error) {
+	bounties, err := s.bountyRepo.GetDue(time.Now(), dueBountiesBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due bounties: %w", err)
+	}
+
+	settledCount := 0
+	for i := range bounties {
+		bounty := &bounties[i]
+
+		answer, err := s.answerRepo.GetTopScored(bounty.QuestionID, bounty.UserID)
+		if err != nil {
+			logger.Error("Failed to find answer for expired bounty", "bounty_id", bounty.ID, "question_id", bounty.QuestionID, "error", err)
+			continue
+		}
+
+		settled, err := s.settle(bounty, answer, true)
+		if err != nil {
+			logger.Error("Failed to settle expired bounty", "bounty_id", bounty.ID, "question_id", bounty.QuestionID, "error", err)
+			continue
+		}
+		if settled {
+			settledCount++
+		}
+	}
+
+	return settledCount, nil
+}
+
+// StartExpirySweeper settles expired bounties on the given interval until stop is closed
+func (s *BountyService) StartExpirySweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.ExpireDueBounties(); err != nil {
+					logger.Error("Bounty expiry sweep failed", "error", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// settle closes a bounty, paying it to answer or, when answer is nil, letting it expire. It
+// reports false when the bounty had already been settled.
+func (s *BountyService) settle(bounty *domain.Bounty, answer *domain.Answer, automatic bool) (bool, error) {
+	var answerID *string
+	if answer != nil {
+		answerID = &answer.ID
+	}
+
+	settled, err := s.bountyRepo.Settle(bounty.ID, answerID, func(tx *gorm.DB) error {
+		return s.reputationService.Grant(tx, answer.UserID, bounty.Amount, reputationDomain.ReasonBountyAwarded, domain.VotableTypeAnswer, answer.ID, bounty.UserID)
+	})
+	if err != nil {
+		logger.Error("Failed to settle bounty", "bounty_id", bounty.ID, "question_id", bounty.QuestionID, "error", err)
+		return false, fmt.Errorf("failed to settle bounty: %w", err)
+	}
+	if !settled {
+		return false, nil
+	}
+
+	if answer == nil {
+		logger.Info("Bounty expired", "bounty_id", bounty.ID, "question_id", bounty.QuestionID)
+		return true, nil
+	}
+
+	logger.Info("Bounty awarded", "bounty_id", bounty.ID, "question_id", bounty.QuestionID, "answer_id", answer.ID, "automatic", automatic)
+	s.eventBus.Publish("BountyAwarded", events.NewBountyAwarded(bounty.ID, bounty.QuestionID, answer.ID, bounty.UserID, answer.UserID, bounty.Amount, automatic))
+	return true, nil
+}
+
+// bountyResponse converts a bounty for API responses, returning nil for no bounty
+func bountyResponse(bounty *domain.Bounty) *dto.BountyResponse {
+	if bounty == nil {
+		return nil
+	}
+	return &dto.BountyResponse{
+		ID:              bounty.ID,
+		UserID:          bounty.UserID,
+		Amount:          bounty.Amount,
+		Status:          bounty.Status,
+		AwardedAnswerID: bounty.AwardedAnswerID,
+		ExpiresAt:       bounty.ExpiresAt,
+		SettledAt:       bounty.SettledAt,
+		CreatedAt:       bounty.CreatedAt,
+	}
+}
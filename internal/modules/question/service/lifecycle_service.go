@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/repository"
+	reputationDomain "github.com/topboyasante/pitstop/internal/modules/reputation/domain"
+	reputationService "github.com/topboyasante/pitstop/internal/modules/reputation/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQuestionClosed is returned when a question that is not open is answered or given a bounty
+	ErrQuestionClosed = errors.New("question is closed")
+	// ErrQuestionNotClosed is returned when a reopen vote is cast on an open question
+	ErrQuestionNotClosed = errors.New("question is not closed")
+	// ErrQuestionLocked is returned when votes are cast on a locked question or it is locked again
+	ErrQuestionLocked = errors.New("question is locked")
+	// ErrQuestionNotLocked is returned when a question that is not locked is unlocked
+	ErrQuestionNotLocked = errors.New("question is not locked")
+	// ErrAlreadyVoted is returned when a user casts a second close or reopen vote on a question
+	ErrAlreadyVoted = errors.New("you have already voted on this question")
+	// ErrNoClosePrivilege is returned when a user without the close vote privilege votes
+	ErrNoClosePrivilege = errors.New("you do not have enough reputation to vote to close or reopen questions")
+	// ErrInvalidDuplicate is returned when a question is marked a duplicate of itself
+	ErrInvalidDuplicate = errors.New("a question cannot be a duplicate of itself")
+)
+
+// LifecycleService handles closing, reopening and locking questions
+type LifecycleService struct {
+	config            *config.Config
+	closeVoteRepo     *repository.CloseVoteRepository
+	questionRepo      *repository.QuestionRepository
+	reputationService *reputationService.ReputationService
+	policy            *policy.Policy
+	validator         *validator.Validate
+	eventBus          *events.EventBus
+}
+
+// NewLifecycleService creates a new lifecycle service instance
+func NewLifecycleService(config *config.Config, closeVoteRepo *repository.CloseVoteRepository, questionRepo *repository.QuestionRepository, reputationService *reputationService.ReputationService, policy *policy.Policy, validator *validator.Validate, eventBus *events.EventBus) *LifecycleService {
+	return &LifecycleService{
+		config:            config,
+		closeVoteRepo:     closeVoteRepo,
+		questionRepo:      questionRepo,
+		reputationService: reputationService,
+		policy:            policy,
+		validator:         validator,
+		eventBus:          eventBus,
+	}
+}
+
+// statusChange is a lifecycle change made inside a vote or moderator transaction, published
+// once the transaction commits
+type statusChange struct {
+	status      string
+	reason      string
+	canonicalID *string
+}
+
+// GetStatus returns a question's lifecycle state and its pending votes
+func (s *LifecycleService) GetStatus(questionID string) (*dto.QuestionStatusResponse, error) {
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("question not found: %w", err)
+	}
+
+	counts, err := s.closeVoteRepo.CountVotes(questionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count close votes: %w", err)
+	}
+
+	return &dto.QuestionStatusResponse{
+		QuestionID:    question.ID,
+		Status:        question.Status,
+		StatusReason:  question.StatusReason,
+		CanonicalID:   question.CanonicalID,
+		CloseVotes:    counts[domain.CloseVoteKindClose],
+		ReopenVotes:   counts[domain.CloseVoteKindReopen],
+		VotesRequired: s.config.Questions.CloseVotesRequired,
+	}, nil
+}
+
+// VoteToClose casts a vote to close an open question for a reason
+func (s *LifecycleService) VoteToClose(questionID string, actor policy.Actor, req dto.CloseVoteRequest) (*dto.QuestionStatusResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.castVote(questionID, actor, domain.CloseVote{
+		Kind:   domain.CloseVoteKindClose,
+		Reason: req.Reason,
+	})
+}
+
+// VoteDuplicate casts a vote to close an open question as a duplicate of another. When the other
+// question is itself a duplicate, the vote points at the question it links to.
+func (s *LifecycleService) VoteDuplicate(questionID string, actor policy.Actor, req dto.DuplicateVoteRequest) (*dto.QuestionStatusResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	canonical, err := s.questionRepo.GetByID(req.CanonicalID)
+	if err != nil {
+		return nil, fmt.Errorf("question not found: %w", err)
+	}
+	if canonical.Status == domain.QuestionStatusDuplicate && canonical.CanonicalID != nil {
+		canonical, err = s.questionRepo.GetByID(*canonical.CanonicalID)
+		if err != nil {
+			return nil, fmt.Errorf("question not found: %w", err)
+		}
+	}
+	if canonical.ID == questionID {
+		return nil, ErrInvalidDuplicate
+	}
+
+	return s.castVote(questionID, actor, domain.CloseVote{
+		Kind:        domain.CloseVoteKindClose,
+		Reason:      domain.CloseReasonDuplicate,
+		CanonicalID: &canonical.ID,
+	})
+}
+
+// VoteToReopen casts a vote to reopen a closed or duplicate question
+func (s *LifecycleService) VoteToReopen(questionID string, actor policy.Actor) (*dto.QuestionStatusResponse, error) {
+	return s.castVote(questionID, actor, domain.CloseVote{
+		Kind: domain.CloseVoteKindReopen,
+	})
+}
+
+// castVote records a close or reopen vote and changes the question's state once enough votes of
+// the kind are in. A moderator's vote is binding on its own.
+func (s *LifecycleService) castVote(questionID string, actor policy.Actor, vote domain.CloseVote) (*dto.QuestionStatusResponse, error) {
+	userID := actor.UserID
+	allowed, err := s.reputationService.HasPrivilege(userID, reputationDomain.PrivilegeCloseVote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check close vote privilege: %w", err)
+	}
+	if !allowed {
+		return nil, ErrNoClosePrivilege
+	}
+
+	moderator, err := s.policy.IsModerator(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var change *statusChange
+	err = s.closeVoteRepo.WithLockedQuestion(questionID, func(tx *gorm.DB, question *domain.Question) error {
+		switch {
+		case question.Status == domain.QuestionStatusLocked:
+			return ErrQuestionLocked
+		case vote.Kind == domain.CloseVoteKindClose && question.Status != domain.QuestionStatusOpen:
+			return ErrQuestionClosed
+		case vote.Kind == domain.CloseVoteKindReopen && question.Status == domain.QuestionStatusOpen:
+			return ErrQuestionNotClosed
+		}
+
+		vote.QuestionID = questionID
+		vote.UserID = userID
+		added, err := s.closeVoteRepo.AddVote(tx, &vote)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return ErrAlreadyVoted
+		}
+
+		votes := []domain.CloseVote{vote}
+		if !moderator {
+			votes, err = s.closeVoteRepo.GetVotes(tx, questionID, vote.Kind)
+			if err != nil {
+				return err
+			}
+			if len(votes) < s.config.Questions.CloseVotesRequired {
+				return nil
+			}
+		}
+
+		change = tallyVotes(vote.Kind, votes)
+		return s.closeVoteRepo.SetStatus(tx, questionID, change.status, change.reason, change.canonicalID)
+	})
+	if err != nil {
+		return nil, s.lifecycleError(err, "Failed to record close vote", questionID, userID)
+	}
+
+	if change != nil {
+		s.publishChange(questionID, userID, change)
+	}
+	return s.GetStatus(questionID)
+}
+
+// tallyVotes decides the outcome of a round of votes. Reopen votes reopen the question. Close
+// votes close it for the reason most voters gave, the earliest reason winning a tie; when that
+// reason is duplicate, it links to the canonical question most duplicate voters named.
+func tallyVotes(kind string, votes []domain.CloseVote) *statusChange {
+	if kind == domain.CloseVoteKindReopen {
+		return &statusChange{status: domain.QuestionStatusOpen}
+	}
+
+	reason := mostCommon(votes, func(vote domain.CloseVote) string { return vote.Reason })
+	if reason != domain.CloseReasonDuplicate {
+		return &statusChange{status: domain.QuestionStatusClosed, reason: reason}
+	}
+
+	canonicalID := mostCommon(votes, func(vote domain.CloseVote) string {
+		if vote.CanonicalID == nil {
+			return ""
+		}
+		return *vote.CanonicalID
+	})
+	return &statusChange{status: domain.QuestionStatusDuplicate, reason: reason, canonicalID: &canonicalID}
+}
+
+// mostCommon returns the non-empty key shared by most votes, the earliest key winning a tie
+func mostCommon(votes []domain.CloseVote, key func(domain.CloseVote) string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, vote := range votes {
+		k := key(vote)
+		if k == "" {
+			continue
+		}
+		counts[k]++
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+// LockQuestion locks a question so it takes no answers or close votes until a moderator unlocks
+// it. Pending votes are discarded.
+func (s *LifecycleService) LockQuestion(questionID string, actor policy.Actor, req dto.LockQuestionRequest) (*dto.QuestionStatusResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.moderate(questionID, actor, func(question *domain.Question) (*statusChange, error) {
+		if question.Status == domain.QuestionStatusLocked {
+			return nil, ErrQuestionLocked
+		}
+		return &statusChange{status: domain.QuestionStatusLocked, reason: strings.TrimSpace(req.Reason)}, nil
+	})
+}
+
+// UnlockQuestion reopens a locked question
+func (s *LifecycleService) UnlockQuestion(questionID string, actor policy.Actor) (*dto.QuestionStatusResponse, error) {
+	return s.moderate(questionID, actor, func(question *domain.Question) (*statusChange, error) {
+		if question.Status != domain.QuestionStatusLocked {
+			return nil, ErrQuestionNotLocked
+		}
+		return &statusChange{status: domain.QuestionStatusOpen}, nil
+	})
+}
+
+// moderate applies the state change decide returns for a question, on behalf of a moderator
+func (s *LifecycleService) moderate(questionID string, actor policy.Actor, decide func(question *domain.Question) (*statusChange, error)) (*dto.QuestionStatusResponse, error) {
+	userID := actor.UserID
+	if err := s.policy.RequireModerator(actor, "lock or unlock questions"); err != nil {
+		return nil, err
+	}
+
+	var change *statusChange
+	err := s.closeVoteRepo.WithLockedQuestion(questionID, func(tx *gorm.DB, question *domain.Question) error {
+		var err error
+		change, err = decide(question)
+		if err != nil {
+			return err
+		}
+		return s.closeVoteRepo.SetStatus(tx, questionID, change.status, change.reason, change.canonicalID)
+	})
+	if err != nil {
+		return nil, s.lifecycleError(err, "Failed to change question status", questionID, userID)
+	}
+
+	s.publishChange(questionID, userID, change)
+	return s.GetStatus(questionID)
+}
+
+// lifecycleError passes the service's own errors through and wraps the rest
+func (s *LifecycleService) lifecycleError(err error, failureMessage, questionID, userID string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("question not found: %w", err)
+	}
+	for _, known := range []error{ErrQuestionClosed, ErrQuestionNotClosed, ErrQuestionLocked, ErrQuestionNotLocked, ErrAlreadyVoted} {
+		if errors.Is(err, known) {
+			return err
+		}
+	}
+	logger.Error(failureMessage, "question_id", questionID, "user_id", userID, "error", err)
+	return fmt.Errorf("failed to change question status: %w", err)
+}
+
+// publishChange publishes a committed lifecycle change
+func (s *LifecycleService) publishChange(questionID, userID string, change *statusChange) {
+	canonicalID := ""
+	if change.canonicalID != nil {
+		canonicalID = *change.canonicalID
+	}
+
+	s.eventBus.Publish("QuestionStatusChanged", events.NewQuestionStatusChanged(questionID, change.status, change.reason, canonicalID, userID))
+}
@@ -1,30 +1,49 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/modules/question/domain"
 	"github.com/topboyasante/pitstop/internal/modules/question/dto"
 	"github.com/topboyasante/pitstop/internal/modules/question/repository"
+	reputationDomain "github.com/topboyasante/pitstop/internal/modules/reputation/domain"
+	reputationService "github.com/topboyasante/pitstop/internal/modules/reputation/service"
+	viewDomain "github.com/topboyasante/pitstop/internal/modules/view/domain"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	viewService "github.com/topboyasante/pitstop/internal/modules/view/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
 )
 
+// ErrOpenBountyDelete is returned when a question with an open bounty is deleted
+var ErrOpenBountyDelete = errors.New("a question with an open bounty cannot be deleted")
+
 // QuestionService handles question business logic
 type QuestionService struct {
-	questionRepo *repository.QuestionRepository
-	validator    *validator.Validate
-	eventBus     *events.EventBus
+	questionRepo      *repository.QuestionRepository
+	viewService       *viewService.ViewService
+	reputationService *reputationService.ReputationService
+	followService     *userService.FollowService
+	policy            *policy.Policy
+	validator         *validator.Validate
+	eventBus          *events.EventBus
 }
 
 // NewQuestionService creates a new question service instance
-func NewQuestionService(questionRepo *repository.QuestionRepository, validator *validator.Validate, eventBus *events.EventBus) *QuestionService {
+func NewQuestionService(questionRepo *repository.QuestionRepository, viewService *viewService.ViewService, reputationService *reputationService.ReputationService, followService *userService.FollowService, policy *policy.Policy, validator *validator.Validate, eventBus *events.EventBus) *QuestionService {
 	return &QuestionService{
-		questionRepo: questionRepo,
-		validator:    validator,
-		eventBus:     eventBus,
+		questionRepo:      questionRepo,
+		viewService:       viewService,
+		reputationService: reputationService,
+		followService:     followService,
+		policy:            policy,
+		validator:         validator,
+		eventBus:          eventBus,
 	}
 }
 
@@ -35,10 +54,13 @@ func (s *QuestionService) CreateQuestion(req dto.CreateQuestionRequest) (*dto.Qu
 	}
 
 	question := &domain.Question{
-		UserID:  req.UserID,
-		Title:   req.Title,
-		Content: req.Content,
-		Tags:    req.Tags,
+		UserID:   req.UserID,
+		Title:    req.Title,
+		Content:  req.Content,
+		Tags:     req.Tags,
+		CarMake:  strings.TrimSpace(req.CarMake),
+		CarModel: strings.TrimSpace(req.CarModel),
+		CarYear:  req.CarYear,
 	}
 
 	if err := s.questionRepo.Create(question); err != nil {
@@ -47,6 +69,7 @@ func (s *QuestionService) CreateQuestion(req dto.CreateQuestionRequest) (*dto.Qu
 	}
 
 	logger.Info("Question created successfully", "question_id", question.ID)
+	s.eventBus.Publish("QuestionCreated", events.NewQuestionCreated(question.ID, question.UserID))
 
 	// Parse tags for response
 	tags := parseTags(question.Tags)
@@ -57,21 +80,48 @@ func (s *QuestionService) CreateQuestion(req dto.CreateQuestionRequest) (*dto.Qu
 		Title:        question.Title,
 		Content:      question.Content,
 		Tags:         tags,
+		CarMake:      question.CarMake,
+		CarModel:     question.CarModel,
+		CarYear:      question.CarYear,
 		IsAnswered:   question.IsAnswered,
+		Score:        question.Score,
+		Status:       question.Status,
+		StatusReason: question.StatusReason,
+		CanonicalID:  question.CanonicalID,
 		CommentCount: question.CommentCount,
 		LikeCount:    question.LikeCount,
 		AnswerCount:  question.AnswerCount,
+		ViewCount:    question.ViewCount,
+		Bounty:       bountyResponse(question.Bounty),
 		CreatedAt:    question.CreatedAt,
 		UpdatedAt:    question.UpdatedAt,
 	}, nil
 }
 
-// GetQuestionByID retrieves a question by ID
-func (s *QuestionService) GetQuestionByID(id string) (*dto.QuestionResponse, error) {
+// ResolveAuthor returns the author of a question so it can be commented on and liked.
+// Questions by private accounts are only resolved for their followers. Questions are not nested
+// under other content, so there is no container ID to check.
+func (s *QuestionService) ResolveAuthor(id, _, viewerID string) (string, error) {
+	question, err := s.questionRepo.GetByID(id)
+	if err != nil {
+		return "", fmt.Errorf("question not found")
+	}
+	if err := checkContentVisible(s.followService, "question", question.UserID, viewerID); err != nil {
+		return "", err
+	}
+	return question.UserID, nil
+}
+
+// GetQuestionByID retrieves a question by ID. A question by a private account the viewer does
+// not follow is reported as not found.
+func (s *QuestionService) GetQuestionByID(id, viewerID string) (*dto.QuestionResponse, error) {
 	question, err := s.questionRepo.GetByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("question not found: %w", err)
 	}
+	if err := checkContentVisible(s.followService, "question", question.UserID, viewerID); err != nil {
+		return nil, err
+	}
 
 	// Parse tags for response
 	tags := parseTags(question.Tags)
@@ -82,10 +132,19 @@ func (s *QuestionService) GetQuestionByID(id string) (*dto.QuestionResponse, err
 		Title:        question.Title,
 		Content:      question.Content,
 		Tags:         tags,
+		CarMake:      question.CarMake,
+		CarModel:     question.CarModel,
+		CarYear:      question.CarYear,
 		IsAnswered:   question.IsAnswered,
+		Score:        question.Score,
+		Status:       question.Status,
+		StatusReason: question.StatusReason,
+		CanonicalID:  question.CanonicalID,
 		CommentCount: question.CommentCount,
 		LikeCount:    question.LikeCount,
 		AnswerCount:  question.AnswerCount,
+		ViewCount:    question.ViewCount,
+		Bounty:       bountyResponse(question.Bounty),
 		CreatedAt:    question.CreatedAt,
 		UpdatedAt:    question.UpdatedAt,
 	}
@@ -101,8 +160,19 @@ func (s *QuestionService) GetQuestionByID(id string) (*dto.QuestionResponse, err
 	return response, nil
 }
 
-// GetAllQuestions retrieves all questions with pagination
-func (s *QuestionService) GetAllQuestions(page, limit int) (*dto.QuestionsResponse, error) {
+// RecordView counts a view of a question by the viewer, once per viewer within the view window
+func (s *QuestionService) RecordView(id, viewerID, ip, userAgent string) {
+	s.viewService.RecordView(viewDomain.ViewableTypeQuestion, id, viewerID, ip, userAgent)
+}
+
+// GetAllQuestions retrieves the questions matching a query with pagination. Questions hidden from
+// the viewer are left out.
+func (s *QuestionService) GetAllQuestions(query dto.ListQuestionsQuery, viewerID string) (*dto.QuestionsResponse, error) {
+	if err := s.validator.Struct(query); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	page, limit := query.Page, query.Limit
 	if page < 1 {
 		page = 1
 	}
@@ -110,64 +180,35 @@ func (s *QuestionService) GetAllQuestions(page, limit int) (*dto.QuestionsRespon
 		limit = 20
 	}
 
-	questions, totalCount, err := s.questionRepo.GetAll(page, limit)
-	if err != nil {
-		logger.Error("Failed to retrieve questions", "error", err)
-		return nil, fmt.Errorf("failed to retrieve questions: %w", err)
+	filter := repository.QuestionFilter{
+		Answered:     query.Answered,
+		NoAnswers:    query.NoAnswers,
+		Tags:         parseTags(query.Tags),
+		MatchAllTags: query.TagMatch == "all",
+		CarMake:      strings.TrimSpace(query.CarMake),
+		CarModel:     strings.TrimSpace(query.CarModel),
+		CarYear:      query.CarYear,
+		AuthorID:     strings.TrimSpace(query.Author),
+		Bountied:     query.Bountied,
+		Sort:         query.Sort,
+		ViewerID:     viewerID,
 	}
-
-	// Convert to response format
-	questionResponses := make([]dto.QuestionResponse, len(questions))
-	for i, question := range questions {
-		tags := parseTags(question.Tags)
-
-		questionResponses[i] = dto.QuestionResponse{
-			ID:           question.ID,
-			UserID:       question.UserID,
-			Title:        question.Title,
-			Content:      question.Content,
-			Tags:         tags,
-			IsAnswered:   question.IsAnswered,
-			CommentCount: question.CommentCount,
-			LikeCount:    question.LikeCount,
-			AnswerCount:  question.AnswerCount,
-			CreatedAt:    question.CreatedAt,
-			UpdatedAt:    question.UpdatedAt,
-		}
-
-		if question.User != nil {
-			questionResponses[i].User = &dto.QuestionUserResponse{
-				Username:    question.User.Username,
-				DisplayName: question.User.DisplayName,
-				AvatarURL:   question.User.AvatarURL,
-			}
-		}
+	if filter.Sort == "" {
+		filter.Sort = repository.QuestionSortNewest
 	}
 
-	hasNext := int64(page*limit) < totalCount
-
-	return &dto.QuestionsResponse{
-		Questions:  questionResponses,
-		TotalCount: totalCount,
-		Page:       page,
-		Limit:      limit,
-		HasNext:    hasNext,
-	}, nil
-}
-
-// GetQuestionsByTag retrieves questions filtered by tag with pagination
-func (s *QuestionService) GetQuestionsByTag(tag string, page, limit int) (*dto.QuestionsResponse, error) {
-	if page < 1 {
-		page = 1
+	var err error
+	if filter.Since, err = parseTimeParam(query.Since, false); err != nil {
+		return nil, fmt.Errorf("validation failed: since %w", err)
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+	if filter.Until, err = parseTimeParam(query.Until, true); err != nil {
+		return nil, fmt.Errorf("validation failed: until %w", err)
 	}
 
-	questions, totalCount, err := s.questionRepo.GetByTag(tag, page, limit)
+	questions, totalCount, err := s.questionRepo.List(filter, page, limit)
 	if err != nil {
-		logger.Error("Failed to retrieve questions by tag", "tag", tag, "error", err)
-		return nil, fmt.Errorf("failed to retrieve questions by tag: %w", err)
+		logger.Error("Failed to retrieve questions", "error", err)
+		return nil, fmt.Errorf("failed to retrieve questions: %w", err)
 	}
 
 	// Convert to response format
@@ -181,10 +222,19 @@ func (s *QuestionService) GetQuestionsByTag(tag string, page, limit int) (*dto.Q
 			Title:        question.Title,
 			Content:      question.Content,
 			Tags:         tags,
+			CarMake:      question.CarMake,
+			CarModel:     question.CarModel,
+			CarYear:      question.CarYear,
 			IsAnswered:   question.IsAnswered,
+			Score:        question.Score,
+			Status:       question.Status,
+			StatusReason: question.StatusReason,
+			CanonicalID:  question.CanonicalID,
 			CommentCount: question.CommentCount,
 			LikeCount:    question.LikeCount,
 			AnswerCount:  question.AnswerCount,
+			ViewCount:    question.ViewCount,
+			Bounty:       bountyResponse(question.Bounty),
 			CreatedAt:    question.CreatedAt,
 			UpdatedAt:    question.UpdatedAt,
 		}
@@ -209,8 +259,13 @@ func (s *QuestionService) GetQuestionsByTag(tag string, page, limit int) (*dto.Q
 	}, nil
 }
 
-// UpdateQuestion updates a question
-func (s *QuestionService) UpdateQuestion(id string, req dto.UpdateQuestionRequest) (*dto.QuestionResponse, error) {
+// GetQuestionsByTag retrieves questions filtered by tag with pagination
+func (s *QuestionService) GetQuestionsByTag(tag string, page, limit int, viewerID string) (*dto.QuestionsResponse, error) {
+	return s.GetAllQuestions(dto.ListQuestionsQuery{Page: page, Limit: limit, Tags: tag}, viewerID)
+}
+
+// UpdateQuestion updates a question on behalf of its author or a moderator
+func (s *QuestionService) UpdateQuestion(id string, req dto.UpdateQuestionRequest, actor policy.Actor) (*dto.QuestionResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
@@ -220,6 +275,20 @@ func (s *QuestionService) UpdateQuestion(id string, req dto.UpdateQuestionReques
 		return nil, fmt.Errorf("question not found: %w", err)
 	}
 
+	if err := s.policy.RequireAuthorOrModerator(actor, question.UserID, "update this question"); err != nil {
+		// Users with the edit tags privilege may retag other users' questions, but nothing more
+		if !errors.Is(err, policy.ErrForbidden) || !onlyTagsChanged(req) {
+			return nil, err
+		}
+		allowed, privErr := s.reputationService.HasPrivilege(actor.UserID, reputationDomain.PrivilegeEditTags)
+		if privErr != nil {
+			return nil, fmt.Errorf("failed to check edit tags privilege: %w", privErr)
+		}
+		if !allowed {
+			return nil, err
+		}
+	}
+
 	// Update fields if provided
 	if req.Title != "" {
 		question.Title = req.Title
@@ -230,6 +299,15 @@ func (s *QuestionService) UpdateQuestion(id string, req dto.UpdateQuestionReques
 	if req.Tags != "" {
 		question.Tags = req.Tags
 	}
+	if req.CarMake != "" {
+		question.CarMake = strings.TrimSpace(req.CarMake)
+	}
+	if req.CarModel != "" {
+		question.CarModel = strings.TrimSpace(req.CarModel)
+	}
+	if req.CarYear != 0 {
+		question.CarYear = req.CarYear
+	}
 
 	if err := s.questionRepo.Update(question); err != nil {
 		logger.Error("Failed to update question", "question_id", id, "error", err)
@@ -247,23 +325,72 @@ func (s *QuestionService) UpdateQuestion(id string, req dto.UpdateQuestionReques
 		Title:        question.Title,
 		Content:      question.Content,
 		Tags:         tags,
+		CarMake:      question.CarMake,
+		CarModel:     question.CarModel,
+		CarYear:      question.CarYear,
 		IsAnswered:   question.IsAnswered,
+		Score:        question.Score,
+		Status:       question.Status,
+		StatusReason: question.StatusReason,
+		CanonicalID:  question.CanonicalID,
 		CommentCount: question.CommentCount,
 		LikeCount:    question.LikeCount,
 		AnswerCount:  question.AnswerCount,
+		ViewCount:    question.ViewCount,
+		Bounty:       bountyResponse(question.Bounty),
 		CreatedAt:    question.CreatedAt,
 		UpdatedAt:    question.UpdatedAt,
 	}, nil
 }
 
-// DeleteQuestion deletes a question
-func (s *QuestionService) DeleteQuestion(id string) error {
+// checkContentVisible reports a question or answer by a private account the viewer does not
+// follow as not found, so its existence is not revealed
+func checkContentVisible(followService *userService.FollowService, contentType, authorID, viewerID string) error {
+	visible, err := followService.CanViewContent(viewerID, authorID)
+	if err != nil {
+		return fmt.Errorf("failed to check %s visibility: %w", contentType, err)
+	}
+	if !visible {
+		return fmt.Errorf("%s not found", contentType)
+	}
+	return nil
+}
+
+// onlyTagsChanged reports whether an update changes the tags and nothing else
+func onlyTagsChanged(req dto.UpdateQuestionRequest) bool {
+	return req.Tags != "" && req.Title == "" && req.Content == "" &&
+		req.CarMake == "" && req.CarModel == "" && req.CarYear == 0
+}
+
+// DeleteQuestion deletes a question on behalf of its author or a moderator. Questions with an open
+// bounty are kept until it is settled. A moderator may remove the question as spam, which also
+// penalizes its author.
+func (s *QuestionService) DeleteQuestion(id string, actor policy.Actor, asSpam bool) error {
+	question, err := s.questionRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("question not found: %w", err)
+	}
+	if asSpam {
+		if err := s.policy.RequireModerator(actor, "remove content as spam"); err != nil {
+			return err
+		}
+	} else if err := s.policy.RequireAuthorOrModerator(actor, question.UserID, "delete this question"); err != nil {
+		return err
+	}
+	if question.Bounty != nil && question.Bounty.Status == domain.BountyStatusOpen {
+		return ErrOpenBountyDelete
+	}
+
 	if err := s.questionRepo.Delete(id); err != nil {
 		logger.Error("Failed to delete question", "question_id", id, "error", err)
 		return fmt.Errorf("failed to delete question: %w", err)
 	}
 
 	logger.Info("Question deleted successfully", "question_id", id)
+
+	if asSpam {
+		s.eventBus.Publish("ContentRemovedAsSpam", events.NewContentRemovedAsSpam("question", id, question.UserID, actor.UserID))
+	}
 	return nil
 }
 
@@ -284,4 +411,26 @@ func parseTags(tags string) []string {
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+
+// parseTimeParam parses an RFC 3339 timestamp or a YYYY-MM-DD date. A date given as an end of
+// range covers the whole day, so it is moved to the start of the next day.
+func parseTimeParam(value string, end bool) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
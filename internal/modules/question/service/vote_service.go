@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/question/domain"
+	"github.com/topboyasante/pitstop/internal/modules/question/dto"
+	"github.com/topboyasante/pitstop/internal/modules/question/repository"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+)
+
+// ErrSelfVote is returned when a user votes on their own question or answer
+var ErrSelfVote = errors.New("you cannot vote on your own content")
+
+// VoteService handles voting on questions and answers
+type VoteService struct {
+	voteRepo     *repository.VoteRepository
+	questionRepo *repository.QuestionRepository
+	answerRepo   *repository.AnswerRepository
+	blockService *userService.BlockService
+	validator    *validator.Validate
+	eventBus     *events.EventBus
+}
+
+// NewVoteService creates a new vote service instance
+func NewVoteService(voteRepo *repository.VoteRepository, questionRepo *repository.QuestionRepository, answerRepo *repository.AnswerRepository, blockService *userService.BlockService, validator *validator.Validate, eventBus *events.EventBus) *VoteService {
+	return &VoteService{
+		voteRepo:     voteRepo,
+		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		blockService: blockService,
+		validator:    validator,
+		eventBus:     eventBus,
+	}
+}
+
+// VoteOnQuestion casts or changes the user's vote on a question
+func (s *VoteService) VoteOnQuestion(questionID, userID string, req dto.VoteRequest) (*dto.VoteResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	authorID, err := s.questionAuthor(questionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setVote(domain.VotableTypeQuestion, questionID, authorID, userID, req.Value)
+}
+
+// RetractQuestionVote removes the user's vote on a question
+func (s *VoteService) RetractQuestionVote(questionID, userID string) (*dto.VoteResponse, error) {
+	authorID, err := s.questionAuthor(questionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setVote(domain.VotableTypeQuestion, questionID, authorID, userID, 0)
+}
+
+// VoteOnAnswer casts or changes the user's vote on an answer
+func (s *VoteService) VoteOnAnswer(questionID, answerID, userID string, req dto.VoteRequest) (*dto.VoteResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	authorID, err := s.answerAuthor(questionID, answerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setVote(domain.VotableTypeAnswer, answerID, authorID, userID, req.Value)
+}
+
+// RetractAnswerVote removes the user's vote on an answer
+func (s *VoteService) RetractAnswerVote(questionID, answerID, userID string) (*dto.VoteResponse, error) {
+	authorID, err := s.answerAuthor(questionID, answerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.setVote(domain.VotableTypeAnswer, answerID, authorID, userID, 0)
+}
+
+// questionAuthor returns the author of a question
+func (s *VoteService) questionAuthor(questionID string) (string, error) {
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil {
+		return "", fmt.Errorf("question not found: %w", err)
+	}
+	return question.UserID, nil
+}
+
+// answerAuthor returns the author of an answer after checking it belongs to the question
+func (s *VoteService) answerAuthor(questionID, answerID string) (string, error) {
+	answer, err := s.answerRepo.GetByID(answerID)
+	if err != nil {
+		return "", fmt.Errorf("answer not found: %w", err)
+	}
+	if answer.QuestionID != questionID {
+		return "", fmt.Errorf("answer does not belong to this question")
+	}
+	return answer.UserID, nil
+}
+
+// setVote records the vote, publishes VoteCast when it changed anything and returns the new score
+func (s *VoteService) setVote(votableType, votableID, authorID, userID string, value int) (*dto.VoteResponse, error) {
+	if authorID == userID {
+		return nil, ErrSelfVote
+	}
+	if value != 0 {
+		if err := s.blockService.CheckInteraction(userID, authorID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous, score, err := s.voteRepo.SetVote(userID, votableType, votableID, value)
+	if err != nil {
+		logger.Error("Failed to record vote", "votable_type", votableType, "votable_id", votableID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to record vote: %w", err)
+	}
+
+	if previous != value {
+		s.eventBus.Publish("VoteCast", events.NewVoteCast(votableType, votableID, authorID, userID, value, previous))
+	}
+
+	logger.Info("Vote recorded", "votable_type", votableType, "votable_id", votableID, "user_id", userID, "value", value, "score", score)
+	return &dto.VoteResponse{
+		Score:    score,
+		UserVote: value,
+	}, nil
+}
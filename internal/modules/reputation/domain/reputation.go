@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+)
+
+// ReputationEntry is one change to a user's reputation. The entries of a user add up to the
+// reputation shown on their profile. SourceType and SourceID name the question, answer or
+// bounty the change came from, and ActorID the user whose action caused it, when there is one.
+type ReputationEntry struct {
+	ID         string    `gorm:"primarykey" json:"id"`
+	UserID     string    `gorm:"not null;index:idx_reputation_user_created,priority:1" json:"user_id"`
+	Amount     int       `gorm:"not null" json:"amount"`
+	Reason     string    `gorm:"size:40;not null" json:"reason"`
+	SourceType string    `gorm:"size:20;not null" json:"source_type"`
+	SourceID   string    `gorm:"not null" json:"source_id"`
+	ActorID    *string   `json:"actor_id,omitempty"`
+	CreatedAt  time.Time `gorm:"index:idx_reputation_user_created,priority:2" json:"created_at"`
+}
+
+// TableName specifies the table name for the ReputationEntry model
+func (ReputationEntry) TableName() string {
+	return "reputation_entries"
+}
+
+// Reputation change reasons
+const (
+	ReasonQuestionUpvoted       = "question_upvoted"
+	ReasonQuestionDownvoted     = "question_downvoted"
+	ReasonQuestionVoteRetracted = "question_vote_retracted"
+	ReasonAnswerUpvoted         = "answer_upvoted"
+	ReasonAnswerDownvoted       = "answer_downvoted"
+	ReasonAnswerVoteRetracted   = "answer_vote_retracted"
+	ReasonDownvoteCast          = "downvote_cast"
+	ReasonDownvoteRetracted     = "downvote_retracted"
+	ReasonAnswerAccepted        = "answer_accepted"
+	ReasonAcceptedAnswer        = "accepted_answer"
+	ReasonAnswerUnaccepted      = "answer_unaccepted"
+	ReasonSpamRemoved           = "spam_removed"
+	ReasonBountyPlaced          = "bounty_placed"
+	ReasonBountyAwarded         = "bounty_awarded"
+)
+
+// Privileges unlocked by reputation. Moderators and admins hold every privilege.
+const (
+	PrivilegeEditTags   = "edit_tags"
+	PrivilegeCloseVote  = "close_vote"
+	PrivilegeSkipReview = "skip_review"
+)
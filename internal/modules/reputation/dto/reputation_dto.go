@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+)
+
+// PrivilegeResponse describes a privilege and whether the user has unlocked it
+type PrivilegeResponse struct {
+	Name      string `json:"name"`
+	Threshold int    `json:"threshold"`
+	Unlocked  bool   `json:"unlocked"`
+}
+
+// ReputationResponse is a user's reputation total and the privileges it unlocks
+type ReputationResponse struct {
+	UserID     string              `json:"user_id"`
+	Reputation int                 `json:"reputation"`
+	Privileges []PrivilegeResponse `json:"privileges"`
+}
+
+// ReputationEntryResponse is one change in a user's reputation history
+type ReputationEntryResponse struct {
+	ID         string    `json:"id"`
+	Amount     int       `json:"amount"`
+	Reason     string    `json:"reason"`
+	SourceType string    `json:"source_type"`
+	SourceID   string    `json:"source_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReputationHistoryResponse is a page of a user's reputation history, most recent first
+type ReputationHistoryResponse struct {
+	Entries    []ReputationEntryResponse `json:"entries"`
+	TotalCount int64                     `json:"total_count"`
+	Page       int                       `json:"page"`
+	Limit      int                       `json:"limit"`
+	HasNext    bool                      `json:"has_next"`
+}
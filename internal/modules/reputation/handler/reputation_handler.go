@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/service"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+)
+
+// ReputationHandler handles HTTP requests for reputation
+type ReputationHandler struct {
+	reputationService *service.ReputationService
+}
+
+// NewReputationHandler creates a new reputation handler instance
+func NewReputationHandler(reputationService *service.ReputationService) *ReputationHandler {
+	return &ReputationHandler{
+		reputationService: reputationService,
+	}
+}
+
+// GetReputation retrieves a user's reputation and privileges
+// @Summary Get a user's reputation
+// @Description Retrieve a user's reputation total and the privileges it unlocks. Moderators and admins hold every privilege.
+// @Tags reputation
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/{user_id}/reputation [get]
+func (h *ReputationHandler) GetReputation(c *fiber.Ctx) error {
+	userID := c.Params("user_id")
+	if strings.TrimSpace(userID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
+	}
+
+	reputation, err := h.reputationService.GetReputation(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error("Failed to get reputation", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve reputation")
+	}
+
+	return response.SuccessJSON(c, reputation, "Reputation retrieved successfully")
+}
+
+// GetReputationHistory retrieves a page of a user's reputation changes
+// @Summary Get a user's reputation history
+// @Description Retrieve the ledger of a user's reputation changes, most recent first
+// @Tags reputation
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/{user_id}/reputation/history [get]
+func (h *ReputationHandler) GetReputationHistory(c *fiber.Ctx) error {
+	userID := c.Params("user_id")
+	if strings.TrimSpace(userID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	viewerID, _ := c.Locals("userID").(string)
+	history, err := h.reputationService.GetReputationHistory(userID, viewerID, page, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return response.NotFoundJSON(c, "User")
+		}
+		if errors.Is(err, userService.ErrPrivateAccount) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "PRIVATE_ACCOUNT", "This account is private", "")
+		}
+		logger.Error("Failed to get reputation history", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve reputation history")
+	}
+
+	meta := &response.MetaInfo{
+		Page:    history.Page,
+		Limit:   history.Limit,
+		Total:   history.TotalCount,
+		HasNext: history.HasNext,
+	}
+
+	return response.SuccessJSONWithMeta(c, history.Entries, "Reputation history retrieved successfully", meta)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/domain"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"gorm.io/gorm"
+)
+
+// ReputationRepository handles reputation data operations
+type ReputationRepository struct {
+	db *gorm.DB
+}
+
+// NewReputationRepository creates a new reputation repository instance
+func NewReputationRepository(db *gorm.DB) *ReputationRepository {
+	return &ReputationRepository{db: db}
+}
+
+// Record stores reputation entries and adds their amounts to the users' reputation in one
+// transaction. Entries with a zero amount are skipped.
+func (r *ReputationRepository) Record(entries ...domain.ReputationEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.RecordTx(tx, entries...)
+	})
+}
+
+// RecordTx stores reputation entries and adds their amounts to the users' reputation inside
+// the caller's transaction. Entries with a zero amount are skipped.
+func (r *ReputationRepository) RecordTx(tx *gorm.DB, entries ...domain.ReputationEntry) error {
+	for _, entry := range entries {
+		if entry.Amount == 0 {
+			continue
+		}
+		if entry.ID == "" {
+			entry.ID = uuid.NewString()
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&userDomain.User{}).Where("id = ?", entry.UserID).
+			UpdateColumn("reputation", gorm.Expr("reputation + ?", entry.Amount)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SpendTx deducts a negative entry from the user's reputation inside the caller's transaction,
+// provided the user has at least that much. It reports false, storing nothing, when they do not.
+func (r *ReputationRepository) SpendTx(tx *gorm.DB, entry domain.ReputationEntry) (bool, error) {
+	result := tx.Model(&userDomain.User{}).
+		Where("id = ? AND reputation >= ?", entry.UserID, -entry.Amount).
+		UpdateColumn("reputation", gorm.Expr("reputation + ?", entry.Amount))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if entry.ID == "" {
+		entry.ID = uuid.NewString()
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetUser returns the user with their reputation and role
+func (r *ReputationRepository) GetUser(userID string) (*userDomain.User, error) {
+	var user userDomain.User
+	if err := r.db.Select("id", "reputation", "role").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetHistory returns a page of a user's reputation entries, most recent first
+func (r *ReputationRepository) GetHistory(userID string, page, limit int) ([]domain.ReputationEntry, int64, error) {
+	var entries []domain.ReputationEntry
+	var totalCount int64
+
+	if err := r.db.Model(&domain.ReputationEntry{}).Where("user_id = ?", userID).Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, totalCount, nil
+}
+
+// ExportUser returns a user's reputation history, for their personal data export
+func (r *ReputationRepository) ExportUser(userID string) (map[string]interface{}, error) {
+	var entries []domain.ReputationEntry
+	if err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"reputation.json": entries,
+	}, nil
+}
+
+// PurgeUser deletes a user's reputation history and removes them as the actor of other users'
+// entries. It runs inside the account deletion transaction.
+func (r *ReputationRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.ReputationEntry{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&domain.ReputationEntry{}).
+		Where("actor_id = ?", userID).
+		Update("actor_id", nil).Error
+}
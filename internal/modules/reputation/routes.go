@@ -0,0 +1,19 @@
+package reputation
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/handler"
+)
+
+// RegisterRoutes registers all reputation-related routes
+func RegisterRoutes(router fiber.Router, reputationHandler *handler.ReputationHandler) {
+	users := router.Group("/users")
+	optionalJWT := middleware.OptionalJWTMiddleware(config.Get())
+
+	// Reputation is public, like the profile that shows it. The history shows the user's
+	// activity, so a private account's history is only shown to its followers.
+	users.Get("/:user_id/reputation", reputationHandler.GetReputation)
+	users.Get("/:user_id/reputation/history", optionalJWT, reputationHandler.GetReputationHistory)
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/domain"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/dto"
+	"github.com/topboyasante/pitstop/internal/modules/reputation/repository"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserNotFound is returned when the user whose reputation is asked for does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInsufficientReputation is returned when a user spends more reputation than they have
+	ErrInsufficientReputation = errors.New("insufficient reputation")
+)
+
+// Source types of reputation entries. They match the votable types of the question module.
+const (
+	sourceQuestion = "question"
+	sourceAnswer   = "answer"
+)
+
+// ReputationService credits and debits reputation in response to events from other modules
+// and decides which privileges a user holds
+type ReputationService struct {
+	config         *config.Config
+	reputationRepo *repository.ReputationRepository
+	followService  *userService.FollowService
+}
+
+// NewReputationService creates a new reputation service instance
+func NewReputationService(config *config.Config, reputationRepo *repository.ReputationRepository, followService *userService.FollowService) *ReputationService {
+	return &ReputationService{
+		config:         config,
+		reputationRepo: reputationRepo,
+		followService:  followService,
+	}
+}
+
+// SubscribeToEvents registers the handlers that turn votes, accepted answers and spam removals
+// into reputation changes
+func (s *ReputationService) SubscribeToEvents(eventBus *events.EventBus) {
+	eventBus.Subscribe("VoteCast", func(event events.Event) {
+		s.onVoteCast(event.(*events.VoteCast))
+	})
+	eventBus.Subscribe("AnswerAccepted", func(event events.Event) {
+		s.onAnswerAccepted(event.(*events.AnswerAccepted))
+	})
+	eventBus.Subscribe("AnswerUnaccepted", func(event events.Event) {
+		s.onAnswerUnaccepted(event.(*events.AnswerUnaccepted))
+	})
+	eventBus.Subscribe("ContentRemovedAsSpam", func(event events.Event) {
+		s.onContentRemovedAsSpam(event.(*events.ContentRemovedAsSpam))
+	})
+}
+
+// voteAmount is what a vote of the given value is worth to the author of the content
+func (s *ReputationService) voteAmount(votableType string, value int) int {
+	switch {
+	case value < 0:
+		return s.config.Reputation.Downvoted
+	case value > 0 && votableType == sourceAnswer:
+		return s.config.Reputation.AnswerUpvoted
+	case value > 0:
+		return s.config.Reputation.QuestionUpvoted
+	}
+	return 0
+}
+
+// downvoteCost is what casting a vote of the given value costs the voter. Only downvotes on
+// answers cost anything.
+func (s *ReputationService) downvoteCost(votableType string, value int) int {
+	if votableType == sourceAnswer && value < 0 {
+		return s.config.Reputation.DownvoteCast
+	}
+	return 0
+}
+
+// voteReason names the author's entry for a vote that changed to value
+func voteReason(votableType string, value int) string {
+	switch {
+	case votableType == sourceAnswer && value > 0:
+		return domain.ReasonAnswerUpvoted
+	case votableType == sourceAnswer && value < 0:
+		return domain.ReasonAnswerDownvoted
+	case votableType == sourceAnswer:
+		return domain.ReasonAnswerVoteRetracted
+	case value > 0:
+		return domain.ReasonQuestionUpvoted
+	case value < 0:
+		return domain.ReasonQuestionDownvoted
+	}
+	return domain.ReasonQuestionVoteRetracted
+}
+
+// onVoteCast applies the difference between the new vote and the one it replaced, so changing
+// or retracting a vote reverses what the earlier vote was worth
+func (s *ReputationService) onVoteCast(vote *events.VoteCast) {
+	voterID := vote.VoterID
+	entries := []domain.ReputationEntry{{
+		UserID:     vote.AuthorID,
+		Amount:     s.voteAmount(vote.VotableType, vote.Value) - s.voteAmount(vote.VotableType, vote.PreviousValue),
+		Reason:     voteReason(vote.VotableType, vote.Value),
+		SourceType: vote.VotableType,
+		SourceID:   vote.VotableID,
+		ActorID:    &voterID,
+	}}
+
+	cost := s.downvoteCost(vote.VotableType, vote.Value) - s.downvoteCost(vote.VotableType, vote.PreviousValue)
+	if cost != 0 {
+		reason := domain.ReasonDownvoteCast
+		if vote.Value >= 0 {
+			reason = domain.ReasonDownvoteRetracted
+		}
+		entries = append(entries, domain.ReputationEntry{
+			UserID:     vote.VoterID,
+			Amount:     cost,
+			Reason:     reason,
+			SourceType: vote.VotableType,
+			SourceID:   vote.VotableID,
+		})
+	}
+
+	s.record("vote", entries...)
+}
+
+// onAnswerAccepted credits the answer author and the question author. Accepting your own
+// answer earns nothing.
+func (s *ReputationService) onAnswerAccepted(accepted *events.AnswerAccepted) {
+	if accepted.AnswerAuthorID == accepted.QuestionAuthorID {
+		return
+	}
+
+	questionAuthorID := accepted.QuestionAuthorID
+	s.record("accepted answer",
+		domain.ReputationEntry{
+			UserID:     accepted.AnswerAuthorID,
+			Amount:     s.config.Reputation.AnswerAccepted,
+			Reason:     domain.ReasonAnswerAccepted,
+			SourceType: sourceAnswer,
+			SourceID:   accepted.AnswerID,
+			ActorID:    &questionAuthorID,
+		},
+		domain.ReputationEntry{
+			UserID:     accepted.QuestionAuthorID,
+			Amount:     s.config.Reputation.AcceptedAnswer,
+			Reason:     domain.ReasonAcceptedAnswer,
+			SourceType: sourceAnswer,
+			SourceID:   accepted.AnswerID,
+		},
+	)
+}
+
+// onAnswerUnaccepted takes back what accepting the answer earned
+func (s *ReputationService) onAnswerUnaccepted(unaccepted *events.AnswerUnaccepted) {
+	if unaccepted.AnswerAuthorID == unaccepted.QuestionAuthorID {
+		return
+	}
+
+	questionAuthorID := unaccepted.QuestionAuthorID
+	s.record("unaccepted answer",
+		domain.ReputationEntry{
+			UserID:     unaccepted.AnswerAuthorID,
+			Amount:     -s.config.Reputation.AnswerAccepted,
+			Reason:     domain.ReasonAnswerUnaccepted,
+			SourceType: sourceAnswer,
+			SourceID:   unaccepted.AnswerID,
+			ActorID:    &questionAuthorID,
+		},
+		domain.ReputationEntry{
+			UserID:     unaccepted.QuestionAuthorID,
+			Amount:     -s.config.Reputation.AcceptedAnswer,
+			Reason:     domain.ReasonAnswerUnaccepted,
+			SourceType: sourceAnswer,
+			SourceID:   unaccepted.AnswerID,
+		},
+	)
+}
+
+// onContentRemovedAsSpam penalizes the author of content a moderator removed as spam
+func (s *ReputationService) onContentRemovedAsSpam(removed *events.ContentRemovedAsSpam) {
+	moderatorID := removed.ModeratorID
+	s.record("spam removal", domain.ReputationEntry{
+		UserID:     removed.AuthorID,
+		Amount:     s.config.Reputation.SpamRemoved,
+		Reason:     domain.ReasonSpamRemoved,
+		SourceType: removed.ContentType,
+		SourceID:   removed.ContentID,
+		ActorID:    &moderatorID,
+	})
+}
+
+// record stores entries, leaving out those for the deleted user placeholder. Events are handled
+// in the background, so failures are logged rather than returned.
+func (s *ReputationService) record(cause string, entries ...domain.ReputationEntry) {
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.UserID != "" && entry.UserID != userDomain.DeletedUserID {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == 0 {
+		return
+	}
+
+	if err := s.reputationRepo.Record(kept...); err != nil {
+		logger.Error("Failed to record reputation change", "cause", cause, "error", err)
+	}
+}
+
+// Spend takes amount from a user's reputation inside tx, so that it is given back if tx rolls
+// back. It fails with ErrInsufficientReputation when the user has less than amount.
+func (s *ReputationService) Spend(tx *gorm.DB, userID string, amount int, reason, sourceType, sourceID string) error {
+	spent, err := s.reputationRepo.SpendTx(tx, domain.ReputationEntry{
+		UserID:     userID,
+		Amount:     -amount,
+		Reason:     reason,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to spend reputation: %w", err)
+	}
+	if !spent {
+		return ErrInsufficientReputation
+	}
+	return nil
+}
+
+// Grant gives amount to a user's reputation inside tx. The deleted user placeholder is skipped.
+func (s *ReputationService) Grant(tx *gorm.DB, userID string, amount int, reason, sourceType, sourceID, actorID string) error {
+	if userID == "" || userID == userDomain.DeletedUserID {
+		return nil
+	}
+
+	entry := domain.ReputationEntry{
+		UserID:     userID,
+		Amount:     amount,
+		Reason:     reason,
+		SourceType: sourceType,
+		SourceID:   sourceID,
+	}
+	if actorID != "" && actorID != userDomain.DeletedUserID {
+		entry.ActorID = &actorID
+	}
+
+	if err := s.reputationRepo.RecordTx(tx, entry); err != nil {
+		return fmt.Errorf("failed to grant reputation: %w", err)
+	}
+	return nil
+}
+
+// privilegeThresholds returns the reputation each privilege unlocks at, in ascending order
+func (s *ReputationService) privilegeThresholds() []dto.PrivilegeResponse {
+	return []dto.PrivilegeResponse{
+		{Name: domain.PrivilegeEditTags, Threshold: s.config.Reputation.EditTagsThreshold},
+		{Name: domain.PrivilegeCloseVote, Threshold: s.config.Reputation.CloseVoteThreshold},
+		{Name: domain.PrivilegeSkipReview, Threshold: s.config.Reputation.SkipReviewThreshold},
+	}
+}
+
+// hasPrivilege reports whether a user's role or reputation grants the privilege
+func (s *ReputationService) hasPrivilege(user *userDomain.User, privilege dto.PrivilegeResponse) bool {
+	if policy.IsModeratorRole(user.Role) {
+		return true
+	}
+	return user.Reputation >= privilege.Threshold
+}
+
+// HasPrivilege reports whether a user holds a privilege. Other modules use it to gate actions
+// such as voting to close a question.
+func (s *ReputationService) HasPrivilege(userID, privilege string) (bool, error) {
+	user, err := s.reputationRepo.GetUser(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrUserNotFound
+		}
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, candidate := range s.privilegeThresholds() {
+		if candidate.Name == privilege {
+			return s.hasPrivilege(user, candidate), nil
+		}
+	}
+	return false, fmt.Errorf("unknown privilege: %s", privilege)
+}
+
+// GetReputation returns a user's reputation and the privileges it unlocks
+func (s *ReputationService) GetReputation(userID string) (*dto.ReputationResponse, error) {
+	user, err := s.reputationRepo.GetUser(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	privileges := s.privilegeThresholds()
+	for i := range privileges {
+		privileges[i].Unlocked = s.hasPrivilege(user, privileges[i])
+	}
+
+	return &dto.ReputationResponse{
+		UserID:     user.ID,
+		Reputation: user.Reputation,
+		Privileges: privileges,
+	}, nil
+}
+
+// GetReputationHistory returns a page of a user's reputation changes, most recent first
+func (s *ReputationService) GetReputationHistory(userID, viewerID string, page, limit int) (*dto.ReputationHistoryResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if _, err := s.reputationRepo.GetUser(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// The history shows the user's activity, which a private account only shares with followers
+	visible, err := s.followService.CanViewContent(viewerID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reputation visibility: %w", err)
+	}
+	if !visible {
+		return nil, userService.ErrPrivateAccount
+	}
+
+	entries, totalCount, err := s.reputationRepo.GetHistory(userID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reputation history: %w", err)
+	}
+
+	responses := make([]dto.ReputationEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = dto.ReputationEntryResponse{
+			ID:         entry.ID,
+			Amount:     entry.Amount,
+			Reason:     entry.Reason,
+			SourceType: entry.SourceType,
+			SourceID:   entry.SourceID,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+
+	return &dto.ReputationHistoryResponse{
+		Entries:    responses,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		HasNext:    int64(page*limit) < totalCount,
+	}, nil
+}
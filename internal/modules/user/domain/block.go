@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+)
+
+// Block stops two users from interacting. It is directional in who can undo it, but its
+// effect applies both ways:
+// - BlockerID is the user who created the block and the only one who can remove it
+// - BlockedID is the user being blocked
+//
+// While a block exists neither user can follow, comment on or reply to the other,
+// and each one's content is hidden from the other.
+type Block struct {
+	ID        string    `gorm:"primarykey" json:"id"`
+	BlockerID string    `gorm:"not null;index:idx_blocker_blocked,unique" json:"blocker_id" validate:"required"`
+	BlockedID string    `gorm:"not null;index:idx_blocker_blocked,unique;index" json:"blocked_id" validate:"required"`
+	Blocked   *User     `gorm:"foreignKey:BlockedID;references:ID" json:"blocked,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Block model
+func (Block) TableName() string {
+	return "user_blocks"
+}
+
+// Mute hides the muted user's content from the muter only. The muted user is not told
+// and can still follow and reply to the muter.
+type Mute struct {
+	ID        string    `gorm:"primarykey" json:"id"`
+	MuterID   string    `gorm:"not null;index:idx_muter_muted,unique" json:"muter_id" validate:"required"`
+	MutedID   string    `gorm:"not null;index:idx_muter_muted,unique" json:"muted_id" validate:"required"`
+	Muted     *User     `gorm:"foreignKey:MutedID;references:ID" json:"muted,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Mute model
+func (Mute) TableName() string {
+	return "user_mutes"
+}
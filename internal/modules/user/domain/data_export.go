@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// DataExport tracks a personal data export from request to download. The archive itself
+// lives in blob storage; only the hash of its download token is stored.
+type DataExport struct {
+	ID                string     `gorm:"primarykey" json:"id"`
+	UserID            string     `gorm:"not null;index" json:"user_id"`
+	Status            string     `gorm:"not null;size:20" json:"status"`
+	BlobKey           string     `gorm:"size:255" json:"-"`
+	DownloadTokenHash string     `gorm:"size:64" json:"-"`
+	Size              int64      `json:"size"`
+	Error             string     `gorm:"type:text" json:"-"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt         *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the DataExport model
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// Data export statuses
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportReady      = "ready"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
@@ -16,13 +16,15 @@ import (
 // This allows us to query:
 // - Alice's following list (where FollowerID = Alice's ID)
 // - Bob's followers list (where FollowingID = Bob's ID)
+//
+// Both lists are paged newest first, so each side is also indexed together with CreatedAt.
 type Follow struct {
 	ID          string    `gorm:"primarykey" json:"id"`
-	FollowerID  string    `gorm:"not null;index:idx_follower_following,unique" json:"follower_id" validate:"required"`
-	FollowingID string    `gorm:"not null;index:idx_follower_following,unique" json:"following_id" validate:"required"`
+	FollowerID  string    `gorm:"not null;index:idx_follower_following,unique;index:idx_follower_created,priority:1" json:"follower_id" validate:"required"`
+	FollowingID string    `gorm:"not null;index:idx_follower_following,unique;index:idx_following_created,priority:1" json:"following_id" validate:"required"`
 	Follower    *User     `gorm:"foreignKey:FollowerID;references:ID" json:"follower,omitempty"`
 	Following   *User     `gorm:"foreignKey:FollowingID;references:ID" json:"following,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	CreatedAt   time.Time `gorm:"index:idx_follower_created,priority:2;index:idx_following_created,priority:2" json:"created_at"`
 }
 
 // TableName specifies the table name for the Follow model
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+)
+
+// FollowRequest is a pending request to follow a private account. Approving it
+// creates the Follow and removes the request; rejecting or cancelling it only
+// removes the request.
+type FollowRequest struct {
+	ID          string    `gorm:"primarykey" json:"id"`
+	RequesterID string    `gorm:"not null;index:idx_requester_target,unique" json:"requester_id"`
+	TargetID    string    `gorm:"not null;index:idx_requester_target,unique;index" json:"target_id"`
+	Requester   *User     `gorm:"foreignKey:RequesterID;references:ID" json:"requester,omitempty"`
+	Target      *User     `gorm:"foreignKey:TargetID;references:ID" json:"target,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the FollowRequest model
+func (FollowRequest) TableName() string {
+	return "follow_requests"
+}
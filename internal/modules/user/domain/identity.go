@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+)
+
+// UserIdentity links a login provider account to a Pitstop user.
+// A user can sign in with several providers, so identities are kept
+// apart from the user row:
+// - Provider/ProviderID identify the account at the provider and are unique together
+// - Email is the address the provider reported when the identity was linked
+//
+// Example: If Alice signs up with Google and later links GitHub, she has
+// two identities pointing at the same UserID.
+type UserIdentity struct {
+	ID            string    `gorm:"primarykey" json:"id"`
+	UserID        string    `gorm:"not null;index" json:"user_id" validate:"required"`
+	Provider      string    `gorm:"not null;size:50;uniqueIndex:idx_identity_provider" json:"provider" validate:"required,oneof=google facebook github email"`
+	ProviderID    string    `gorm:"not null;size:100;uniqueIndex:idx_identity_provider" json:"provider_id" validate:"required"`
+	Email         string    `gorm:"size:255" json:"email"`
+	EmailVerified bool      `gorm:"not null;default:false" json:"email_verified"`
+	User          *User     `gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -6,19 +6,28 @@ import (
 	"gorm.io/gorm"
 )
 
-// User represents a user entity
+// User represents a user entity. Provider and ProviderID record the provider the
+// account was created with; every provider a user can sign in with lives in UserIdentity.
 type User struct {
 	ID             string         `gorm:"primarykey" json:"id"`
 	ProviderID     string         `gorm:"not null;size:100;index" json:"provider_id"`
-	Provider       string         `gorm:"not null;size:50;index" json:"provider" validate:"required,oneof=google facebook github"`
+	Provider       string         `gorm:"not null;size:50;index" json:"provider" validate:"required,oneof=google facebook github email"`
 	FirstName      string         `gorm:"size:255" json:"first_name" validate:"omitempty,max=255"`
 	LastName       string         `gorm:"size:255" json:"last_name" validate:"omitempty,max=255"`
-	Username       string         `gorm:"uniqueIndex;size:100" json:"username" validate:"omitempty,min=3,max=100,alphanum"`
+	Username       string         `gorm:"uniqueIndex;size:100" json:"username" validate:"omitempty,min=3,max=100"`
 	Email          string         `gorm:"uniqueIndex;not null;size:255" json:"email" validate:"required,email,max=255"`
 	DisplayName    string         `gorm:"size:150" json:"display_name" validate:"omitempty,max=150"`
 	Bio            string         `gorm:"size:500" json:"bio" validate:"omitempty,max=500"`
 	AvatarURL      string         `gorm:"size:500" json:"avatar_url" validate:"omitempty,url,max=500"`
 	Locale         string         `gorm:"size:10" json:"locale" validate:"omitempty,max=10"`
+	Role           string         `gorm:"size:20;not null;default:user" json:"role" validate:"omitempty,oneof=user moderator admin"`
+	UsernameChangedAt *time.Time  `json:"username_changed_at,omitempty"`
+	EmailVisibility     string    `gorm:"size:20;not null;default:nobody" json:"email_visibility"`
+	FirstNameVisibility string    `gorm:"size:20;not null;default:everyone" json:"first_name_visibility"`
+	LastNameVisibility  string    `gorm:"size:20;not null;default:followers" json:"last_name_visibility"`
+	IsPrivate      bool           `gorm:"not null;default:false" json:"is_private"`
+	Reputation     int            `gorm:"not null;default:0" json:"reputation"` // Kept by the reputation module
+	DeletionRequestedAt *time.Time `gorm:"index" json:"deletion_requested_at,omitempty"`
 	FollowerCount  int64          `gorm:"-" json:"follower_count"`
 	FollowingCount int64          `gorm:"-" json:"following_count"`
 	CreatedAt      time.Time      `json:"created_at"`
@@ -26,6 +35,35 @@ type User struct {
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// User roles. Moderators and admins hold privileges over other users' content.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// The placeholder account that content is reassigned to when its author deletes their account.
+// It is created by the migrations and has no identity, so nobody can sign in as it.
+const (
+	DeletedUserID       = "00000000-0000-0000-0000-000000000000"
+	DeletedUserUsername = "deleted"
+)
+
+// Profile field visibility. Username, display name, bio and avatar are always public;
+// email, first name and last name are shown according to the user's choice.
+const (
+	VisibilityEveryone  = "everyone"
+	VisibilityFollowers = "followers"
+	VisibilityNobody    = "nobody"
+)
+
+// Default visibility of the private profile fields
+const (
+	DefaultEmailVisibility     = VisibilityNobody
+	DefaultFirstNameVisibility = VisibilityEveryone
+	DefaultLastNameVisibility  = VisibilityFollowers
+)
+
 // TableName specifies the table name for the User model
 func (User) TableName() string {
 	return "users"
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// UsernameRedirect keeps a previous username pointing at the user who gave it up,
+// so links to the old profile keep working after a rename
+type UsernameRedirect struct {
+	ID        string    `gorm:"primarykey" json:"id"`
+	Username  string    `gorm:"uniqueIndex;not null;size:100" json:"username"`
+	UserID    string    `gorm:"not null;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the UsernameRedirect model
+func (UsernameRedirect) TableName() string {
+	return "username_redirects"
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// RelatedUserResponse represents a blocked or muted user and when the relationship was created
+type RelatedUserResponse struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Since       time.Time `json:"since"`
+}
+
+// RelatedUsersResponse represents a page of blocked or muted users
+type RelatedUsersResponse struct {
+	Users      []RelatedUserResponse `json:"users"`
+	TotalCount int64                 `json:"total_count"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	HasNext    bool                  `json:"has_next"`
+}
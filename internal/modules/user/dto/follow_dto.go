@@ -4,13 +4,16 @@ import (
 	"time"
 )
 
-// FollowUserResponse represents user data in follow responses (limited fields)
+// FollowUserResponse represents user data in follow responses (limited fields). YouFollow and
+// FollowsYou relate the user to the signed-in viewer and are omitted for anonymous requests.
 type FollowUserResponse struct {
 	ID          string `json:"id"`
 	Username    string `json:"username"`
 	DisplayName string `json:"display_name"`
 	AvatarURL   string `json:"avatar_url"`
 	Bio         string `json:"bio,omitempty"`
+	YouFollow   *bool  `json:"you_follow,omitempty"`
+	FollowsYou  *bool  `json:"follows_you,omitempty"`
 }
 
 // FollowResponse represents a follow relationship in API responses
@@ -21,21 +24,63 @@ type FollowResponse struct {
 	CreatedAt time.Time           `json:"created_at"`
 }
 
-// FollowersResponse represents a list of followers
+// FollowersResponse represents a page of followers. NextCursor fetches the next page and is empty on the last one.
 type FollowersResponse struct {
 	Followers  []FollowUserResponse `json:"followers"`
 	TotalCount int64                `json:"total_count"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	HasNext    bool                 `json:"has_next"`
 }
 
-// FollowingResponse represents a list of users being followed
+// FollowingResponse represents a page of users being followed
 type FollowingResponse struct {
 	Following  []FollowUserResponse `json:"following"`
 	TotalCount int64                `json:"total_count"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	HasNext    bool                 `json:"has_next"`
 }
 
-// FollowToggleResponse represents the response after toggling a follow
+// MutualsResponse represents a page of a user's followers that the viewer also follows
+type MutualsResponse struct {
+	Mutuals    []FollowUserResponse `json:"mutuals"`
+	TotalCount int64                `json:"total_count"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+	HasNext    bool                 `json:"has_next"`
+}
+
+// FollowToggleResponse represents the response after toggling a follow. IsRequested is set
+// when the user is private and the toggle created a follow request instead of a follow.
 type FollowToggleResponse struct {
 	IsFollowing    bool  `json:"is_following"`
+	IsRequested    bool  `json:"is_requested"`
 	FollowerCount  int64 `json:"follower_count"`
 	FollowingCount int64 `json:"following_count"`
-}
\ No newline at end of file
+}
+
+// FollowRequestResponse represents a pending follow request. User is the other party:
+// the requester for incoming requests and the requested account for outgoing ones.
+type FollowRequestResponse struct {
+	ID        string             `json:"id"`
+	User      FollowUserResponse `json:"user"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// FollowRequestsResponse represents a page of follow requests
+type FollowRequestsResponse struct {
+	Requests   []FollowRequestResponse `json:"requests"`
+	TotalCount int64                   `json:"total_count"`
+	Page       int                     `json:"page"`
+	Limit      int                     `json:"limit"`
+	HasNext    bool                    `json:"has_next"`
+}
+
+// SuggestedUserResponse is a user recommended to follow. Reasons lists the signals that ranked
+// the user, strongest first: followed_by_people_you_follow, shared_tags, active, or one added by another module.
+type SuggestedUserResponse struct {
+	ID          string   `json:"id"`
+	Username    string   `json:"username"`
+	DisplayName string   `json:"display_name"`
+	AvatarURL   string   `json:"avatar_url"`
+	Bio         string   `json:"bio,omitempty"`
+	Reasons     []string `json:"reasons"`
+}
@@ -5,22 +5,49 @@ import "time"
 // CreateUserRequest represents OAuth user data
 type CreateUserRequest struct {
 	ProviderID  string `json:"provider_id" validate:"required"`
-	Provider    string `json:"provider" validate:"required,oneof=google facebook github"`
+	Provider    string `json:"provider" validate:"required,oneof=google facebook github email"`
 	FirstName   string `json:"first_name" validate:"omitempty,max=255"`
 	LastName    string `json:"last_name" validate:"omitempty,max=255"`
 	Email       string `json:"email" validate:"required,email,max=255"`
+	EmailVerified bool `json:"email_verified"`
 	AvatarURL   string `json:"avatar_url" validate:"omitempty,url,max=500"`
 	Locale      string `json:"locale" validate:"omitempty,max=10"`
 }
 
-// UpdateUserRequest represents a request to update user profile
+// UpdateUserRequest represents a partial profile update. Omitted or null fields are left
+// unchanged and an empty string clears a field. The username can be changed but not cleared.
 type UpdateUserRequest struct {
-	FirstName   string `json:"first_name" validate:"omitempty,max=255"`
-	LastName    string `json:"last_name" validate:"omitempty,max=255"`
-	Username    string `json:"username" validate:"omitempty,min=3,max=100,alphanum"`
-	DisplayName string `json:"display_name" validate:"omitempty,max=150"`
-	Bio         string `json:"bio" validate:"omitempty,max=500"`
-	AvatarURL   string `json:"avatar_url" validate:"omitempty,url,max=500"`
+	FirstName   *string `json:"first_name" validate:"omitempty,max=255"`
+	LastName    *string `json:"last_name" validate:"omitempty,max=255"`
+	Username    *string `json:"username" validate:"omitempty,max=100"`
+	DisplayName *string `json:"display_name" validate:"omitempty,max=150"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,url,max=500"`
+	Visibility  *UpdateVisibilityRequest `json:"visibility"`
+	// IsPrivate makes follows of the account need approval. Making the account public approves pending requests.
+	IsPrivate *bool `json:"is_private"`
+}
+
+// UpdateVisibilityRequest changes who can see the private profile fields. Omitted fields are unchanged.
+type UpdateVisibilityRequest struct {
+	Email     *string `json:"email" validate:"omitempty,oneof=everyone followers nobody"`
+	FirstName *string `json:"first_name" validate:"omitempty,oneof=everyone followers nobody"`
+	LastName  *string `json:"last_name" validate:"omitempty,oneof=everyone followers nobody"`
+}
+
+// ProfileVisibility reports who can see each private profile field: everyone, followers or nobody
+type ProfileVisibility struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// UsernameAvailabilityResponse reports whether a username can be claimed
+type UsernameAvailabilityResponse struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	// Reason is one of invalid, reserved, not_allowed or taken when the name is unavailable
+	Reason string `json:"reason,omitempty"`
 }
 
 // UserResponse represents a user in API responses
@@ -34,18 +61,68 @@ type UserResponse struct {
 	DisplayName    string    `json:"display_name,omitempty"`
 	Bio            string    `json:"bio,omitempty"`
 	AvatarURL      string    `json:"avatar_url,omitempty"`
+	Role           string    `json:"role"`
+	UsernameChangedAt *time.Time `json:"username_changed_at,omitempty"`
+	Visibility     ProfileVisibility `json:"visibility"`
+	IsPrivate      bool      `json:"is_private"`
+	Reputation     int       `json:"reputation"`
+	// DeletionScheduledAt is set while an account deletion is pending
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	FollowerCount  int64     `json:"follower_count"`
+	FollowingCount int64     `json:"following_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PublicUserResponse is a user profile as seen by someone else. Email, first name and last name
+// are only present when the user's visibility settings allow the viewer to see them.
+type PublicUserResponse struct {
+	ID             string    `json:"id"`
+	Username       string    `json:"username"`
+	DisplayName    string    `json:"display_name,omitempty"`
+	FirstName      string    `json:"first_name,omitempty"`
+	LastName       string    `json:"last_name,omitempty"`
+	Email          string    `json:"email,omitempty"`
+	Bio            string    `json:"bio,omitempty"`
+	AvatarURL      string    `json:"avatar_url,omitempty"`
+	Role           string    `json:"role"`
+	IsPrivate      bool      `json:"is_private"`
+	Reputation     int       `json:"reputation"`
 	FollowerCount  int64     `json:"follower_count"`
 	FollowingCount int64     `json:"following_count"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
-// UsersResponse represents a paginated list of users
+// AccountDeletionResponse describes a pending account deletion
+type AccountDeletionResponse struct {
+	DeletionRequestedAt time.Time `json:"deletion_requested_at"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// DataExportResponse describes a personal data export. The download link is only sent by email.
+type DataExportResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// IdentityResponse represents a login provider linked to a user
+type IdentityResponse struct {
+	Provider      string    `json:"provider"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UsersResponse represents a page of user search results
 type UsersResponse struct {
-	Users      []UserResponse `json:"users"`
-	TotalCount int64          `json:"total_count"`
-	Page       int            `json:"page"`
-	Limit      int            `json:"limit"`
-	HasNext    bool           `json:"has_next"`
+	Users      []PublicUserResponse `json:"users"`
+	TotalCount int64                `json:"total_count"`
+	Page       int                  `json:"page"`
+	Limit      int                  `json:"limit"`
+	HasNext    bool                 `json:"has_next"`
 }
 
 // OAuthCallbackResponse represents the response after OAuth callback
@@ -54,15 +131,3 @@ type OAuthCallbackResponse struct {
 	AccessToken string       `json:"access_token"`
 	IsNewUser   bool         `json:"is_new_user"`
 }
-
-// GoogleOAuthProfile represents Google OAuth profile data
-type GoogleOAuthProfile struct {
-	ID            string `json:"id"`
-	Email         string `json:"email"`
-	Name          string `json:"name"`
-	FirstName     string `json:"given_name"`
-	LastName      string `json:"family_name"`
-	Picture       string `json:"picture"`
-	Locale        string `json:"locale"`
-	VerifiedEmail bool   `json:"verified_email"`
-}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// BlockHandler handles HTTP requests for blocks and mutes
+type BlockHandler struct {
+	blockService *service.BlockService
+}
+
+// NewBlockHandler creates a new block handler instance
+func NewBlockHandler(blockService *service.BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// BlockUser blocks a user
+// @Summary Block a user
+// @Description Block a user. Any follow between you is removed, neither of you can follow, comment on or reply to the other, and each one's content is hidden from the other.
+// @Tags blocks
+// @Produce json
+// @Param user_id path string true "User ID to block"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/{user_id}/block [post]
+func (h *BlockHandler) BlockUser(c *fiber.Ctx) error {
+	return h.relate(c, h.blockService.BlockUser, "User blocked successfully", "Failed to block user")
+}
+
+// UnblockUser removes a block
+// @Summary Unblock a user
+// @Description Remove a block you created. Follows removed by the block are not restored.
+// @Tags blocks
+// @Produce json
+// @Param user_id path string true "User ID to unblock"
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/{user_id}/block [delete]
+func (h *BlockHandler) UnblockUser(c *fiber.Ctx) error {
+	return h.relate(c, h.blockService.UnblockUser, "User unblocked successfully", "Failed to unblock user")
+}
+
+// MuteUser mutes a user
+// @Summary Mute a user
+// @Description Hide a user's content from you. The user is not notified and can still interact with you.
+// @Tags blocks
+// @Produce json
+// @Param user_id path string true "User ID to mute"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/{user_id}/mute [post]
+func (h *BlockHandler) MuteUser(c *fiber.Ctx) error {
+	return h.relate(c, h.blockService.MuteUser, "User muted successfully", "Failed to mute user")
+}
+
+// UnmuteUser removes a mute
+// @Summary Unmute a user
+// @Description Show a muted user's content again
+// @Tags blocks
+// @Produce json
+// @Param user_id path string true "User ID to unmute"
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/{user_id}/mute [delete]
+func (h *BlockHandler) UnmuteUser(c *fiber.Ctx) error {
+	return h.relate(c, h.blockService.UnmuteUser, "User unmuted successfully", "Failed to unmute user")
+}
+
+// GetBlockedUsers lists the users the current user has blocked
+// @Summary List blocked users
+// @Description List the users you have blocked, most recent first
+// @Tags blocks
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/blocks [get]
+func (h *BlockHandler) GetBlockedUsers(c *fiber.Ctx) error {
+	return h.list(c, h.blockService.GetBlockedUsers, "Blocked users retrieved successfully")
+}
+
+// GetMutedUsers lists the users the current user has muted
+// @Summary List muted users
+// @Description List the users you have muted, most recent first
+// @Tags blocks
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/mutes [get]
+func (h *BlockHandler) GetMutedUsers(c *fiber.Ctx) error {
+	return h.list(c, h.blockService.GetMutedUsers, "Muted users retrieved successfully")
+}
+
+// relate applies a block or mute change between the current user and the user in the path
+func (h *BlockHandler) relate(c *fiber.Ctx, apply func(userID, targetID string) error, successMessage, failureMessage string) error {
+	targetID := c.Params("user_id")
+	if strings.TrimSpace(targetID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
+	}
+
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	if err := apply(userID, targetID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrRelateToSelf):
+			return response.ValidationErrorJSON(c, "Invalid operation", err.Error())
+		case strings.Contains(err.Error(), "user not found"):
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error(failureMessage, "user_id", userID, "target_id", targetID, "error", err)
+		return response.InternalErrorJSON(c, failureMessage)
+	}
+
+	return response.SuccessJSON(c, nil, successMessage)
+}
+
+// list returns a page of the current user's blocked or muted users
+func (h *BlockHandler) list(c *fiber.Ctx, fetch func(userID string, page, limit int) (*dto.RelatedUsersResponse, error), successMessage string) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	users, err := fetch(userID, page, limit)
+	if err != nil {
+		logger.Error("Failed to list related users", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve users")
+	}
+
+	meta := &response.MetaInfo{
+		Page:    users.Page,
+		Limit:   users.Limit,
+		Total:   users.TotalCount,
+		HasNext: users.HasNext,
+	}
+
+	return response.SuccessJSONWithMeta(c, users.Users, successMessage, meta)
+}
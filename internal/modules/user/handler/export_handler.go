@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// ExportHandler handles HTTP requests for personal data exports
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new export handler instance
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport starts a personal data export
+// @Summary Export my data
+// @Description Start building a ZIP archive of everything held about the current user. A download link is emailed when it is ready. While an export is being built, it is returned instead of starting another.
+// @Tags users
+// @Produce json
+// @Success 202 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Failure 503 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/export [post]
+func (h *ExportHandler) RequestExport(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	export, err := h.exportService.RequestExport(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrExportsDisabled) {
+			return response.ErrorJSON(c, fiber.StatusServiceUnavailable, "EXPORTS_DISABLED", "Data exports are not available", err.Error())
+		}
+		if errors.Is(err, service.ErrExportCooldown) {
+			return response.ErrorJSON(c, fiber.StatusTooManyRequests, "EXPORT_COOLDOWN", "A data export was requested too recently", err.Error())
+		}
+		logger.Error("Failed to request data export", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to start data export")
+	}
+
+	return response.JSON(c, fiber.StatusAccepted, response.Success(export, "Data export started"))
+}
+
+// GetExport returns the status of the current user's latest data export
+// @Summary Get my data export status
+// @Description Get the status of the current user's most recent data export
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/export [get]
+func (h *ExportHandler) GetExport(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	export, err := h.exportService.GetLatestExport(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "Data export")
+		}
+		logger.Error("Failed to retrieve data export", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve data export")
+	}
+
+	return response.SuccessJSON(c, export, "Data export retrieved successfully")
+}
+
+// DownloadExport streams a data export archive. The emailed link carries the token, so no sign-in is needed.
+// @Summary Download a data export
+// @Description Download a data export archive using the time-limited link sent by email
+// @Tags users
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param token query string true "Download token"
+// @Success 200 {file} file
+// @Failure 404 {object} response.APIResponse
+// @Router /users/exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *fiber.Ctx) error {
+	reader, export, err := h.exportService.OpenDownload(c.Params("id"), c.Query("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrExportLinkInvalid) {
+			return response.ErrorJSON(c, fiber.StatusNotFound, "INVALID_DOWNLOAD_LINK", "Download link is invalid or has expired", "")
+		}
+		logger.Error("Failed to open data export", "export_id", c.Params("id"), "error", err)
+		return response.InternalErrorJSON(c, "Failed to download data export")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="pitstop-export-%s.zip"`, export.CreatedAt.Format("2006-01-02")))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.SendStream(reader, int(export.Size))
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
 	"github.com/topboyasante/pitstop/internal/modules/user/service"
 	utils "github.com/topboyasante/pitstop/internal/shared/utils"
 )
@@ -24,7 +27,7 @@ func NewFollowHandler(followService *service.FollowService) *FollowHandler {
 
 // ToggleFollow toggles a follow relationship for a user
 // @Summary Toggle follow on a user
-// @Description Follow or unfollow a user
+// @Description Follow or unfollow a user. Following a private account sends a follow request instead; toggling again while it is pending cancels it.
 // @Tags follows
 // @Accept json
 // @Produce json
@@ -53,6 +56,9 @@ func (h *FollowHandler) ToggleFollow(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "user not found") {
 			return response.NotFoundJSON(c, "User")
 		}
+		if errors.Is(err, service.ErrBlocked) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "BLOCKED", "You cannot follow this user", "")
+		}
 		if strings.Contains(err.Error(), "cannot follow themselves") {
 			return response.ValidationErrorJSON(c, "Invalid operation", "Users cannot follow themselves")
 		}
@@ -63,67 +69,103 @@ func (h *FollowHandler) ToggleFollow(c *fiber.Ctx) error {
 	if !result.IsFollowing {
 		action = "unfollowed"
 	}
+	if result.IsRequested {
+		action = "requested"
+	}
 
 	logger.Info("Follow toggled successfully", "follower_id", followerID, "following_id", userID, "action", action)
+	if result.IsRequested {
+		return response.SuccessJSON(c, result, "Follow request sent successfully")
+	}
 	return response.SuccessJSON(c, result, "User "+action+" successfully")
 }
 
-// GetFollowers retrieves all followers for a user
+// GetFollowers retrieves a page of a user's followers
 // @Summary Get followers for a user
-// @Description Retrieve all users who follow a specific user
+// @Description Retrieve the users who follow a specific user, newest first. When signed in, each user carries you_follow and follows_you. The followers of a private account are only shown to the account and its followers.
 // @Tags follows
 // @Accept json
 // @Produce json
 // @Param user_id path string true "User ID"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Router /users/{user_id}/followers [get]
 func (h *FollowHandler) GetFollowers(c *fiber.Ctx) error {
-	userID := c.Params("user_id")
-	if strings.TrimSpace(userID) == "" {
-		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
-	}
-
-	followers, err := h.followService.GetFollowers(userID)
-	if err != nil {
-		logger.Error("Failed to retrieve followers", "user_id", userID, "error", err)
-		if strings.Contains(err.Error(), "user not found") {
-			return response.NotFoundJSON(c, "User")
-		}
-		return response.InternalErrorJSON(c, "Failed to retrieve followers")
-	}
-
-	return response.SuccessJSON(c, followers, "Followers retrieved successfully")
+	return h.followList(c, "followers", func(userID, viewerID, cursor string, limit int) (interface{}, error) {
+		return h.followService.GetFollowers(userID, viewerID, cursor, limit)
+	})
 }
 
-// GetFollowing retrieves all users that a user is following
+// GetFollowing retrieves a page of the users a user is following
 // @Summary Get users being followed
-// @Description Retrieve all users that a specific user is following
+// @Description Retrieve the users that a specific user is following, newest first. When signed in, each user carries you_follow and follows_you. For a private account the list is only shown to the account and its followers.
 // @Tags follows
 // @Accept json
 // @Produce json
 // @Param user_id path string true "User ID"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Items per page" default(20)
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Router /users/{user_id}/following [get]
 func (h *FollowHandler) GetFollowing(c *fiber.Ctx) error {
+	return h.followList(c, "following", func(userID, viewerID, cursor string, limit int) (interface{}, error) {
+		return h.followService.GetFollowing(userID, viewerID, cursor, limit)
+	})
+}
+
+// GetMutuals retrieves a page of a user's followers that the current user also follows
+// @Summary Get mutual follows
+// @Description Retrieve the followers of a specific user that you also follow, newest first
+// @Tags follows
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/{user_id}/mutuals [get]
+func (h *FollowHandler) GetMutuals(c *fiber.Ctx) error {
+	return h.followList(c, "mutuals", func(userID, viewerID, cursor string, limit int) (interface{}, error) {
+		return h.followService.GetMutuals(userID, viewerID, cursor, limit)
+	})
+}
+
+// followList returns a page of a followers, following or mutuals list for the user in the path
+func (h *FollowHandler) followList(c *fiber.Ctx, name string, fetch func(userID, viewerID, cursor string, limit int) (interface{}, error)) error {
 	userID := c.Params("user_id")
 	if strings.TrimSpace(userID) == "" {
 		return response.ValidationErrorJSON(c, "Invalid user ID", "User ID cannot be empty")
 	}
 
-	following, err := h.followService.GetFollowing(userID)
+	viewerID, _ := c.Locals("userID").(string)
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	list, err := fetch(userID, viewerID, c.Query("cursor"), limit)
 	if err != nil {
-		logger.Error("Failed to retrieve following", "user_id", userID, "error", err)
 		if strings.Contains(err.Error(), "user not found") {
 			return response.NotFoundJSON(c, "User")
 		}
-		return response.InternalErrorJSON(c, "Failed to retrieve following")
+		if errors.Is(err, service.ErrPrivateAccount) {
+			return response.ErrorJSON(c, fiber.StatusForbidden, "PRIVATE_ACCOUNT", "This account is private", "")
+		}
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return response.ValidationErrorJSON(c, "Invalid cursor", "Use the next_cursor of a previous page")
+		}
+		logger.Error("Failed to retrieve "+name, "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve "+name)
 	}
 
-	return response.SuccessJSON(c, following, "Following retrieved successfully")
+	return response.SuccessJSON(c, list, strings.ToUpper(name[:1])+name[1:]+" retrieved successfully")
 }
 
 // CheckFollowStatus checks if the current user is following a specific user
@@ -157,4 +199,123 @@ func (h *FollowHandler) CheckFollowStatus(c *fiber.Ctx) error {
 	}
 
 	return response.SuccessJSON(c, map[string]bool{"is_following": isFollowing}, "Follow status retrieved successfully")
-}
\ No newline at end of file
+}
+
+// GetIncomingFollowRequests lists the pending requests to follow the current user
+// @Summary List incoming follow requests
+// @Description List the pending requests to follow you, oldest first
+// @Tags follows
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/follow-requests [get]
+func (h *FollowHandler) GetIncomingFollowRequests(c *fiber.Ctx) error {
+	return h.listRequests(c, h.followService.GetIncomingFollowRequests)
+}
+
+// GetOutgoingFollowRequests lists the pending follow requests the current user has made
+// @Summary List outgoing follow requests
+// @Description List the follow requests you have sent that are still pending, most recent first
+// @Tags follows
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/follow-requests/outgoing [get]
+func (h *FollowHandler) GetOutgoingFollowRequests(c *fiber.Ctx) error {
+	return h.listRequests(c, h.followService.GetOutgoingFollowRequests)
+}
+
+// ApproveFollowRequest approves a request to follow the current user
+// @Summary Approve a follow request
+// @Description Approve a pending request to follow you. The requester becomes a follower.
+// @Tags follows
+// @Produce json
+// @Param request_id path string true "Follow request ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/follow-requests/{request_id}/approve [post]
+func (h *FollowHandler) ApproveFollowRequest(c *fiber.Ctx) error {
+	return h.actOnRequest(c, h.followService.ApproveFollowRequest, "Follow request approved successfully", "Failed to approve follow request")
+}
+
+// RejectFollowRequest rejects a request to follow the current user
+// @Summary Reject a follow request
+// @Description Reject a pending request to follow you. The requester is not notified.
+// @Tags follows
+// @Produce json
+// @Param request_id path string true "Follow request ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/follow-requests/{request_id}/reject [post]
+func (h *FollowHandler) RejectFollowRequest(c *fiber.Ctx) error {
+	return h.actOnRequest(c, h.followService.RejectFollowRequest, "Follow request rejected successfully", "Failed to reject follow request")
+}
+
+// CancelFollowRequest withdraws a follow request the current user made
+// @Summary Cancel a follow request
+// @Description Withdraw a follow request you sent that is still pending
+// @Tags follows
+// @Produce json
+// @Param request_id path string true "Follow request ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me/follow-requests/{request_id} [delete]
+func (h *FollowHandler) CancelFollowRequest(c *fiber.Ctx) error {
+	return h.actOnRequest(c, h.followService.CancelFollowRequest, "Follow request cancelled successfully", "Failed to cancel follow request")
+}
+
+// actOnRequest applies an approval, rejection or cancellation to the follow request in the path
+func (h *FollowHandler) actOnRequest(c *fiber.Ctx, apply func(userID, requestID string) error, successMessage, failureMessage string) error {
+	requestID := c.Params("request_id")
+	if strings.TrimSpace(requestID) == "" {
+		return response.ValidationErrorJSON(c, "Invalid request ID", "Request ID cannot be empty")
+	}
+
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	if err := apply(userID, requestID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "Follow request")
+		}
+		logger.Error(failureMessage, "user_id", userID, "request_id", requestID, "error", err)
+		return response.InternalErrorJSON(c, failureMessage)
+	}
+
+	return response.SuccessJSON(c, nil, successMessage)
+}
+
+// listRequests returns a page of the current user's incoming or outgoing follow requests
+func (h *FollowHandler) listRequests(c *fiber.Ctx, fetch func(userID string, page, limit int) (*dto.FollowRequestsResponse, error)) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+
+	requests, err := fetch(userID, page, limit)
+	if err != nil {
+		logger.Error("Failed to list follow requests", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve follow requests")
+	}
+
+	meta := &response.MetaInfo{
+		Page:    requests.Page,
+		Limit:   requests.Limit,
+		Total:   requests.TotalCount,
+		HasNext: requests.HasNext,
+	}
+
+	return response.SuccessJSONWithMeta(c, requests.Requests, "Follow requests retrieved successfully", meta)
+}
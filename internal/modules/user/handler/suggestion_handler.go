@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/response"
+	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+)
+
+// SuggestionHandler handles HTTP requests for follow suggestions
+type SuggestionHandler struct {
+	suggestionService *service.SuggestionService
+}
+
+// NewSuggestionHandler creates a new suggestion handler instance
+func NewSuggestionHandler(suggestionService *service.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{
+		suggestionService: suggestionService,
+	}
+}
+
+// GetSuggestions returns users the current user may want to follow
+// @Summary Who to follow
+// @Description Recommend users to follow, ranked by people followed by the users you follow, shared question tags and recent activity. Users you follow, asked to follow or blocked, and users who blocked you, are left out.
+// @Tags follows
+// @Produce json
+// @Param limit query int false "Number of suggestions" default(10)
+// @Success 200 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/suggestions [get]
+func (h *SuggestionHandler) GetSuggestions(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+
+	suggestions, err := h.suggestionService.GetSuggestions(userID, limit)
+	if err != nil {
+		logger.Error("Failed to retrieve suggestions", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve suggestions")
+	}
+
+	return response.SuccessJSON(c, suggestions, "Suggestions retrieved successfully")
+}
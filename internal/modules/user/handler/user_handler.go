@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
 	"github.com/topboyasante/pitstop/internal/core/response"
 	"github.com/topboyasante/pitstop/internal/modules/user/dto"
 	"github.com/topboyasante/pitstop/internal/modules/user/service"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
 )
 
 // UserHandler handles HTTP requests for users
@@ -22,23 +27,37 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 	}
 }
 
-// GetAllUsers retrieves all users
-// @Summary Get all users
-// @Description Retrieve a paginated list of users
+// SearchUsers finds users by username or display name
+// @Summary Search users
+// @Description Find users whose username or display name starts with the query. Results only include the fields each user shares with you.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Param q query string true "Username or display name prefix, at least 2 characters"
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Users per page" default(20)
+// @Param limit query int false "Users per page (max 50)" default(20)
 // @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Security BearerAuth
 // @Router /users [get]
-func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
+func (h *UserHandler) SearchUsers(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(strings.TrimPrefix(query, "@"))) < 2 {
+		return response.ValidationErrorJSON(c, "Search query is required", "q must be at least 2 characters")
+	}
+
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 
-	users, err := h.userService.GetAllUsers(page, limit)
+	users, err := h.userService.SearchUsers(query, userID, page, limit)
 	if err != nil {
-		logger.Error("Failed to retrieve users", "error", err)
+		logger.Error("Failed to search users", "error", err)
 		return response.InternalErrorJSON(c, "Failed to retrieve users")
 	}
 
@@ -81,21 +100,184 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 
 // GetUser retrieves a specific user by ID
 // @Summary Get a user by ID
-// @Description Retrieve a specific user
+// @Description Retrieve a user's profile. Email, first name and last name are included only when the user's visibility settings allow it; your own profile is returned in full.
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param id path int true "User ID"
+// @Param id path string true "User ID"
 // @Success 200 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(c *fiber.Ctx) error {
 	id := c.Params("id")
+	viewerID, _ := c.Locals("userID").(string)
+
+	user, err := h.userService.GetProfile(id, viewerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error("Failed to retrieve user", "user_id", id, "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve user")
+	}
+
+	return response.SuccessJSON(c, user, "User retrieved successfully")
+}
+
+// GetMe retrieves the current user's full profile
+// @Summary Get current user's profile
+// @Description Retrieve your own profile, including email and visibility settings
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me [get]
+func (h *UserHandler) GetMe(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(userID)
 	if err != nil {
 		return response.NotFoundJSON(c, "User")
 	}
 
 	return response.SuccessJSON(c, user, "User retrieved successfully")
 }
+
+// GetUserByUsername retrieves a user by username
+// @Summary Get a user by username
+// @Description Retrieve a user's profile by username, projected like GET /users/{id}. A previous username answers with a 301 redirect to the user's current one.
+// @Tags users
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} response.APIResponse
+// @Success 301 "Username changed; Location points to the current username"
+// @Failure 404 {object} response.APIResponse
+// @Router /users/@{username} [get]
+func (h *UserHandler) GetUserByUsername(c *fiber.Ctx) error {
+	viewerID, _ := c.Locals("userID").(string)
+
+	user, movedTo, err := h.userService.GetProfileByUsername(c.Params("username"), viewerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error("Failed to look up username", "username", c.Params("username"), "error", err)
+		return response.InternalErrorJSON(c, "Failed to retrieve user")
+	}
+
+	if movedTo != "" {
+		return c.Redirect("/api/v1/users/@"+url.PathEscape(movedTo), fiber.StatusMovedPermanently)
+	}
+
+	return response.SuccessJSON(c, user, "User retrieved successfully")
+}
+
+// UpdateMe updates the current user's profile
+// @Summary Update current user's profile
+// @Description Partially update the profile. Omitted fields are unchanged and an empty string clears a field. Username changes are subject to a cooldown.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateUserRequest true "Fields to update"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me [patch]
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	var req dto.UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.ValidationErrorJSON(c, "Invalid request body", err.Error())
+	}
+
+	// Changing the username moves the account's public address, so automation tokens may not
+	if req.Username != nil && middleware.IsPersonalToken(c) {
+		return response.ErrorJSON(c, fiber.StatusForbidden, "SESSION_REQUIRED", "Changing the username requires a signed-in session", "Personal access tokens cannot change the username")
+	}
+
+	user, err := h.userService.UpdateUser(userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUsernameInvalid),
+			errors.Is(err, service.ErrUsernameReserved),
+			errors.Is(err, service.ErrUsernameNotAllowed):
+			return response.ValidationErrorJSON(c, "Username cannot be used", err.Error())
+		case errors.Is(err, service.ErrUsernameTaken):
+			return response.ErrorJSON(c, fiber.StatusConflict, "USERNAME_TAKEN", "Username is already taken", "")
+		case errors.Is(err, service.ErrUsernameCooldown):
+			return response.ErrorJSON(c, fiber.StatusTooManyRequests, "USERNAME_CHANGE_COOLDOWN", "Username was changed too recently", err.Error())
+		case strings.Contains(err.Error(), "validation failed"):
+			return response.ValidationErrorJSON(c, "Invalid profile fields", err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error("Failed to update user", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to update profile")
+	}
+
+	return response.SuccessJSON(c, user, "Profile updated successfully")
+}
+
+// DeleteMe schedules the current user's account for deletion
+// @Summary Delete current user's account
+// @Description Schedule the account for permanent deletion after a grace period and sign out of every session. Signing in again before the scheduled time cancels the deletion.
+// @Tags users
+// @Produce json
+// @Success 202 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Security BearerAuth
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteMe(c *fiber.Ctx) error {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return response.UnauthorizedJSON(c)
+	}
+
+	deletion, err := h.userService.RequestDeletion(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return response.NotFoundJSON(c, "User")
+		}
+		logger.Error("Failed to request account deletion", "user_id", userID, "error", err)
+		return response.InternalErrorJSON(c, "Failed to delete account")
+	}
+
+	return response.JSON(c, fiber.StatusAccepted, response.Success(deletion, "Account scheduled for deletion"))
+}
+
+// CheckUsernameAvailability reports whether a username can be claimed
+// @Summary Check username availability
+// @Description Check a username against the format, reserved names, blocked words and existing users. When signed in, your own names count as available.
+// @Tags users
+// @Produce json
+// @Param username query string true "Username to check"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Router /users/username-available [get]
+func (h *UserHandler) CheckUsernameAvailability(c *fiber.Ctx) error {
+	username := c.Query("username")
+	if username == "" {
+		return response.ValidationErrorJSON(c, "Username is required", "username query parameter cannot be empty")
+	}
+
+	// The route is public; a signed-in user is only used to treat their own names as available
+	userID, _ := c.Locals("userID").(string)
+
+	result, err := h.userService.CheckUsernameAvailability(username, userID)
+	if err != nil {
+		logger.Error("Failed to check username availability", "error", err)
+		return response.InternalErrorJSON(c, "Failed to check username")
+	}
+
+	return response.SuccessJSON(c, result, "Username availability checked")
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockRepository handles block and mute data operations
+type BlockRepository struct {
+	db *gorm.DB
+}
+
+// NewBlockRepository creates a new block repository instance
+func NewBlockRepository(db *gorm.DB) *BlockRepository {
+	return &BlockRepository{db: db}
+}
+
+// Block creates a block and removes any follow or follow request between the two users in the same transaction.
+// Blocking someone who is already blocked is a no-op.
+func (r *BlockRepository) Block(blockerID, blockedID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		block := &domain.Block{
+			ID:        uuid.NewString(),
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).
+			Delete(&domain.Follow{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("(requester_id = ? AND target_id = ?) OR (requester_id = ? AND target_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).
+			Delete(&domain.FollowRequest{}).Error
+	})
+}
+
+// Unblock removes a block. It reports whether a block existed.
+func (r *BlockRepository) Unblock(blockerID, blockedID string) (bool, error) {
+	result := r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&domain.Block{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsBlockedEither checks whether either user has blocked the other
+func (r *BlockRepository) IsBlockedEither(userID, otherID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetBlocked retrieves the users a user has blocked, most recent first
+func (r *BlockRepository) GetBlocked(blockerID string, page, limit int) ([]domain.Block, int64, error) {
+	var blocks []domain.Block
+	var totalCount int64
+
+	query := r.db.Model(&domain.Block{}).Where("blocker_id = ?", blockerID)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Blocked").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&blocks).Error
+	return blocks, totalCount, err
+}
+
+// Mute creates a mute. Muting someone who is already muted is a no-op.
+func (r *BlockRepository) Mute(muterID, mutedID string) error {
+	mute := &domain.Mute{
+		ID:      uuid.NewString(),
+		MuterID: muterID,
+		MutedID: mutedID,
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error
+}
+
+// Unmute removes a mute. It reports whether a mute existed.
+func (r *BlockRepository) Unmute(muterID, mutedID string) (bool, error) {
+	result := r.db.Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&domain.Mute{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetMuted retrieves the users a user has muted, most recent first
+func (r *BlockRepository) GetMuted(muterID string, page, limit int) ([]domain.Mute, int64, error) {
+	var mutes []domain.Mute
+	var totalCount int64
+
+	query := r.db.Model(&domain.Mute{}).Where("muter_id = ?", muterID)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Muted").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&mutes).Error
+	return mutes, totalCount, err
+}
+
+// PurgeUser deletes every block and mute involving a user. It runs inside the account deletion transaction.
+func (r *BlockRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	if err := tx.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&domain.Block{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("muter_id = ? OR muted_id = ?", userID, userID).Delete(&domain.Mute{}).Error
+}
+
+// ExcludeBlockedUsers is a query scope that drops rows whose column holds a user who blocked
+// or was blocked by the viewer. An empty viewer ID leaves the query unchanged.
+func ExcludeBlockedUsers(column, viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == "" {
+			return db
+		}
+		sub := db.Session(&gorm.Session{NewDB: true})
+		blocked := sub.Model(&domain.Block{}).Select("blocked_id").Where("blocker_id = ?", viewerID)
+		blockers := sub.Model(&domain.Block{}).Select("blocker_id").Where("blocked_id = ?", viewerID)
+		return db.Where(column+" NOT IN (?) AND "+column+" NOT IN (?)", blocked, blockers)
+	}
+}
+
+// ExcludeHiddenUsers is a query scope that drops content the viewer should not see: rows whose
+// column holds a user blocked in either direction or muted by the viewer. An empty viewer ID
+// leaves the query unchanged.
+func ExcludeHiddenUsers(column, viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == "" {
+			return db
+		}
+		muted := db.Session(&gorm.Session{NewDB: true}).Model(&domain.Mute{}).Select("muted_id").Where("muter_id = ?", viewerID)
+		return ExcludeBlockedUsers(column, viewerID)(db).Where(column+" NOT IN (?)", muted)
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"gorm.io/gorm"
+)
+
+// DataExportRepository handles personal data export records
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new data export repository instance
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create creates a new data export record
+func (r *DataExportRepository) Create(export *domain.DataExport) error {
+	if export.ID == "" {
+		export.ID = uuid.NewString()
+	}
+	return r.db.Create(export).Error
+}
+
+// GetByID retrieves a data export by ID
+func (r *DataExportRepository) GetByID(id string) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.db.Where("id = ?", id).First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetLatestForUser retrieves the user's most recent data export
+func (r *DataExportRepository) GetLatestForUser(userID string) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetUnfinished retrieves exports that are still pending or were interrupted while processing
+func (r *DataExportRepository) GetUnfinished() ([]domain.DataExport, error) {
+	var exports []domain.DataExport
+	err := r.db.Where("status IN ?", []string{domain.DataExportPending, domain.DataExportProcessing}).
+		Order("created_at ASC").
+		Find(&exports).Error
+	return exports, err
+}
+
+// GetExpired retrieves ready exports whose download link expired before the given time
+func (r *DataExportRepository) GetExpired(before time.Time, limit int) ([]domain.DataExport, error) {
+	var exports []domain.DataExport
+	err := r.db.Where("status = ? AND expires_at <= ?", domain.DataExportReady, before).
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+// Update saves changes to a data export
+func (r *DataExportRepository) Update(export *domain.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+// PurgeUser deletes a user's export records and returns the blob keys they referenced.
+// It runs inside the account deletion transaction.
+func (r *DataExportRepository) PurgeUser(tx *gorm.DB, userID string) ([]string, error) {
+	var keys []string
+	if err := tx.Model(&domain.DataExport{}).
+		Where("user_id = ? AND blob_key <> ''", userID).
+		Pluck("blob_key", &keys).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.DataExport{}).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"github.com/topboyasante/pitstop/internal/shared/suggestion"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FollowRepository handles follow data operations
@@ -48,7 +51,29 @@ func (r *FollowRepository) Exists(followerID, followingID string) (bool, error)
 	return count > 0, nil
 }
 
-// GetFollowers retrieves all followers for a user with user information
+// FollowedAmong returns which of the given users the follower follows
+func (r *FollowRepository) FollowedAmong(followerID string, userIDs []string) (map[string]bool, error) {
+	followed := make(map[string]bool)
+	if followerID == "" || len(userIDs) == 0 {
+		return followed, nil
+	}
+
+	var ids []string
+	err := r.db.Model(&domain.Follow{}).
+		Where("follower_id = ? AND following_id IN ?", followerID, userIDs).
+		Pluck("following_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		followed[id] = true
+	}
+	return followed, nil
+}
+
+// GetFollowers retrieves every follower of a user, for their personal data export. Lists shown
+// in the API are paged with ListFollowers.
 func (r *FollowRepository) GetFollowers(userID string) ([]domain.User, error) {
 	var users []domain.User
 	err := r.db.Table("users").
@@ -57,19 +82,11 @@ func (r *FollowRepository) GetFollowers(userID string) ([]domain.User, error) {
 		Where("follows.following_id = ?", userID).
 		Order("follows.created_at DESC").
 		Find(&users).Error
-	if err != nil {
-		return nil, err
-	}
-
-	// Calculate follower/following counts for each user
-	for i := range users {
-		r.calculateFollowCounts(&users[i])
-	}
-
-	return users, nil
+	return users, err
 }
 
-// GetFollowing retrieves all users that a user is following with user information
+// GetFollowing retrieves every user a user follows, for their personal data export. Lists shown
+// in the API are paged with ListFollowing.
 func (r *FollowRepository) GetFollowing(userID string) ([]domain.User, error) {
 	var users []domain.User
 	err := r.db.Table("users").
@@ -78,16 +95,110 @@ func (r *FollowRepository) GetFollowing(userID string) ([]domain.User, error) {
 		Where("follows.follower_id = ?", userID).
 		Order("follows.created_at DESC").
 		Find(&users).Error
-	if err != nil {
-		return nil, err
+	return users, err
+}
+
+// FollowCursor marks the last entry of a page of a follow list. Lists run from the newest follow to the oldest.
+type FollowCursor struct {
+	CreatedAt time.Time
+	FollowID  string
+}
+
+// FollowListEntry is one user in a followers, following or mutuals list. YouFollow and FollowsYou
+// relate the user to the viewer and are false when there is no viewer.
+type FollowListEntry struct {
+	FollowID    string
+	FollowedAt  time.Time
+	UserID      string
+	Username    string
+	DisplayName string
+	AvatarURL   string
+	Bio         string
+	YouFollow   bool
+	FollowsYou  bool
+}
+
+// ListFollowers retrieves a page of a user's followers, newest first, starting after the cursor
+func (r *FollowRepository) ListFollowers(userID, viewerID string, cursor *FollowCursor, limit int) ([]FollowListEntry, error) {
+	return r.listFollows(r.followersOf(userID, viewerID), viewerID, cursor, limit)
+}
+
+// ListFollowing retrieves a page of the users a user follows, newest first, starting after the cursor
+func (r *FollowRepository) ListFollowing(userID, viewerID string, cursor *FollowCursor, limit int) ([]FollowListEntry, error) {
+	return r.listFollows(r.followingOf(userID, viewerID), viewerID, cursor, limit)
+}
+
+// CountListedFollowers counts the followers ListFollowers shows the viewer
+func (r *FollowRepository) CountListedFollowers(userID, viewerID string) (int64, error) {
+	var count int64
+	err := r.followersOf(userID, viewerID).Count(&count).Error
+	return count, err
+}
+
+// CountListedFollowing counts the users ListFollowing shows the viewer
+func (r *FollowRepository) CountListedFollowing(userID, viewerID string) (int64, error) {
+	var count int64
+	err := r.followingOf(userID, viewerID).Count(&count).Error
+	return count, err
+}
+
+// ListMutuals retrieves a page of a user's followers that the viewer also follows, newest first
+func (r *FollowRepository) ListMutuals(userID, viewerID string, cursor *FollowCursor, limit int) ([]FollowListEntry, error) {
+	return r.listFollows(r.mutualsOf(userID, viewerID), viewerID, cursor, limit)
+}
+
+// CountMutuals counts a user's followers that the viewer also follows
+func (r *FollowRepository) CountMutuals(userID, viewerID string) (int64, error) {
+	var count int64
+	err := r.mutualsOf(userID, viewerID).Count(&count).Error
+	return count, err
+}
+
+// followersOf selects the follows of a user joined to the following users, without users blocked in either direction with the viewer
+func (r *FollowRepository) followersOf(userID, viewerID string) *gorm.DB {
+	return r.db.Table("follows").
+		Joins("JOIN users ON users.id = follows.follower_id AND users.deleted_at IS NULL").
+		Where("follows.following_id = ?", userID).
+		Scopes(ExcludeBlockedUsers("users.id", viewerID))
+}
+
+// followingOf selects the follows by a user joined to the followed users, without users blocked in either direction with the viewer
+func (r *FollowRepository) followingOf(userID, viewerID string) *gorm.DB {
+	return r.db.Table("follows").
+		Joins("JOIN users ON users.id = follows.following_id AND users.deleted_at IS NULL").
+		Where("follows.follower_id = ?", userID).
+		Scopes(ExcludeBlockedUsers("users.id", viewerID))
+}
+
+// mutualsOf narrows followersOf to the users the viewer follows
+func (r *FollowRepository) mutualsOf(userID, viewerID string) *gorm.DB {
+	return r.followersOf(userID, viewerID).
+		Where("EXISTS (SELECT 1 FROM follows AS vf WHERE vf.follower_id = ? AND vf.following_id = users.id)", viewerID)
+}
+
+// listFollows pages through a follows-join-users query. The viewer's relationship to every user
+// is computed in the same query rather than looked up per row.
+func (r *FollowRepository) listFollows(query *gorm.DB, viewerID string, cursor *FollowCursor, limit int) ([]FollowListEntry, error) {
+	columns := "follows.id AS follow_id, follows.created_at AS followed_at, users.id AS user_id, " +
+		"users.username, users.display_name, users.avatar_url, users.bio"
+	if viewerID != "" {
+		query = query.Select(columns+", "+
+			"EXISTS (SELECT 1 FROM follows AS vf WHERE vf.follower_id = ? AND vf.following_id = users.id) AS you_follow, "+
+			"EXISTS (SELECT 1 FROM follows AS vf WHERE vf.follower_id = users.id AND vf.following_id = ?) AS follows_you",
+			viewerID, viewerID)
+	} else {
+		query = query.Select(columns)
 	}
 
-	// Calculate follower/following counts for each user
-	for i := range users {
-		r.calculateFollowCounts(&users[i])
+	if cursor != nil {
+		query = query.Where("(follows.created_at, follows.id) < (?, ?)", cursor.CreatedAt, cursor.FollowID)
 	}
 
-	return users, nil
+	var entries []FollowListEntry
+	err := query.Order("follows.created_at DESC, follows.id DESC").
+		Limit(limit).
+		Scan(&entries).Error
+	return entries, err
 }
 
 // CountFollowers counts how many followers a user has
@@ -144,13 +255,156 @@ func (r *FollowRepository) ToggleFollow(followerID, followingID string) (bool, e
 	}
 }
 
-// calculateFollowCounts calculates and sets follower/following counts for a user
-func (r *FollowRepository) calculateFollowCounts(user *domain.User) {
-	// Get follower count
-	followerCount, _ := r.CountFollowers(user.ID)
-	user.FollowerCount = followerCount
+// CreateRequest records a pending follow request. Requesting again while a request is pending is a no-op.
+func (r *FollowRepository) CreateRequest(request *domain.FollowRequest) error {
+	if request.ID == "" {
+		request.ID = uuid.NewString()
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(request).Error
+}
 
-	// Get following count
-	followingCount, _ := r.CountFollowing(user.ID)
-	user.FollowingCount = followingCount
-}
\ No newline at end of file
+// GetRequestByID retrieves a follow request with both users
+func (r *FollowRepository) GetRequestByID(id string) (*domain.FollowRequest, error) {
+	var request domain.FollowRequest
+	err := r.db.Preload("Requester").Preload("Target").Where("id = ?", id).First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// RequestExists checks if a follow request is pending from the requester to the target
+func (r *FollowRepository) RequestExists(requesterID, targetID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.FollowRequest{}).Where("requester_id = ? AND target_id = ?", requesterID, targetID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteRequest removes a follow request. It reports whether a request existed.
+func (r *FollowRepository) DeleteRequest(id string) (bool, error) {
+	result := r.db.Where("id = ?", id).Delete(&domain.FollowRequest{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteRequestBetween removes the pending request from the requester to the target. It reports whether a request existed.
+func (r *FollowRepository) DeleteRequestBetween(requesterID, targetID string) (bool, error) {
+	result := r.db.Where("requester_id = ? AND target_id = ?", requesterID, targetID).Delete(&domain.FollowRequest{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ApproveRequest turns a follow request into a follow in one transaction
+func (r *FollowRepository) ApproveRequest(request *domain.FollowRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", request.ID).Delete(&domain.FollowRequest{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("follow request not found")
+		}
+
+		follow := &domain.Follow{
+			ID:          uuid.NewString(),
+			FollowerID:  request.RequesterID,
+			FollowingID: request.TargetID,
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(follow).Error
+	})
+}
+
+// ApproveAllRequests turns every pending request to the target into a follow. It is used when an
+// account stops being private and returns the approved requests.
+func (r *FollowRepository) ApproveAllRequests(targetID string) ([]domain.FollowRequest, error) {
+	var requests []domain.FollowRequest
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("target_id = ?", targetID).Find(&requests).Error; err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return nil
+		}
+
+		follows := make([]domain.Follow, len(requests))
+		for i, request := range requests {
+			follows[i] = domain.Follow{
+				ID:          uuid.NewString(),
+				FollowerID:  request.RequesterID,
+				FollowingID: targetID,
+			}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follows).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("target_id = ?", targetID).Delete(&domain.FollowRequest{}).Error
+	})
+	return requests, err
+}
+
+// GetIncomingRequests retrieves the pending requests to follow a user, oldest first
+func (r *FollowRepository) GetIncomingRequests(targetID string, page, limit int) ([]domain.FollowRequest, int64, error) {
+	return r.getRequests("target_id", targetID, "Requester", page, limit)
+}
+
+// GetOutgoingRequests retrieves the pending requests a user has made, most recent first
+func (r *FollowRepository) GetOutgoingRequests(requesterID string, page, limit int) ([]domain.FollowRequest, int64, error) {
+	return r.getRequests("requester_id", requesterID, "Target", page, limit)
+}
+
+// getRequests pages through follow requests filtered on one side of the relationship
+func (r *FollowRepository) getRequests(column, userID, preload string, page, limit int) ([]domain.FollowRequest, int64, error) {
+	var requests []domain.FollowRequest
+	var totalCount int64
+
+	query := r.db.Model(&domain.FollowRequest{}).Where(column+" = ?", userID)
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "created_at DESC"
+	if column == "target_id" {
+		order = "created_at ASC"
+	}
+
+	err := query.Preload(preload).
+		Order(order).
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&requests).Error
+	return requests, totalCount, err
+}
+
+// FriendsOfFriends scores the users followed by the people a user follows. The score is the
+// number of people the user follows who follow the candidate.
+func (r *FollowRepository) FriendsOfFriends(userID string, limit int) (map[string]float64, error) {
+	var rows []suggestion.ScoredUser
+	err := r.db.Table("follows AS f1").
+		Select("f2.following_id AS user_id, COUNT(*) AS score").
+		Joins("JOIN follows AS f2 ON f2.follower_id = f1.following_id").
+		Where("f1.follower_id = ? AND f2.following_id <> ?", userID, userID).
+		Group("f2.following_id").
+		Order("score DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return suggestion.ScoresByUser(rows), nil
+}
+
+// ExcludePrivateUsers is a query scope that drops rows whose column holds a private account the
+// viewer does not follow. The viewer's own rows are kept. An empty viewer ID drops every private account.
+func ExcludePrivateUsers(column, viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		sub := db.Session(&gorm.Session{NewDB: true})
+		private := sub.Model(&domain.User{}).Select("id").Where("is_private = ?", true)
+		if viewerID != "" {
+			followed := sub.Model(&domain.Follow{}).Select("following_id").Where("follower_id = ?", viewerID)
+			private = private.Where("id <> ? AND id NOT IN (?)", viewerID, followed)
+		}
+		return db.Where(column+" NOT IN (?)", private)
+	}
+}
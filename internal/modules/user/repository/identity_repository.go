@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastIdentity is returned when unlinking would leave a user with no way to sign in
+var ErrLastIdentity = errors.New("cannot unlink the only login provider")
+
+// IdentityRepository handles login identity data operations
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository creates a new identity repository instance
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// Create links a new identity to a user
+func (r *IdentityRepository) Create(identity *domain.UserIdentity) error {
+	if identity.ID == "" {
+		identity.ID = uuid.NewString()
+	}
+	return r.db.Create(identity).Error
+}
+
+// CreateWithUser creates a user and its first identity in a single transaction
+func (r *IdentityRepository) CreateWithUser(user *domain.User, identity *domain.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if identity.ID == "" {
+			identity.ID = uuid.NewString()
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// GetByProvider retrieves an identity by Provider and Provider ID
+func (r *IdentityRepository) GetByProvider(provider, providerID string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.Where("provider = ? AND provider_id = ?", provider, providerID).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUser retrieves all identities linked to a user
+func (r *IdentityRepository) GetByUser(userID string) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Update updates an identity
+func (r *IdentityRepository) Update(identity *domain.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+// DeleteForUser unlinks a provider from a user, refusing to remove the user's last identity
+func (r *IdentityRepository) DeleteForUser(userID, provider string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user's identities so two concurrent unlinks cannot both pass the count check
+		var identities []domain.UserIdentity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Find(&identities).Error; err != nil {
+			return err
+		}
+
+		found := false
+		for _, identity := range identities {
+			if identity.Provider == provider {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("identity not found")
+		}
+		if len(identities) == 1 {
+			return ErrLastIdentity
+		}
+
+		return tx.Where("user_id = ? AND provider = ?", userID, provider).Delete(&domain.UserIdentity{}).Error
+	})
+}
@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/topboyasante/pitstop/internal/modules/user/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository handles user data operations
@@ -34,6 +40,24 @@ func (r *UserRepository) GetByID(id string) (*domain.User, error) {
 	return &user, nil
 }
 
+// MembershipDays returns how many whole days have passed since a user joined
+func (r *UserRepository) MembershipDays(userID string) (int64, error) {
+	var user domain.User
+	if err := r.db.Select("created_at").Where("id = ?", userID).First(&user).Error; err != nil {
+		return 0, err
+	}
+	return int64(time.Since(user.CreatedAt) / (24 * time.Hour)), nil
+}
+
+// GetRole returns a user's role
+func (r *UserRepository) GetRole(userID string) (string, error) {
+	var user domain.User
+	if err := r.db.Select("role").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
 // GetByProviderID retrieves a user by Provider and Provider ID
 func (r *UserRepository) GetByProviderID(provider string, providerID string) (*domain.User, error) {
 	var user domain.User
@@ -47,29 +71,94 @@ func (r *UserRepository) GetByProviderID(provider string, providerID string) (*d
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(email string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByUsername retrieves a user by their current username
+func (r *UserRepository) GetByUsername(username string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Where("LOWER(username) = LOWER(?)", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
+
+	r.calculateFollowCounts(&user)
+
 	return &user, nil
 }
 
-// GetAll retrieves all users with pagination
-func (r *UserRepository) GetAll(page, limit int) ([]domain.User, int64, error) {
+// GetUsernameRedirect retrieves the redirect left behind by a previous username
+func (r *UserRepository) GetUsernameRedirect(username string) (*domain.UsernameRedirect, error) {
+	var redirect domain.UsernameRedirect
+	err := r.db.Where("username = ?", username).First(&redirect).Error
+	if err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// GetUsernameRedirectsByUser retrieves the previous usernames still held for a user
+func (r *UserRepository) GetUsernameRedirectsByUser(userID string) ([]domain.UsernameRedirect, error) {
+	var redirects []domain.UsernameRedirect
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&redirects).Error
+	return redirects, err
+}
+
+// UpdateWithUsernameChange saves a user whose username changed from previousUsername.
+// The old name becomes a redirect to the user, and any redirect holding the new name is released.
+func (r *UserRepository) UpdateWithUsernameChange(user *domain.User, previousUsername string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("username = ?", user.Username).Delete(&domain.UsernameRedirect{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("reputation").Save(user).Error; err != nil {
+			return err
+		}
+		if previousUsername == "" {
+			return nil
+		}
+
+		redirect := &domain.UsernameRedirect{
+			ID:       uuid.NewString(),
+			Username: previousUsername,
+			UserID:   user.ID,
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "username"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "created_at"}),
+		}).Create(redirect).Error
+	})
+}
+
+// Search finds users whose username or display name starts with the query, with pagination.
+// Users who blocked or were blocked by the viewer are left out.
+func (r *UserRepository) Search(query, viewerID string, page, limit int) ([]domain.User, int64, error) {
 	var users []domain.User
 	var totalCount int64
 
 	offset := (page - 1) * limit
 
+	// Escape LIKE wildcards so the query only matches literally
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(query)) + "%"
+	matches := r.db.Model(&domain.User{}).
+		Where("LOWER(username) LIKE ? OR LOWER(display_name) LIKE ?", pattern, pattern).
+		Where("id <> ?", domain.DeletedUserID).
+		Scopes(ExcludeBlockedUsers("id", viewerID))
+
 	// Get total count
-	if err := r.db.Model(&domain.User{}).Count(&totalCount).Error; err != nil {
+	if err := matches.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get users
-	if err := r.db.Offset(offset).
+	if err := matches.Session(&gorm.Session{}).
+		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
+		Order("username ASC").
 		Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
@@ -82,9 +171,99 @@ func (r *UserRepository) GetAll(page, limit int) ([]domain.User, int64, error) {
 	return users, totalCount, nil
 }
 
-// Update updates a user
+// GetSuggestible retrieves the given users that can be suggested to the viewer: not the viewer,
+// not already followed or requested, not blocked in either direction and not leaving the site.
+func (r *UserRepository) GetSuggestible(viewerID string, ids []string) ([]domain.User, error) {
+	var users []domain.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	sub := r.db.Session(&gorm.Session{NewDB: true})
+	followed := sub.Model(&domain.Follow{}).Select("following_id").Where("follower_id = ?", viewerID)
+	requested := sub.Model(&domain.FollowRequest{}).Select("target_id").Where("requester_id = ?", viewerID)
+
+	err := r.db.Where("id IN ?", ids).
+		Where("id <> ? AND id <> ?", viewerID, domain.DeletedUserID).
+		Where("deletion_requested_at IS NULL").
+		Where("id NOT IN (?) AND id NOT IN (?)", followed, requested).
+		Scopes(ExcludeBlockedUsers("id", viewerID)).
+		Find(&users).Error
+	return users, err
+}
+
+// Update updates a user. Reputation is left alone, only the reputation module changes it.
 func (r *UserRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit("reputation").Save(user).Error
+}
+
+// SetDeletionRequestedAt records or clears a pending account deletion. It reports whether the
+// value changed, so clearing an account without a pending deletion returns false.
+func (r *UserRepository) SetDeletionRequestedAt(userID string, requestedAt *time.Time) (bool, error) {
+	query := r.db.Model(&domain.User{}).Where("id = ?", userID)
+	if requestedAt == nil {
+		query = query.Where("deletion_requested_at IS NOT NULL")
+	} else {
+		query = query.Where("deletion_requested_at IS NULL")
+	}
+
+	result := query.UpdateColumn("deletion_requested_at", requestedAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDueForDeletion returns the IDs of accounts whose deletion was requested at or before the cutoff
+func (r *UserRepository) GetDueForDeletion(cutoff time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Unscoped().Model(&domain.User{}).
+		Where("deletion_requested_at IS NOT NULL AND deletion_requested_at <= ?", cutoff).
+		Order("deletion_requested_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// EraseAccount permanently deletes an account whose deletion was requested at or before the cutoff.
+// purge runs first in the same transaction to erase the data other modules hold for the user.
+// It returns false when the account no longer qualifies, for example because the user signed in.
+func (r *UserRepository) EraseAccount(userID string, cutoff time.Time, purge func(tx *gorm.DB) error) (bool, error) {
+	erased := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so a concurrent sign-in cannot cancel the deletion halfway through
+		var user domain.User
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_requested_at IS NOT NULL AND deletion_requested_at <= ?", userID, cutoff).
+			First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := purge(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Where("follower_id = ? OR following_id = ?", userID, userID).Delete(&domain.Follow{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("requester_id = ? OR target_id = ?", userID, userID).Delete(&domain.FollowRequest{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.UsernameRedirect{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("id = ?", userID).Delete(&domain.User{}).Error; err != nil {
+			return err
+		}
+
+		erased = true
+		return nil
+	})
+	return erased, err
 }
 
 // Delete soft deletes a user
@@ -8,18 +8,43 @@ import (
 )
 
 // RegisterRoutes registers all user-related routes
-func RegisterRoutes(router fiber.Router, userHandler *handler.UserHandler, followHandler *handler.FollowHandler) {
+func RegisterRoutes(router fiber.Router, userHandler *handler.UserHandler, followHandler *handler.FollowHandler, blockHandler *handler.BlockHandler, exportHandler *handler.ExportHandler, suggestionHandler *handler.SuggestionHandler) {
 	users := router.Group("/users")
-	
-	// Public routes
-	users.Get("/", userHandler.GetAllUsers)
-	users.Get("/:id", userHandler.GetUser)
-	users.Get("/:user_id/followers", followHandler.GetFollowers)
-	users.Get("/:user_id/following", followHandler.GetFollowing)
-	
+	jwt := middleware.JWTMiddleware(config.Get())
+	optionalJWT := middleware.OptionalJWTMiddleware(config.Get())
+	interactive := middleware.InteractiveOnly()
+
+	// Named routes are registered ahead of /:id. Profiles are public, but a signed-in
+	// viewer may see more fields depending on the user's visibility settings
+	users.Get("/", jwt, userHandler.SearchUsers)
+	users.Get("/me", jwt, userHandler.GetMe)
+	users.Get("/suggestions", jwt, suggestionHandler.GetSuggestions)
+	users.Get("/me/export", jwt, interactive, exportHandler.GetExport)
+	users.Get("/me/blocks", jwt, blockHandler.GetBlockedUsers)
+	users.Get("/me/mutes", jwt, blockHandler.GetMutedUsers)
+	users.Get("/me/follow-requests", jwt, followHandler.GetIncomingFollowRequests)
+	users.Get("/me/follow-requests/outgoing", jwt, followHandler.GetOutgoingFollowRequests)
+	users.Get("/exports/:id/download", exportHandler.DownloadExport)
+	users.Get("/username-available", optionalJWT, userHandler.CheckUsernameAvailability)
+	users.Get("/@:username", optionalJWT, userHandler.GetUserByUsername)
+	users.Get("/:id", optionalJWT, userHandler.GetUser)
+	users.Get("/:user_id/followers", optionalJWT, followHandler.GetFollowers)
+	users.Get("/:user_id/following", optionalJWT, followHandler.GetFollowing)
+
 	// Protected routes
-	protected := users.Group("", middleware.JWTMiddleware(config.Get()))
+	protected := users.Group("", jwt)
 	protected.Post("/", userHandler.CreateUser)
+	protected.Patch("/me", userHandler.UpdateMe)
+	protected.Delete("/me", interactive, userHandler.DeleteMe)
+	protected.Post("/me/export", interactive, exportHandler.RequestExport)
+	protected.Post("/me/follow-requests/:request_id/approve", followHandler.ApproveFollowRequest)
+	protected.Post("/me/follow-requests/:request_id/reject", followHandler.RejectFollowRequest)
+	protected.Delete("/me/follow-requests/:request_id", followHandler.CancelFollowRequest)
 	protected.Post("/:user_id/follow", followHandler.ToggleFollow)
 	protected.Get("/:user_id/follow/status", followHandler.CheckFollowStatus)
+	protected.Get("/:user_id/mutuals", followHandler.GetMutuals)
+	protected.Post("/:user_id/block", blockHandler.BlockUser)
+	protected.Delete("/:user_id/block", blockHandler.UnblockUser)
+	protected.Post("/:user_id/mute", blockHandler.MuteUser)
+	protected.Delete("/:user_id/mute", blockHandler.UnmuteUser)
 }
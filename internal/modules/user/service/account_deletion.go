@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/shared/events"
+	"gorm.io/gorm"
+)
+
+// deletionBatchSize caps how many accounts one sweep erases
+const deletionBatchSize = 100
+
+// AccountPurger erases or anonymizes the rows a module holds for a user. It runs inside the
+// account deletion transaction, so a failure rolls back the whole deletion and it is retried.
+type AccountPurger func(tx *gorm.DB, userID string) error
+
+// DeferredPurger is an AccountPurger that also returns cleanup to run once the deletion has
+// committed, such as deleting stored files the purged rows pointed at. Cleanup that ran inside
+// the transaction would be lost to a rollback, leaving rows that point at deleted files.
+type DeferredPurger func(tx *gorm.DB, userID string) (cleanup func(), err error)
+
+// SessionRevoker signs a user out of every session and token. Implemented by the auth module.
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID string) error
+}
+
+type namedPurger struct {
+	name  string
+	purge DeferredPurger
+}
+
+// RegisterAccountPurger adds a module's purger to the account deletion transaction
+func (s *UserService) RegisterAccountPurger(name string, purger AccountPurger) {
+	s.RegisterDeferredPurger(name, func(tx *gorm.DB, userID string) (func(), error) {
+		return nil, purger(tx, userID)
+	})
+}
+
+// RegisterDeferredPurger adds a module's purger to the account deletion transaction and runs
+// the cleanup it returns after the transaction commits
+func (s *UserService) RegisterDeferredPurger(name string, purger DeferredPurger) {
+	s.purgers = append(s.purgers, namedPurger{name: name, purge: purger})
+}
+
+// SetSessionRevoker registers what signs users out when they request deletion
+func (s *UserService) SetSessionRevoker(revoker SessionRevoker) {
+	s.sessionRevoker = revoker
+}
+
+// RequestDeletion schedules the account for erasure after the grace period and signs the user out
+// everywhere. Signing in again before then cancels the deletion. Repeated requests keep the first schedule.
+func (s *UserService) RequestDeletion(userID string) (*dto.AccountDeletionResponse, error) {
+	now := time.Now()
+	if _, err := s.userRepo.SetDeletionRequestedAt(userID, &now); err != nil {
+		return nil, fmt.Errorf("failed to request deletion: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if s.sessionRevoker != nil {
+		if err := s.sessionRevoker.RevokeUserSessions(context.Background(), userID); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Account deletion requested",
+		"event", "user.deletion_requested",
+		"user_id", userID,
+		"scheduled_at", user.DeletionRequestedAt.Add(s.config.Users.DeletionGracePeriod))
+
+	return &dto.AccountDeletionResponse{
+		DeletionRequestedAt: *user.DeletionRequestedAt,
+		DeletionScheduledAt: user.DeletionRequestedAt.Add(s.config.Users.DeletionGracePeriod),
+	}, nil
+}
+
+// CancelDeletion withdraws a pending deletion request. It reports whether one was pending.
+func (s *UserService) CancelDeletion(userID string) (bool, error) {
+	cancelled, err := s.userRepo.SetDeletionRequestedAt(userID, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	if cancelled {
+		logger.Info("Account deletion cancelled by sign-in",
+			"event", "user.deletion_cancelled",
+			"user_id", userID)
+	}
+	return cancelled, nil
+}
+
+// EraseDueAccounts erases accounts whose grace period has passed and returns how many were erased.
+// Each account is erased in its own transaction and then announced with an AccountDeleted event.
+func (s *UserService) EraseDueAccounts() (int, error) {
+	cutoff := time.Now().Add(-s.config.Users.DeletionGracePeriod)
+
+	ids, err := s.userRepo.GetDueForDeletion(cutoff, deletionBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find accounts due for deletion: %w", err)
+	}
+
+	erased := 0
+	for _, userID := range ids {
+		var cleanups []func()
+		ok, err := s.userRepo.EraseAccount(userID, cutoff, func(tx *gorm.DB) error {
+			cleanups = nil
+			for _, purger := range s.purgers {
+				cleanup, err := purger.purge(tx, userID)
+				if err != nil {
+					return fmt.Errorf("%s purge failed: %w", purger.name, err)
+				}
+				if cleanup != nil {
+					cleanups = append(cleanups, cleanup)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to erase account",
+				"event", "user.erase_failed",
+				"user_id", userID,
+				"error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		for _, cleanup := range cleanups {
+			cleanup()
+		}
+
+		erased++
+		logger.Info("Account erased",
+			"event", "user.account_erased",
+			"user_id", userID)
+		s.eventBus.Publish("AccountDeleted", events.NewAccountDeleted(userID))
+	}
+
+	return erased, nil
+}
+
+// StartDeletionSweeper erases due accounts on the given interval until stop is closed
+func (s *UserService) StartDeletionSweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.EraseDueAccounts(); err != nil {
+					logger.Error("Account deletion sweep failed", "error", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
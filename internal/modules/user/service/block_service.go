@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBlocked is returned when an interaction is refused because one user blocked the other
+	ErrBlocked = errors.New("interaction is not allowed between these users")
+	// ErrRelateToSelf is returned when a user tries to block or mute themselves
+	ErrRelateToSelf = errors.New("users cannot block or mute themselves")
+)
+
+// BlockService handles block and mute business logic
+type BlockService struct {
+	blockRepo *repository.BlockRepository
+	userRepo  *repository.UserRepository
+}
+
+// NewBlockService creates a new block service instance
+func NewBlockService(blockRepo *repository.BlockRepository, userRepo *repository.UserRepository) *BlockService {
+	return &BlockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// BlockUser blocks a user and removes any follow between the two of them
+func (s *BlockService) BlockUser(blockerID, blockedID string) error {
+	if err := s.checkTarget(blockerID, blockedID); err != nil {
+		return err
+	}
+
+	if err := s.blockRepo.Block(blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	logger.Info("User blocked", "event", "user.blocked", "blocker_id", blockerID, "blocked_id", blockedID)
+	return nil
+}
+
+// UnblockUser removes a block. Unblocking a user who is not blocked is a no-op.
+func (s *BlockService) UnblockUser(blockerID, blockedID string) error {
+	removed, err := s.blockRepo.Unblock(blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+
+	if removed {
+		logger.Info("User unblocked", "event", "user.unblocked", "blocker_id", blockerID, "blocked_id", blockedID)
+	}
+	return nil
+}
+
+// MuteUser hides a user's content from the muter
+func (s *BlockService) MuteUser(muterID, mutedID string) error {
+	if err := s.checkTarget(muterID, mutedID); err != nil {
+		return err
+	}
+
+	if err := s.blockRepo.Mute(muterID, mutedID); err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+	return nil
+}
+
+// UnmuteUser removes a mute. Unmuting a user who is not muted is a no-op.
+func (s *BlockService) UnmuteUser(muterID, mutedID string) error {
+	if _, err := s.blockRepo.Unmute(muterID, mutedID); err != nil {
+		return fmt.Errorf("failed to unmute user: %w", err)
+	}
+	return nil
+}
+
+// GetBlockedUsers lists the users a user has blocked
+func (s *BlockService) GetBlockedUsers(userID string, page, limit int) (*dto.RelatedUsersResponse, error) {
+	page, limit = normalizePage(page, limit)
+
+	blocks, totalCount, err := s.blockRepo.GetBlocked(userID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve blocked users: %w", err)
+	}
+
+	users := make([]dto.RelatedUserResponse, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Blocked != nil {
+			users = append(users, mapRelatedUser(block.Blocked, block.CreatedAt))
+		}
+	}
+
+	return newRelatedUsersResponse(users, len(blocks), totalCount, page, limit), nil
+}
+
+// GetMutedUsers lists the users a user has muted
+func (s *BlockService) GetMutedUsers(userID string, page, limit int) (*dto.RelatedUsersResponse, error) {
+	page, limit = normalizePage(page, limit)
+
+	mutes, totalCount, err := s.blockRepo.GetMuted(userID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve muted users: %w", err)
+	}
+
+	users := make([]dto.RelatedUserResponse, 0, len(mutes))
+	for _, mute := range mutes {
+		if mute.Muted != nil {
+			users = append(users, mapRelatedUser(mute.Muted, mute.CreatedAt))
+		}
+	}
+
+	return newRelatedUsersResponse(users, len(mutes), totalCount, page, limit), nil
+}
+
+// CheckInteraction returns ErrBlocked when either user has blocked the other. Other modules call it
+// before letting one user follow, comment on or reply to another.
+func (s *BlockService) CheckInteraction(userID, otherID string) error {
+	if userID == "" || otherID == "" || userID == otherID {
+		return nil
+	}
+
+	blocked, err := s.blockRepo.IsBlockedEither(userID, otherID)
+	if err != nil {
+		return fmt.Errorf("failed to check blocks: %w", err)
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// checkTarget rejects blocking or muting yourself or a user who does not exist
+func (s *BlockService) checkTarget(userID, targetID string) error {
+	if userID == targetID {
+		return ErrRelateToSelf
+	}
+
+	if _, err := s.userRepo.GetByID(targetID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+	return nil
+}
+
+// mapRelatedUser converts a blocked or muted user to its response DTO
+func mapRelatedUser(user *domain.User, since time.Time) dto.RelatedUserResponse {
+	return dto.RelatedUserResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Since:       since,
+	}
+}
+
+// newRelatedUsersResponse builds a page of blocked or muted users
+func newRelatedUsersResponse(users []dto.RelatedUserResponse, pageSize int, totalCount int64, page, limit int) *dto.RelatedUsersResponse {
+	return &dto.RelatedUsersResponse{
+		Users:      users,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		HasNext:    int64((page-1)*limit+pageSize) < totalCount,
+	}
+}
+
+// normalizePage applies the default page size and caps it at 50
+func normalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+	return page, limit
+}
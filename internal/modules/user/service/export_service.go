@@ -0,0 +1,459 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/mailer"
+	"github.com/topboyasante/pitstop/internal/core/storage"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrExportCooldown is returned when the previous export was requested too recently
+	ErrExportCooldown = errors.New("a data export was requested too recently")
+	// ErrExportLinkInvalid is returned for an unknown, expired or tampered download link
+	ErrExportLinkInvalid = errors.New("download link is invalid or has expired")
+	// ErrExportsDisabled is returned when data exports are turned off because no mail server is configured
+	ErrExportsDisabled = errors.New("data exports are turned off because no mail server is configured")
+)
+
+// exportExpiryBatchSize caps how many expired exports one sweep removes
+const exportExpiryBatchSize = 100
+
+// DataExporter returns the data a module holds for a user, keyed by file name inside the archive.
+// []byte values are stored as-is, which is how modules add attachments; anything else is written as JSON.
+type DataExporter func(userID string) (map[string]interface{}, error)
+
+type namedExporter struct {
+	name   string
+	export DataExporter
+}
+
+// ExportService builds personal data exports: a ZIP archive with everything held about a user
+type ExportService struct {
+	config      *config.Config
+	exportRepo  *repository.DataExportRepository
+	userRepo    *repository.UserRepository
+	followRepo  *repository.FollowRepository
+	userService *UserService
+	storage     storage.Storage
+	mailer      mailer.Mailer
+
+	exporters []namedExporter
+	// running holds the IDs of exports being built by this process
+	running sync.Map
+}
+
+// NewExportService creates a new export service instance
+func NewExportService(config *config.Config, exportRepo *repository.DataExportRepository, userRepo *repository.UserRepository, followRepo *repository.FollowRepository, userService *UserService, storage storage.Storage, mailer mailer.Mailer) *ExportService {
+	return &ExportService{
+		config:      config,
+		exportRepo:  exportRepo,
+		userRepo:    userRepo,
+		followRepo:  followRepo,
+		userService: userService,
+		storage:     storage,
+		mailer:      mailer,
+	}
+}
+
+// RegisterDataExporter adds a module's data to every export. The name is used in error messages only.
+func (s *ExportService) RegisterDataExporter(name string, exporter DataExporter) {
+	s.exporters = append(s.exporters, namedExporter{name: name, export: exporter})
+}
+
+// RequestExport starts building an export in the background. While an export is still being
+// built it is returned instead of starting another one.
+func (s *ExportService) RequestExport(userID string) (*dto.DataExportResponse, error) {
+	if !s.config.Mail.DataExportEnabled {
+		return nil, ErrExportsDisabled
+	}
+
+	latest, err := s.exportRepo.GetLatestForUser(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check previous exports: %w", err)
+	}
+	if latest != nil {
+		switch {
+		case latest.Status == domain.DataExportPending || latest.Status == domain.DataExportProcessing:
+			return mapExportToResponse(latest), nil
+		case latest.Status != domain.DataExportFailed && time.Since(latest.CreatedAt) < s.config.Users.ExportCooldown:
+			next := latest.CreatedAt.Add(s.config.Users.ExportCooldown)
+			return nil, fmt.Errorf("%w, next export allowed at %s", ErrExportCooldown, next.UTC().Format(time.RFC3339))
+		}
+	}
+
+	export := &domain.DataExport{
+		UserID: userID,
+		Status: domain.DataExportPending,
+	}
+	if err := s.exportRepo.Create(export); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	logger.Info("Data export requested",
+		"event", "user.export_requested",
+		"user_id", userID,
+		"export_id", export.ID)
+
+	go s.run(*export)
+
+	return mapExportToResponse(export), nil
+}
+
+// GetLatestExport returns the status of the user's most recent export
+func (s *ExportService) GetLatestExport(userID string) (*dto.DataExportResponse, error) {
+	export, err := s.exportRepo.GetLatestForUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("export not found: %w", err)
+	}
+	return mapExportToResponse(export), nil
+}
+
+// OpenDownload checks a download link and opens the archive. The caller must close the reader.
+func (s *ExportService) OpenDownload(exportID, token string) (io.ReadCloser, *domain.DataExport, error) {
+	export, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		return nil, nil, ErrExportLinkInvalid
+	}
+
+	hash := hashExportToken(token)
+	if export.Status != domain.DataExportReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) ||
+		subtle.ConstantTimeCompare([]byte(hash), []byte(export.DownloadTokenHash)) != 1 {
+		return nil, nil, ErrExportLinkInvalid
+	}
+
+	reader, err := s.storage.Open(context.Background(), export.BlobKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrExportLinkInvalid
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open export: %w", err)
+	}
+
+	return reader, export, nil
+}
+
+// ResumeUnfinished restarts exports that were interrupted, for example by a restart of the server
+func (s *ExportService) ResumeUnfinished() {
+	exports, err := s.exportRepo.GetUnfinished()
+	if err != nil {
+		logger.Error("Failed to load unfinished data exports", "error", err)
+		return
+	}
+
+	for _, export := range exports {
+		go s.run(export)
+	}
+}
+
+// ExpireDownloads deletes archives whose download link has expired and returns how many were removed
+func (s *ExportService) ExpireDownloads() (int, error) {
+	exports, err := s.exportRepo.GetExpired(time.Now(), exportExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired exports: %w", err)
+	}
+
+	expired := 0
+	for i := range exports {
+		export := &exports[i]
+		if err := s.storage.Delete(context.Background(), export.BlobKey); err != nil {
+			logger.Error("Failed to delete expired export",
+				"export_id", export.ID,
+				"error", err)
+			continue
+		}
+
+		export.Status = domain.DataExportExpired
+		export.BlobKey = ""
+		export.DownloadTokenHash = ""
+		if err := s.exportRepo.Update(export); err != nil {
+			return expired, fmt.Errorf("failed to expire export: %w", err)
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// StartExpirySweeper removes expired archives on the given interval until stop is closed
+func (s *ExportService) StartExpirySweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.ExpireDownloads(); err != nil {
+					logger.Error("Data export expiry sweep failed", "error", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// PurgeUser deletes a user's exports inside the account deletion transaction. Their archives are
+// deleted by the returned cleanup, once the transaction has committed.
+func (s *ExportService) PurgeUser(tx *gorm.DB, userID string) (func(), error) {
+	keys, err := s.exportRepo.PurgeUser(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		for _, key := range keys {
+			if err := s.storage.Delete(context.Background(), key); err != nil {
+				logger.Error("Failed to delete export archive of erased account", "user_id", userID, "key", key, "error", err)
+			}
+		}
+	}, nil
+}
+
+// run builds the archive, stores it and emails the download link
+func (s *ExportService) run(export domain.DataExport) {
+	if _, busy := s.running.LoadOrStore(export.ID, true); busy {
+		return
+	}
+	defer s.running.Delete(export.ID)
+
+	export.Status = domain.DataExportProcessing
+	if err := s.exportRepo.Update(&export); err != nil {
+		logger.Error("Failed to start data export", "export_id", export.ID, "error", err)
+		return
+	}
+
+	token, err := s.build(&export)
+	if err != nil {
+		logger.Error("Data export failed",
+			"event", "user.export_failed",
+			"user_id", export.UserID,
+			"export_id", export.ID,
+			"error", err)
+		export.Status = domain.DataExportFailed
+		export.Error = err.Error()
+		if err := s.exportRepo.Update(&export); err != nil {
+			logger.Error("Failed to record data export failure", "export_id", export.ID, "error", err)
+		}
+		return
+	}
+
+	logger.Info("Data export ready",
+		"event", "user.export_ready",
+		"user_id", export.UserID,
+		"export_id", export.ID,
+		"size", export.Size)
+
+	if err := s.notify(&export, token); err != nil {
+		logger.Error("Failed to send data export email", "export_id", export.ID, "error", err)
+	}
+}
+
+// build writes the archive to a temporary file, uploads it and marks the export ready.
+// It returns the plain download token.
+func (s *ExportService) build(export *domain.DataExport) (string, error) {
+	files, err := s.collect(export.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp("", "pitstop-export-*.zip")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := writeArchive(tmp, files); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	key := path.Join("exports", export.UserID, export.ID+".zip")
+	if err := s.storage.Put(context.Background(), key, tmp); err != nil {
+		return "", fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	token, err := generateExportToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.Users.ExportLinkTTL)
+	export.Status = domain.DataExportReady
+	export.BlobKey = key
+	export.DownloadTokenHash = hashExportToken(token)
+	export.Size = size
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	if err := s.exportRepo.Update(export); err != nil {
+		return "", fmt.Errorf("failed to save export: %w", err)
+	}
+
+	return token, nil
+}
+
+// collect gathers the user module's own data and every registered module's data
+func (s *ExportService) collect(userID string) (map[string]interface{}, error) {
+	profile, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.userService.GetIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := s.followRepo.GetFollowers(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve followers: %w", err)
+	}
+	following, err := s.followRepo.GetFollowing(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve following: %w", err)
+	}
+
+	redirects, err := s.userRepo.GetUsernameRedirectsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve previous usernames: %w", err)
+	}
+	previousUsernames := make([]string, len(redirects))
+	for i, redirect := range redirects {
+		previousUsernames[i] = redirect.Username
+	}
+
+	files := map[string]interface{}{
+		"profile.json":    profile,
+		"identities.json": identities,
+		"follows.json": map[string]interface{}{
+			"followers": exportFollowList(followers),
+			"following": exportFollowList(following),
+		},
+		"previous_usernames.json": previousUsernames,
+	}
+
+	for _, exporter := range s.exporters {
+		data, err := exporter.export(userID)
+		if err != nil {
+			return nil, fmt.Errorf("%s export failed: %w", exporter.name, err)
+		}
+		for name, content := range data {
+			files[name] = content
+		}
+	}
+
+	return files, nil
+}
+
+// notify emails the download link to the user
+func (s *ExportService) notify(export *domain.DataExport, token string) error {
+	user, err := s.userRepo.GetByID(export.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		logger.Warn("Data export ready but the user has no email address", "export_id", export.ID)
+		return nil
+	}
+
+	link := fmt.Sprintf("%s/api/v1/users/exports/%s/download?token=%s", s.config.Server.PublicURL, export.ID, token)
+	expires := export.ExpiresAt.UTC().Format("2 January 2006 15:04 MST")
+
+	return s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "Your Pitstop data export is ready",
+		Text: fmt.Sprintf("The copy of your Pitstop data you requested is ready:\n\n%s\n\nThe link works until %s. If you did not request this export, secure your account.",
+			link, expires),
+		HTML: fmt.Sprintf(`<p>The copy of your Pitstop data you requested is ready.</p><p><a href="%s">Download your data</a></p><p>The link works until %s. If you did not request this export, secure your account.</p>`,
+			html.EscapeString(link), expires),
+	})
+}
+
+// writeArchive writes the files as a ZIP archive
+func writeArchive(w io.Writer, files map[string]interface{}) error {
+	archive := zip.NewWriter(w)
+	for name, content := range files {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+
+		if raw, ok := content.([]byte); ok {
+			_, err = f.Write(raw)
+		} else {
+			encoder := json.NewEncoder(f)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(content)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// exportFollowList keeps the identifying fields of the users on either side of a follow
+func exportFollowList(users []domain.User) []map[string]string {
+	list := make([]map[string]string, len(users))
+	for i, user := range users {
+		list[i] = map[string]string{"id": user.ID, "username": user.Username}
+	}
+	return list
+}
+
+// generateExportToken returns a random download token
+func generateExportToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate download token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashExportToken returns the SHA-256 hash stored for a download token
+func hashExportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// mapExportToResponse converts a data export to its response DTO
+func mapExportToResponse(export *domain.DataExport) *dto.DataExportResponse {
+	return &dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		Size:        export.Size,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
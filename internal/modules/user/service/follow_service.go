@@ -1,34 +1,53 @@
 package service
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
 	"github.com/topboyasante/pitstop/internal/modules/user/dto"
 	"github.com/topboyasante/pitstop/internal/modules/user/repository"
 	"github.com/topboyasante/pitstop/internal/shared/events"
 	"gorm.io/gorm"
 )
 
+// ErrInvalidCursor is returned when a follow list cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrPrivateAccount is returned when a private account's content or follow lists are requested by someone who does not follow it
+var ErrPrivateAccount = errors.New("this account is private")
+
 // FollowService handles follow business logic
 type FollowService struct {
-	followRepo *repository.FollowRepository
-	userRepo   *repository.UserRepository
-	eventBus   *events.EventBus
+	followRepo   *repository.FollowRepository
+	userRepo     *repository.UserRepository
+	blockService *BlockService
+	eventBus     *events.EventBus
 }
 
 // NewFollowService creates a new follow service instance
-func NewFollowService(followRepo *repository.FollowRepository, userRepo *repository.UserRepository, eventBus *events.EventBus) *FollowService {
+func NewFollowService(followRepo *repository.FollowRepository, userRepo *repository.UserRepository, blockService *BlockService, eventBus *events.EventBus) *FollowService {
 	return &FollowService{
-		followRepo: followRepo,
-		userRepo:   userRepo,
-		eventBus:   eventBus,
+		followRepo:   followRepo,
+		userRepo:     userRepo,
+		blockService: blockService,
+		eventBus:     eventBus,
 	}
 }
 
-// ToggleFollow toggles a follow relationship (follow/unfollow)
+// ToggleFollow toggles a follow relationship (follow/unfollow). Following a private account
+// creates a follow request instead, and toggling again while it is pending cancels the request.
 func (s *FollowService) ToggleFollow(followerID, followingID string) (*dto.FollowToggleResponse, error) {
+	// Prevent self-following
+	if followerID == followingID {
+		return nil, errors.New("users cannot follow themselves")
+	}
+
 	// Check if the user being followed exists
 	followingUser, err := s.userRepo.GetByID(followingID)
 	if err != nil {
@@ -38,11 +57,41 @@ func (s *FollowService) ToggleFollow(followerID, followingID string) (*dto.Follo
 		return nil, fmt.Errorf("failed to check user: %w", err)
 	}
 
-	// Toggle the follow
-	isFollowing, err := s.followRepo.ToggleFollow(followerID, followingID)
+	// Blocked users have no follows between them, so this only ever refuses a new follow
+	if err := s.blockService.CheckInteraction(followerID, followingID); err != nil {
+		return nil, err
+	}
+
+	isFollowing, err := s.followRepo.Exists(followerID, followingID)
 	if err != nil {
-		logger.Error("Failed to toggle follow", "follower_id", followerID, "following_id", followingID, "error", err)
-		return nil, fmt.Errorf("failed to toggle follow: %w", err)
+		return nil, fmt.Errorf("failed to check follow status: %w", err)
+	}
+
+	isRequested := false
+	action := ""
+	if followingUser.IsPrivate && !isFollowing {
+		isRequested, err = s.toggleRequest(followerID, followingID)
+		if err != nil {
+			logger.Error("Failed to toggle follow request", "follower_id", followerID, "following_id", followingID, "error", err)
+			return nil, fmt.Errorf("failed to toggle follow request: %w", err)
+		}
+		action = "cancelled the follow request to"
+		if isRequested {
+			action = "requested to follow"
+		}
+	} else {
+		// Toggle the follow
+		isFollowing, err = s.followRepo.ToggleFollow(followerID, followingID)
+		if err != nil {
+			logger.Error("Failed to toggle follow", "follower_id", followerID, "following_id", followingID, "error", err)
+			return nil, fmt.Errorf("failed to toggle follow: %w", err)
+		}
+		action = "followed"
+		if !isFollowing {
+			action = "unfollowed"
+		} else {
+			s.eventBus.Publish("UserFollowed", events.NewUserFollowed(followerID, followingID))
+		}
 	}
 
 	// Get updated counts
@@ -59,86 +108,259 @@ func (s *FollowService) ToggleFollow(followerID, followingID string) (*dto.Follo
 	}
 
 	// Log the action
-	action := "followed"
-	if !isFollowing {
-		action = "unfollowed"
-	}
 	logger.Info(fmt.Sprintf("User %s %s", action, followingUser.Username), "follower_id", followerID, "following_id", followingID)
 
 	return &dto.FollowToggleResponse{
 		IsFollowing:    isFollowing,
+		IsRequested:    isRequested,
 		FollowerCount:  followerCount,
 		FollowingCount: followingCount,
 	}, nil
 }
 
-// GetFollowers retrieves all followers for a user
-func (s *FollowService) GetFollowers(userID string) (*dto.FollowersResponse, error) {
-	// Check if user exists
-	_, err := s.userRepo.GetByID(userID)
+// toggleRequest cancels a pending follow request or creates one. It reports whether a request is now pending.
+func (s *FollowService) toggleRequest(requesterID, targetID string) (bool, error) {
+	cancelled, err := s.followRepo.DeleteRequestBetween(requesterID, targetID)
+	if err != nil {
+		return false, err
+	}
+	if cancelled {
+		return false, nil
+	}
+
+	request := &domain.FollowRequest{
+		RequesterID: requesterID,
+		TargetID:    targetID,
+	}
+	if err := s.followRepo.CreateRequest(request); err != nil {
+		return false, err
+	}
+
+	s.eventBus.Publish("FollowRequested", events.NewFollowRequested(request.ID, requesterID, targetID))
+	return true, nil
+}
+
+// ApproveFollowRequest approves a request to follow the user. Only the requested account can approve it.
+func (s *FollowService) ApproveFollowRequest(userID, requestID string) error {
+	request, err := s.getRequest(requestID, func(r *domain.FollowRequest) bool { return r.TargetID == userID })
+	if err != nil {
+		return err
+	}
+
+	if err := s.followRepo.ApproveRequest(request); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return err
+		}
+		return fmt.Errorf("failed to approve follow request: %w", err)
+	}
+
+	logger.Info("Follow request approved", "event", "user.follow_request_approved", "request_id", request.ID,
+		"requester_id", request.RequesterID, "target_id", request.TargetID)
+	s.eventBus.Publish("FollowRequestApproved", events.NewFollowRequestApproved(request.ID, request.RequesterID, request.TargetID))
+	return nil
+}
+
+// RejectFollowRequest rejects a request to follow the user. The requester is not notified.
+func (s *FollowService) RejectFollowRequest(userID, requestID string) error {
+	request, err := s.getRequest(requestID, func(r *domain.FollowRequest) bool { return r.TargetID == userID })
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.followRepo.DeleteRequest(request.ID); err != nil {
+		return fmt.Errorf("failed to reject follow request: %w", err)
+	}
+
+	logger.Info("Follow request rejected", "event", "user.follow_request_rejected", "request_id", request.ID,
+		"requester_id", request.RequesterID, "target_id", request.TargetID)
+	return nil
+}
+
+// CancelFollowRequest withdraws a follow request the user made
+func (s *FollowService) CancelFollowRequest(userID, requestID string) error {
+	request, err := s.getRequest(requestID, func(r *domain.FollowRequest) bool { return r.RequesterID == userID })
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.followRepo.DeleteRequest(request.ID); err != nil {
+		return fmt.Errorf("failed to cancel follow request: %w", err)
+	}
+
+	logger.Info("Follow request cancelled", "event", "user.follow_request_cancelled", "request_id", request.ID,
+		"requester_id", request.RequesterID, "target_id", request.TargetID)
+	return nil
+}
+
+// getRequest loads a follow request the user is a party to. Requests belonging to
+// other users are reported as not found so their existence is not revealed.
+func (s *FollowService) getRequest(requestID string, allowed func(*domain.FollowRequest) bool) (*domain.FollowRequest, error) {
+	request, err := s.followRepo.GetRequestByID(requestID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("follow request not found")
 		}
-		return nil, fmt.Errorf("failed to check user: %w", err)
+		return nil, fmt.Errorf("failed to retrieve follow request: %w", err)
+	}
+	if !allowed(request) {
+		return nil, fmt.Errorf("follow request not found")
 	}
+	return request, nil
+}
+
+// GetIncomingFollowRequests retrieves the pending requests to follow the user, oldest first
+func (s *FollowService) GetIncomingFollowRequests(userID string, page, limit int) (*dto.FollowRequestsResponse, error) {
+	page, limit = normalizePage(page, limit)
 
-	// Get followers
-	followers, err := s.followRepo.GetFollowers(userID)
+	requests, totalCount, err := s.followRepo.GetIncomingRequests(userID, page, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve followers: %w", err)
+		return nil, fmt.Errorf("failed to retrieve follow requests: %w", err)
 	}
 
-	// Convert to response DTOs
-	followerResponses := make([]dto.FollowUserResponse, len(followers))
-	for i, follower := range followers {
-		followerResponses[i] = dto.FollowUserResponse{
-			ID:          follower.ID,
-			Username:    follower.Username,
-			DisplayName: follower.DisplayName,
-			AvatarURL:   follower.AvatarURL,
-			Bio:         follower.Bio,
+	responses := make([]dto.FollowRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		if request.Requester != nil {
+			responses = append(responses, mapFollowRequest(&request, request.Requester))
 		}
 	}
 
-	return &dto.FollowersResponse{
-		Followers:  followerResponses,
-		TotalCount: int64(len(followerResponses)),
-	}, nil
+	return newFollowRequestsResponse(responses, len(requests), totalCount, page, limit), nil
 }
 
-// GetFollowing retrieves all users that a user is following
-func (s *FollowService) GetFollowing(userID string) (*dto.FollowingResponse, error) {
-	// Check if user exists
-	_, err := s.userRepo.GetByID(userID)
+// GetOutgoingFollowRequests retrieves the pending requests the user has made, most recent first
+func (s *FollowService) GetOutgoingFollowRequests(userID string, page, limit int) (*dto.FollowRequestsResponse, error) {
+	page, limit = normalizePage(page, limit)
+
+	requests, totalCount, err := s.followRepo.GetOutgoingRequests(userID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve follow requests: %w", err)
+	}
+
+	responses := make([]dto.FollowRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		if request.Target != nil {
+			responses = append(responses, mapFollowRequest(&request, request.Target))
+		}
+	}
+
+	return newFollowRequestsResponse(responses, len(requests), totalCount, page, limit), nil
+}
+
+// CanViewContent reports whether the viewer may see content owned by a user. Public accounts
+// are visible to everyone; a private account only to itself and its approved followers.
+func (s *FollowService) CanViewContent(viewerID, ownerID string) (bool, error) {
+	if viewerID != "" && viewerID == ownerID {
+		return true, nil
+	}
+
+	owner, err := s.userRepo.GetByID(ownerID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user not found")
+			return false, fmt.Errorf("user not found")
 		}
-		return nil, fmt.Errorf("failed to check user: %w", err)
+		return false, fmt.Errorf("failed to check user: %w", err)
+	}
+	if !owner.IsPrivate {
+		return true, nil
+	}
+	if viewerID == "" {
+		return false, nil
+	}
+
+	return s.CheckUserFollowing(viewerID, ownerID)
+}
+
+// GetFollowers retrieves a page of a user's followers, newest first. The followers of a private
+// account are only listed to the account and its followers.
+func (s *FollowService) GetFollowers(userID, viewerID, cursor string, limit int) (*dto.FollowersResponse, error) {
+	// Check if user exists and the viewer may see who follows them
+	if err := s.checkListVisible(userID, viewerID); err != nil {
+		return nil, err
+	}
+
+	after, limit, err := parseFollowPage(cursor, limit)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get following
-	following, err := s.followRepo.GetFollowing(userID)
+	entries, err := s.followRepo.ListFollowers(userID, viewerID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve followers: %w", err)
+	}
+
+	totalCount, err := s.followRepo.CountListedFollowers(userID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count followers: %w", err)
+	}
+
+	users, nextCursor := mapFollowPage(entries, viewerID, limit)
+	return &dto.FollowersResponse{
+		Followers:  users,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+		HasNext:    nextCursor != "",
+	}, nil
+}
+
+// GetFollowing retrieves a page of the users a user follows, newest first. For a private account
+// the list is only shown to the account and its followers.
+func (s *FollowService) GetFollowing(userID, viewerID, cursor string, limit int) (*dto.FollowingResponse, error) {
+	// Check if user exists and the viewer may see who they follow
+	if err := s.checkListVisible(userID, viewerID); err != nil {
+		return nil, err
+	}
+
+	after, limit, err := parseFollowPage(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.followRepo.ListFollowing(userID, viewerID, after, limit+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve following: %w", err)
 	}
 
-	// Convert to response DTOs
-	followingResponses := make([]dto.FollowUserResponse, len(following))
-	for i, user := range following {
-		followingResponses[i] = dto.FollowUserResponse{
-			ID:          user.ID,
-			Username:    user.Username,
-			DisplayName: user.DisplayName,
-			AvatarURL:   user.AvatarURL,
-			Bio:         user.Bio,
-		}
+	totalCount, err := s.followRepo.CountListedFollowing(userID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count following: %w", err)
 	}
 
+	users, nextCursor := mapFollowPage(entries, viewerID, limit)
 	return &dto.FollowingResponse{
-		Following:  followingResponses,
-		TotalCount: int64(len(followingResponses)),
+		Following:  users,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+		HasNext:    nextCursor != "",
+	}, nil
+}
+
+// GetMutuals retrieves a page of a user's followers that the viewer also follows, newest first
+func (s *FollowService) GetMutuals(userID, viewerID, cursor string, limit int) (*dto.MutualsResponse, error) {
+	if err := s.checkListVisible(userID, viewerID); err != nil {
+		return nil, err
+	}
+
+	after, limit, err := parseFollowPage(cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.followRepo.ListMutuals(userID, viewerID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mutuals: %w", err)
+	}
+
+	totalCount, err := s.followRepo.CountMutuals(userID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count mutuals: %w", err)
+	}
+
+	users, nextCursor := mapFollowPage(entries, viewerID, limit)
+	return &dto.MutualsResponse{
+		Mutuals:    users,
+		TotalCount: totalCount,
+		NextCursor: nextCursor,
+		HasNext:    nextCursor != "",
 	}, nil
 }
 
@@ -149,4 +371,98 @@ func (s *FollowService) CheckUserFollowing(followerID, followingID string) (bool
 		return false, fmt.Errorf("failed to check follow status: %w", err)
 	}
 	return exists, nil
-}
\ No newline at end of file
+}
+
+// checkListVisible returns ErrPrivateAccount when the viewer may not see a user's follow lists
+func (s *FollowService) checkListVisible(userID, viewerID string) error {
+	visible, err := s.CanViewContent(viewerID, userID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrPrivateAccount
+	}
+	return nil
+}
+
+// mapFollowRequest converts a follow request to its response, showing the other party
+func mapFollowRequest(request *domain.FollowRequest, user *domain.User) dto.FollowRequestResponse {
+	return dto.FollowRequestResponse{
+		ID: request.ID,
+		User: dto.FollowUserResponse{
+			ID:          user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			AvatarURL:   user.AvatarURL,
+			Bio:         user.Bio,
+		},
+		CreatedAt: request.CreatedAt,
+	}
+}
+
+// newFollowRequestsResponse builds a page of follow requests
+func newFollowRequestsResponse(requests []dto.FollowRequestResponse, pageSize int, totalCount int64, page, limit int) *dto.FollowRequestsResponse {
+	return &dto.FollowRequestsResponse{
+		Requests:   requests,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		HasNext:    int64((page-1)*limit+pageSize) < totalCount,
+	}
+}
+
+// parseFollowPage decodes a follow list cursor and applies the default page size. An empty cursor starts at the newest follow.
+func parseFollowPage(cursor string, limit int) (*repository.FollowCursor, int, error) {
+	_, limit = normalizePage(1, limit)
+	if cursor == "" {
+		return nil, limit, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	nanos, followID, ok := strings.Cut(string(raw), "|")
+	if !ok || followID == "" {
+		return nil, 0, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	return &repository.FollowCursor{CreatedAt: time.Unix(0, unixNano).UTC(), FollowID: followID}, limit, nil
+}
+
+// encodeFollowCursor returns the cursor of the page that starts after an entry
+func encodeFollowCursor(entry repository.FollowListEntry) string {
+	raw := fmt.Sprintf("%d|%s", entry.FollowedAt.UnixNano(), entry.FollowID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// mapFollowPage converts up to limit entries to responses. The query asks for one entry more than
+// the page holds; when it is there, the cursor of the next page is returned.
+func mapFollowPage(entries []repository.FollowListEntry, viewerID string, limit int) ([]dto.FollowUserResponse, string) {
+	nextCursor := ""
+	if len(entries) > limit {
+		entries = entries[:limit]
+		nextCursor = encodeFollowCursor(entries[limit-1])
+	}
+
+	users := make([]dto.FollowUserResponse, len(entries))
+	for i, entry := range entries {
+		users[i] = dto.FollowUserResponse{
+			ID:          entry.UserID,
+			Username:    entry.Username,
+			DisplayName: entry.DisplayName,
+			AvatarURL:   entry.AvatarURL,
+			Bio:         entry.Bio,
+		}
+		if viewerID != "" {
+			youFollow, followsYou := entry.YouFollow, entry.FollowsYou
+			users[i].YouFollow = &youFollow
+			users[i].FollowsYou = &followsYou
+		}
+	}
+	return users, nextCursor
+}
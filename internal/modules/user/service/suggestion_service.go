@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/modules/user/domain"
+	"github.com/topboyasante/pitstop/internal/modules/user/dto"
+	"github.com/topboyasante/pitstop/internal/modules/user/repository"
+)
+
+// SuggestionSource scores candidate users to suggest to a user, returning at most limit
+// candidates keyed by user ID. Higher scores are stronger; scores are only compared within
+// one source. Sources need not exclude anyone: followed, requested and blocked users are
+// dropped afterwards.
+type SuggestionSource func(userID string, limit int) (map[string]float64, error)
+
+// Suggestion reasons of the signals the user module ranks by itself
+const (
+	ReasonFollowedByFollowing = "followed_by_people_you_follow"
+)
+
+const (
+	// suggestionCandidates is how many candidates each source contributes and how many ranked suggestions are cached
+	suggestionCandidates = 100
+	defaultSuggestions   = 10
+	maxSuggestions       = 50
+)
+
+type namedSource struct {
+	reason string
+	weight float64
+	source SuggestionSource
+}
+
+// rankedSuggestion is one cached entry of a user's ranking
+type rankedSuggestion struct {
+	UserID  string   `json:"user_id"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// SuggestionService recommends users to follow. Each source's scores are scaled to 0..1 and
+// added up by weight, and the ranking is cached per user in Redis.
+type SuggestionService struct {
+	config     *config.Config
+	redis      *redis.Client
+	followRepo *repository.FollowRepository
+	userRepo   *repository.UserRepository
+
+	sources []namedSource
+}
+
+// NewSuggestionService creates a new suggestion service instance ranking by friends-of-friends.
+// Other modules add their signals with RegisterSuggestionSource.
+func NewSuggestionService(config *config.Config, redis *redis.Client, followRepo *repository.FollowRepository, userRepo *repository.UserRepository) *SuggestionService {
+	s := &SuggestionService{
+		config:     config,
+		redis:      redis,
+		followRepo: followRepo,
+		userRepo:   userRepo,
+	}
+	s.RegisterSuggestionSource(ReasonFollowedByFollowing, 3, followRepo.FriendsOfFriends)
+	return s
+}
+
+// RegisterSuggestionSource adds a ranking signal. The reason is reported on the users the source
+// contributed to; sources registered under the same reason share it.
+func (s *SuggestionService) RegisterSuggestionSource(reason string, weight float64, source SuggestionSource) {
+	s.sources = append(s.sources, namedSource{reason: reason, weight: weight, source: source})
+}
+
+// GetSuggestions returns up to limit users the user may want to follow, best first
+func (s *SuggestionService) GetSuggestions(userID string, limit int) ([]dto.SuggestedUserResponse, error) {
+	if limit < 1 || limit > maxSuggestions {
+		limit = defaultSuggestions
+	}
+
+	ranking, err := s.getRanking(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached ranking may be older than the user's latest follows and blocks, so it is filtered on every read
+	ids := make([]string, len(ranking))
+	for i, suggestion := range ranking {
+		ids[i] = suggestion.UserID
+	}
+	users, err := s.userRepo.GetSuggestible(userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve suggested users: %w", err)
+	}
+	byID := make(map[string]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	suggestions := make([]dto.SuggestedUserResponse, 0, limit)
+	for _, suggestion := range ranking {
+		user, ok := byID[suggestion.UserID]
+		if !ok {
+			continue
+		}
+		suggestions = append(suggestions, dto.SuggestedUserResponse{
+			ID:          user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			AvatarURL:   user.AvatarURL,
+			Bio:         user.Bio,
+			Reasons:     suggestion.Reasons,
+		})
+		if len(suggestions) == limit {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+// getRanking returns the user's cached ranking, computing and caching it when missing
+func (s *SuggestionService) getRanking(userID string) ([]rankedSuggestion, error) {
+	ctx := context.Background()
+	key := suggestionsKey(userID)
+
+	cached, err := s.redis.Get(ctx, key).Bytes()
+	if err == nil {
+		var ranking []rankedSuggestion
+		if err := json.Unmarshal(cached, &ranking); err == nil {
+			return ranking, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		logger.Warn("Failed to read cached suggestions", "user_id", userID, "error", err)
+	}
+
+	ranking, err := s.rank(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(ranking); err == nil {
+		if err := s.redis.Set(ctx, key, data, s.config.Users.SuggestionsCacheTTL).Err(); err != nil {
+			logger.Warn("Failed to cache suggestions", "user_id", userID, "error", err)
+		}
+	}
+	return ranking, nil
+}
+
+// rank combines every source into the user's ranking. A failing source is logged and skipped
+// so one module cannot take suggestions down.
+func (s *SuggestionService) rank(userID string) ([]rankedSuggestion, error) {
+	scores := make(map[string]float64)
+	contributions := make(map[string]map[string]float64)
+
+	for _, named := range s.sources {
+		candidates, err := named.source(userID, suggestionCandidates)
+		if err != nil {
+			logger.Error("Suggestion source failed", "reason", named.reason, "user_id", userID, "error", err)
+			continue
+		}
+
+		top := 0.0
+		for _, score := range candidates {
+			if score > top {
+				top = score
+			}
+		}
+		if top <= 0 {
+			continue
+		}
+
+		for candidateID, score := range candidates {
+			weighted := named.weight * score / top
+			scores[candidateID] += weighted
+			if contributions[candidateID] == nil {
+				contributions[candidateID] = make(map[string]float64)
+			}
+			contributions[candidateID][named.reason] += weighted
+		}
+	}
+
+	// Drop candidates that can never be suggested before keeping the best ones
+	ids := make([]string, 0, len(scores))
+	for candidateID := range scores {
+		ids = append(ids, candidateID)
+	}
+	users, err := s.userRepo.GetSuggestible(userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter suggested users: %w", err)
+	}
+
+	ranking := make([]rankedSuggestion, 0, len(users))
+	for _, user := range users {
+		ranking = append(ranking, rankedSuggestion{
+			UserID:  user.ID,
+			Score:   scores[user.ID],
+			Reasons: sortedReasons(contributions[user.ID]),
+		})
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score > ranking[j].Score
+		}
+		return ranking[i].UserID < ranking[j].UserID
+	})
+	if len(ranking) > suggestionCandidates {
+		ranking = ranking[:suggestionCandidates]
+	}
+
+	return ranking, nil
+}
+
+// sortedReasons orders reasons by how much they contributed to a candidate's score
+func sortedReasons(contributions map[string]float64) []string {
+	reasons := make([]string, 0, len(contributions))
+	for reason := range contributions {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if contributions[reasons[i]] != contributions[reasons[j]] {
+			return contributions[reasons[i]] > contributions[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	return reasons
+}
+
+// suggestionsKey is the Redis key holding a user's cached ranking
+func suggestionsKey(userID string) string {
+	return fmt.Sprintf("user:suggestions:%s", userID)
+}
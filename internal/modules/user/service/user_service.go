@@ -1,35 +1,63 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 	"github.com/topboyasante/pitstop/internal/modules/user/domain"
 	"github.com/topboyasante/pitstop/internal/modules/user/dto"
 	"github.com/topboyasante/pitstop/internal/modules/user/repository"
 	"github.com/topboyasante/pitstop/internal/shared/events"
 	"github.com/topboyasante/pitstop/internal/shared/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrEmailInUse is returned when a new provider identity reports an email owned by
+	// another account and the address is not verified on both sides
+	ErrEmailInUse = errors.New("email already registered with another provider")
+	// ErrIdentityTaken is returned when a provider identity is linked to a different user
+	ErrIdentityTaken = errors.New("identity already linked to another account")
+	// ErrProviderLinked is returned when the user already has an identity for the provider
+	ErrProviderLinked = errors.New("provider already linked")
+	// ErrLastIdentity is returned when unlinking would leave the user unable to sign in
+	ErrLastIdentity = repository.ErrLastIdentity
 )
 
 // UserService handles user business logic
 type UserService struct {
-	userRepo  *repository.UserRepository
-	validator *validator.Validate
-	eventBus  *events.EventBus
+	config       *config.Config
+	userRepo     *repository.UserRepository
+	identityRepo *repository.IdentityRepository
+	followRepo   *repository.FollowRepository
+	validator    *validator.Validate
+	eventBus     *events.EventBus
+
+	purgers        []namedPurger
+	sessionRevoker SessionRevoker
 }
 
 // NewUserService creates a new user service instance
-func NewUserService(userRepo *repository.UserRepository, validator *validator.Validate, eventBus *events.EventBus) *UserService {
+func NewUserService(config *config.Config, userRepo *repository.UserRepository, identityRepo *repository.IdentityRepository, followRepo *repository.FollowRepository, validator *validator.Validate, eventBus *events.EventBus) *UserService {
 	return &UserService{
-		userRepo:  userRepo,
-		validator: validator,
-		eventBus:  eventBus,
+		config:       config,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		followRepo:   followRepo,
+		validator:    validator,
+		eventBus:     eventBus,
 	}
 }
 
-// CreateUser creates a user from OAuth provider data
+// CreateUser signs a user in from OAuth provider data. The provider identity is
+// looked up first; an unknown identity is merged into an existing account only
+// when both sides have verified the same email, otherwise a new user is created.
 func (s *UserService) CreateUser(req dto.CreateUserRequest) (*dto.UserResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		logger.Error("OAuth user validation failed",
@@ -39,15 +67,45 @@ func (s *UserService) CreateUser(req dto.CreateUserRequest) (*dto.UserResponse,
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Check if user already exists by provider ID
-	existingUser, err := s.userRepo.GetByProviderID(req.Provider, req.ProviderID)
+	// Check if the provider identity is already linked to a user
+	identity, err := s.identityRepo.GetByProvider(req.Provider, req.ProviderID)
 	if err == nil {
+		existingUser, err := s.userRepo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+
+		// Keep the provider's view of the email current so later merges see it
+		if identity.Email != req.Email || identity.EmailVerified != req.EmailVerified {
+			identity.Email = req.Email
+			identity.EmailVerified = req.EmailVerified
+			if err := s.identityRepo.Update(identity); err != nil {
+				logger.Warn("Failed to refresh identity email",
+					"event", "user.identity_refresh_failed",
+					"provider", req.Provider,
+					"user_id", existingUser.ID,
+					"error", err)
+			}
+		}
+
 		logger.Info("Existing OAuth user found",
 			"event", "user.oauth_login",
 			"provider", req.Provider,
 			"user_id", existingUser.ID)
 		return s.mapUserToResponse(existingUser), nil
 	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up identity: %w", err)
+	}
+
+	// A different provider may already own this email
+	existingUser, err := s.userRepo.GetByEmail(req.Email)
+	if err == nil {
+		return s.mergeIdentity(existingUser, req)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
 
 	// Create new user from OAuth data
 	user := &domain.User{
@@ -60,9 +118,17 @@ func (s *UserService) CreateUser(req dto.CreateUserRequest) (*dto.UserResponse,
 		Email:      req.Email,
 		AvatarURL:  req.AvatarURL,
 		Locale:     req.Locale,
+		Role:       domain.RoleUser,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	identity = &domain.UserIdentity{
+		Provider:      req.Provider,
+		ProviderID:    req.ProviderID,
+		Email:         req.Email,
+		EmailVerified: req.EmailVerified,
+	}
+
+	if err := s.identityRepo.CreateWithUser(user, identity); err != nil {
 		logger.Error("Failed to create OAuth user",
 			"event", "user.oauth_create_failed",
 			"provider", req.Provider,
@@ -78,6 +144,145 @@ func (s *UserService) CreateUser(req dto.CreateUserRequest) (*dto.UserResponse,
 	return s.mapUserToResponse(user), nil
 }
 
+// mergeIdentity links a new provider identity to the user that already owns its email.
+// Both the incoming provider and one of the user's existing identities must have verified
+// the address, so an unverified email at one provider cannot take over another account.
+func (s *UserService) mergeIdentity(user *domain.User, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	identities, err := s.identityRepo.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+
+	verified := false
+	for _, identity := range identities {
+		if identity.EmailVerified && strings.EqualFold(identity.Email, user.Email) {
+			verified = true
+			break
+		}
+	}
+
+	if !req.EmailVerified || !verified {
+		logger.Warn("OAuth email belongs to another account",
+			"event", "user.oauth_merge_refused",
+			"provider", req.Provider,
+			"user_id", user.ID,
+			"incoming_verified", req.EmailVerified,
+			"existing_verified", verified)
+		return nil, ErrEmailInUse
+	}
+
+	identity := &domain.UserIdentity{
+		UserID:        user.ID,
+		Provider:      req.Provider,
+		ProviderID:    req.ProviderID,
+		Email:         req.Email,
+		EmailVerified: req.EmailVerified,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	logger.Info("OAuth identity merged into existing user",
+		"event", "user.identity_merged",
+		"provider", req.Provider,
+		"user_id", user.ID)
+
+	return s.mapUserToResponse(user), nil
+}
+
+// LinkIdentity links a provider identity to an authenticated user
+func (s *UserService) LinkIdentity(userID string, req dto.CreateUserRequest) ([]dto.IdentityResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	existing, err := s.identityRepo.GetByProvider(req.Provider, req.ProviderID)
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityTaken
+		}
+		// Already linked to this user; linking again is a no-op
+		return s.GetIdentities(userID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up identity: %w", err)
+	}
+
+	identities, err := s.identityRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == req.Provider {
+			return nil, ErrProviderLinked
+		}
+	}
+
+	identity := &domain.UserIdentity{
+		UserID:        userID,
+		Provider:      req.Provider,
+		ProviderID:    req.ProviderID,
+		Email:         req.Email,
+		EmailVerified: req.EmailVerified,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		logger.Error("Failed to link identity",
+			"event", "user.identity_link_failed",
+			"provider", req.Provider,
+			"user_id", userID,
+			"error", err)
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	logger.Info("Identity linked",
+		"event", "user.identity_linked",
+		"provider", req.Provider,
+		"user_id", userID)
+
+	return s.GetIdentities(userID)
+}
+
+// UnlinkIdentity removes a provider from a user. The last remaining provider cannot be removed.
+func (s *UserService) UnlinkIdentity(userID, provider string) error {
+	if err := s.identityRepo.DeleteForUser(userID, provider); err != nil {
+		if errors.Is(err, repository.ErrLastIdentity) {
+			return ErrLastIdentity
+		}
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+
+	logger.Info("Identity unlinked",
+		"event", "user.identity_unlinked",
+		"provider", provider,
+		"user_id", userID)
+
+	return nil
+}
+
+// GetIdentities lists the login providers linked to a user
+func (s *UserService) GetIdentities(userID string) ([]dto.IdentityResponse, error) {
+	identities, err := s.identityRepo.GetByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve identities: %w", err)
+	}
+
+	responses := make([]dto.IdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = dto.IdentityResponse{
+			Provider:      identity.Provider,
+			Email:         identity.Email,
+			EmailVerified: identity.EmailVerified,
+			CreatedAt:     identity.CreatedAt,
+		}
+	}
+
+	return responses, nil
+}
+
 // GetUserByID retrieves a user by ID
 func (s *UserService) GetUserByID(id string) (*dto.UserResponse, error) {
 	user, err := s.userRepo.GetByID(id)
@@ -98,7 +303,8 @@ func (s *UserService) GetUserByEmail(email string) (*dto.UserResponse, error) {
 	return s.mapUserToResponse(user), nil
 }
 
-// UpdateUser updates user profile
+// UpdateUser applies a partial profile update. A username change is checked against the
+// username policy and cooldown, and the previous name is kept as a redirect.
 func (s *UserService) UpdateUser(userID string, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	if err := s.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -109,27 +315,63 @@ func (s *UserService) UpdateUser(userID string, req dto.UpdateUserRequest) (*dto
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	// Update fields if provided
-	if req.FirstName != "" {
-		user.FirstName = req.FirstName
+	// Only fields present in the request change; an empty string clears the field
+	if req.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*req.FirstName)
+	}
+	if req.LastName != nil {
+		user.LastName = strings.TrimSpace(*req.LastName)
 	}
-	if req.LastName != "" {
-		user.LastName = req.LastName
+	if req.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*req.DisplayName)
 	}
-	if req.Username != "" {
-		user.Username = req.Username
+	if req.Bio != nil {
+		user.Bio = strings.TrimSpace(*req.Bio)
 	}
-	if req.DisplayName != "" {
-		user.DisplayName = req.DisplayName
+	if req.AvatarURL != nil {
+		user.AvatarURL = strings.TrimSpace(*req.AvatarURL)
 	}
-	if req.Bio != "" {
-		user.Bio = req.Bio
+	if v := req.Visibility; v != nil {
+		if v.Email != nil {
+			user.EmailVisibility = *v.Email
+		}
+		if v.FirstName != nil {
+			user.FirstNameVisibility = *v.FirstName
+		}
+		if v.LastName != nil {
+			user.LastNameVisibility = *v.LastName
+		}
 	}
-	if req.AvatarURL != "" {
-		user.AvatarURL = req.AvatarURL
+	madePublic := false
+	if req.IsPrivate != nil {
+		madePublic = user.IsPrivate && !*req.IsPrivate
+		user.IsPrivate = *req.IsPrivate
+	}
+
+	previousUsername := ""
+	if req.Username != nil {
+		username := normalizeUsername(*req.Username)
+		if username != strings.ToLower(user.Username) {
+			if err := s.checkUsernameChange(user, username); err != nil {
+				return nil, err
+			}
+			previousUsername = strings.ToLower(user.Username)
+			now := time.Now()
+			user.Username = username
+			user.UsernameChangedAt = &now
+		}
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if previousUsername != "" {
+		err = s.userRepo.UpdateWithUsernameChange(user, previousUsername)
+	} else {
+		err = s.userRepo.Update(user)
+	}
+	if err != nil {
+		// Another user claimed the name between the check and the update
+		if previousUsername != "" && strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrUsernameTaken
+		}
 		logger.Error("Failed to update user profile",
 			"event", "user.update_failed",
 			"user_id", userID,
@@ -137,6 +379,29 @@ func (s *UserService) UpdateUser(userID string, req dto.UpdateUserRequest) (*dto
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if previousUsername != "" {
+		logger.Info("Username changed",
+			"event", "user.username_changed",
+			"user_id", userID,
+			"previous_username", previousUsername,
+			"username", user.Username)
+	}
+
+	// Nobody needs approval to follow a public account, so requests still waiting are approved
+	if madePublic {
+		approved, err := s.followRepo.ApproveAllRequests(userID)
+		if err != nil {
+			logger.Error("Failed to approve pending follow requests",
+				"event", "user.follow_requests_approve_failed",
+				"user_id", userID,
+				"error", err)
+		}
+		for _, request := range approved {
+			s.eventBus.Publish("FollowRequestApproved", events.NewFollowRequestApproved(request.ID, request.RequesterID, request.TargetID))
+		}
+		user.FollowerCount += int64(len(approved))
+	}
+
 	logger.Info("User profile updated successfully",
 		"event", "user.updated",
 		"user_id", userID)
@@ -144,23 +409,153 @@ func (s *UserService) UpdateUser(userID string, req dto.UpdateUserRequest) (*dto
 	return s.mapUserToResponse(user), nil
 }
 
-// GetAllUsers retrieves all users with pagination
-func (s *UserService) GetAllUsers(page, limit int) (*dto.UsersResponse, error) {
+// checkUsernameChange verifies that the user may switch to the normalized username
+func (s *UserService) checkUsernameChange(user *domain.User, username string) error {
+	if err := checkUsernamePolicy(username); err != nil {
+		return err
+	}
+
+	if user.UsernameChangedAt != nil {
+		next := user.UsernameChangedAt.Add(s.config.Users.UsernameChangeCooldown)
+		if time.Now().Before(next) {
+			return fmt.Errorf("%w: next change allowed after %s", ErrUsernameCooldown, next.UTC().Format(time.RFC3339))
+		}
+	}
+
+	available, err := s.usernameAvailable(username, user.ID)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// CheckUsernameAvailability reports whether a username can be claimed. When userID is set,
+// the user's own current and previous names count as available.
+func (s *UserService) CheckUsernameAvailability(username, userID string) (*dto.UsernameAvailabilityResponse, error) {
+	username = normalizeUsername(username)
+	result := &dto.UsernameAvailabilityResponse{Username: username}
+
+	if err := checkUsernamePolicy(username); err != nil {
+		result.Reason = usernameReason(err)
+		return result, nil
+	}
+
+	available, err := s.usernameAvailable(username, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.Available = available
+	if !available {
+		result.Reason = UsernameReasonTaken
+	}
+	return result, nil
+}
+
+// usernameAvailable checks a normalized username against current usernames and held previous names
+func (s *UserService) usernameAvailable(username, userID string) (bool, error) {
+	owner, err := s.userRepo.GetByUsername(username)
+	if err == nil {
+		return owner.ID == userID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to look up username: %w", err)
+	}
+
+	// A previous name stays with its owner for the hold period, so it cannot be taken over to impersonate them
+	redirect, err := s.userRepo.GetUsernameRedirect(username)
+	if err == nil {
+		held := time.Since(redirect.CreatedAt) < s.config.Users.UsernameHoldPeriod
+		return redirect.UserID == userID || !held, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to look up username redirect: %w", err)
+	}
+	return true, nil
+}
+
+// GetProfile retrieves a user as seen by the viewer: the full profile for the user themselves,
+// otherwise the public view allowed by their visibility settings. viewerID is empty for anonymous callers.
+func (s *UserService) GetProfile(id, viewerID string) (interface{}, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return s.projectProfile(user, viewerID)
+}
+
+// GetProfileByUsername retrieves a user by username as seen by the viewer. When the name was given up
+// by its owner, no profile is returned and movedTo holds the owner's current username instead.
+func (s *UserService) GetProfileByUsername(username, viewerID string) (profile interface{}, movedTo string, err error) {
+	username = normalizeUsername(username)
+
+	user, err := s.userRepo.GetByUsername(username)
+	if err == nil {
+		profile, err := s.projectProfile(user, viewerID)
+		return profile, "", err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", fmt.Errorf("failed to look up username: %w", err)
+	}
+
+	redirect, err := s.userRepo.GetUsernameRedirect(username)
+	if err != nil {
+		return nil, "", fmt.Errorf("user not found: %w", err)
+	}
+	owner, err := s.userRepo.GetByID(redirect.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("user not found: %w", err)
+	}
+	return nil, owner.Username, nil
+}
+
+// projectProfile picks the self view or the public view of a user for the viewer
+func (s *UserService) projectProfile(user *domain.User, viewerID string) (interface{}, error) {
+	if viewerID != "" && viewerID == user.ID {
+		return s.mapUserToResponse(user), nil
+	}
+
+	isFollower := false
+	if viewerID != "" {
+		exists, err := s.followRepo.Exists(viewerID, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check follow status: %w", err)
+		}
+		isFollower = exists
+	}
+
+	return s.mapUserToPublicResponse(user, isFollower), nil
+}
+
+// SearchUsers finds users by username or display name prefix. Results are public views, so a
+// search never reveals fields the users have not shared with the viewer.
+func (s *UserService) SearchUsers(query, viewerID string, page, limit int) (*dto.UsersResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 || limit > 100 {
+	if limit < 1 || limit > 50 {
 		limit = 20
 	}
 
-	users, totalCount, err := s.userRepo.GetAll(page, limit)
+	users, totalCount, err := s.userRepo.Search(strings.TrimPrefix(strings.TrimSpace(query), "@"), viewerID, page, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve users: %w", err)
+		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
-	userResponses := make([]dto.UserResponse, len(users))
+	ids := make([]string, len(users))
 	for i, user := range users {
-		userResponses[i] = *s.mapUserToResponse(&user)
+		ids[i] = user.ID
+	}
+	followed, err := s.followRepo.FollowedAmong(viewerID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check follow status: %w", err)
+	}
+
+	userResponses := make([]dto.PublicUserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = *s.mapUserToPublicResponse(&user, followed[user.ID])
 	}
 
 	hasNext := int64((page-1)*limit+len(users)) < totalCount
@@ -177,17 +572,85 @@ func (s *UserService) GetAllUsers(page, limit int) (*dto.UsersResponse, error) {
 // mapUserToResponse converts domain User to UserResponse DTO
 func (s *UserService) mapUserToResponse(user *domain.User) *dto.UserResponse {
 	return &dto.UserResponse{
+		ID:                user.ID,
+		Provider:          user.Provider,
+		FirstName:         user.FirstName,
+		LastName:          user.LastName,
+		Username:          user.Username,
+		Email:             user.Email,
+		DisplayName:       user.DisplayName,
+		Bio:               user.Bio,
+		AvatarURL:         user.AvatarURL,
+		Role:              user.Role,
+		UsernameChangedAt: user.UsernameChangedAt,
+		IsPrivate:         user.IsPrivate,
+		Reputation:        user.Reputation,
+		Visibility: dto.ProfileVisibility{
+			Email:     visibilityOrDefault(user.EmailVisibility, domain.DefaultEmailVisibility),
+			FirstName: visibilityOrDefault(user.FirstNameVisibility, domain.DefaultFirstNameVisibility),
+			LastName:  visibilityOrDefault(user.LastNameVisibility, domain.DefaultLastNameVisibility),
+		},
+		DeletionScheduledAt: s.deletionScheduledAt(user),
+		FollowerCount:       user.FollowerCount,
+		FollowingCount:      user.FollowingCount,
+		CreatedAt:           user.CreatedAt,
+	}
+}
+
+// mapUserToPublicResponse converts a domain User to the view another user is allowed to see
+func (s *UserService) mapUserToPublicResponse(user *domain.User, viewerFollows bool) *dto.PublicUserResponse {
+	response := &dto.PublicUserResponse{
 		ID:             user.ID,
-		Provider:       user.Provider,
-		FirstName:      user.FirstName,
-		LastName:       user.LastName,
 		Username:       user.Username,
-		Email:          user.Email,
 		DisplayName:    user.DisplayName,
 		Bio:            user.Bio,
 		AvatarURL:      user.AvatarURL,
+		Role:           user.Role,
+		IsPrivate:      user.IsPrivate,
+		Reputation:     user.Reputation,
 		FollowerCount:  user.FollowerCount,
 		FollowingCount: user.FollowingCount,
 		CreatedAt:      user.CreatedAt,
 	}
+
+	if canSee(visibilityOrDefault(user.EmailVisibility, domain.DefaultEmailVisibility), viewerFollows) {
+		response.Email = user.Email
+	}
+	if canSee(visibilityOrDefault(user.FirstNameVisibility, domain.DefaultFirstNameVisibility), viewerFollows) {
+		response.FirstName = user.FirstName
+	}
+	if canSee(visibilityOrDefault(user.LastNameVisibility, domain.DefaultLastNameVisibility), viewerFollows) {
+		response.LastName = user.LastName
+	}
+
+	return response
+}
+
+// canSee reports whether a field with the given visibility is shown to a viewer
+func canSee(visibility string, viewerFollows bool) bool {
+	switch visibility {
+	case domain.VisibilityEveryone:
+		return true
+	case domain.VisibilityFollowers:
+		return viewerFollows
+	default:
+		return false
+	}
+}
+
+// visibilityOrDefault falls back to the default for rows written before the setting existed
+func visibilityOrDefault(visibility, defaultVisibility string) string {
+	if visibility == "" {
+		return defaultVisibility
+	}
+	return visibility
+}
+
+// deletionScheduledAt returns when a pending account deletion will be carried out
+func (s *UserService) deletionScheduledAt(user *domain.User) *time.Time {
+	if user.DeletionRequestedAt == nil {
+		return nil
+	}
+	scheduledAt := user.DeletionRequestedAt.Add(s.config.Users.DeletionGracePeriod)
+	return &scheduledAt
 }
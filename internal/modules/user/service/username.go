@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrUsernameInvalid is returned for a username outside the allowed format
+	ErrUsernameInvalid = errors.New("username must be 3-30 lowercase letters, digits or underscores, starting and ending with a letter or digit")
+	// ErrUsernameReserved is returned for names kept for the platform
+	ErrUsernameReserved = errors.New("username is reserved")
+	// ErrUsernameNotAllowed is returned for names containing blocked words
+	ErrUsernameNotAllowed = errors.New("username is not allowed")
+	// ErrUsernameTaken is returned when another user holds the name, currently or as a recent previous name
+	ErrUsernameTaken = errors.New("username is already taken")
+	// ErrUsernameCooldown is returned when the username was changed too recently
+	ErrUsernameCooldown = errors.New("username was changed too recently")
+)
+
+// Reasons reported by the availability check
+const (
+	UsernameReasonInvalid    = "invalid"
+	UsernameReasonReserved   = "reserved"
+	UsernameReasonNotAllowed = "not_allowed"
+	UsernameReasonTaken      = "taken"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{1,28}[a-z0-9]$`)
+
+// reservedUsernames are route names, roles and names that could pass as official accounts
+var reservedUsernames = map[string]bool{
+	"about": true, "account": true, "admin": true, "administrator": true, "api": true,
+	"auth": true, "billing": true, "contact": true, "deleted": true, "docs": true, "help": true,
+	"info": true, "login": true, "logout": true, "me": true, "mod": true,
+	"moderator": true, "null": true, "official": true, "pitstop": true, "privacy": true,
+	"root": true, "security": true, "settings": true, "signin": true, "signup": true,
+	"staff": true, "support": true, "system": true, "team": true, "terms": true,
+	"undefined": true, "user": true, "users": true, "www": true,
+}
+
+// blockedUsernameWords are matched against each word of a username after undoing common digit
+// substitutions. A word is blocked when it starts or ends with one of them, so place names and
+// brands that only contain one, such as scunthorpe or mitsubishi, are allowed.
+var blockedUsernameWords = []string{
+	"asshole", "bastard", "bitch", "cunt", "dickhead", "fuck", "motherfucker",
+	"nazi", "nigger", "nigga", "retard", "shit", "slut", "whore",
+}
+
+// usernameWordSeparator splits a username into words on underscores and on digits that are not
+// read as letters by leetReplacer
+var usernameWordSeparator = regexp.MustCompile(`[_2689]+`)
+
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t")
+
+// normalizeUsername lowercases and trims a requested username
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// checkUsernamePolicy validates the format of a normalized username and rejects reserved and offensive names
+func checkUsernamePolicy(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrUsernameInvalid
+	}
+	if reservedUsernames[username] || reservedUsernames[strings.ReplaceAll(username, "_", "")] {
+		return ErrUsernameReserved
+	}
+
+	for _, segment := range usernameWordSeparator.Split(username, -1) {
+		if containsBlockedWord(leetReplacer.Replace(segment)) {
+			return ErrUsernameNotAllowed
+		}
+	}
+	return nil
+}
+
+// containsBlockedWord reports whether a word of a username starts or ends with a blocked word
+func containsBlockedWord(word string) bool {
+	for _, blocked := range blockedUsernameWords {
+		if strings.HasPrefix(word, blocked) || strings.HasSuffix(word, blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+// usernameReason maps a username error to the reason reported by the availability check
+func usernameReason(err error) string {
+	switch {
+	case errors.Is(err, ErrUsernameInvalid):
+		return UsernameReasonInvalid
+	case errors.Is(err, ErrUsernameReserved):
+		return UsernameReasonReserved
+	case errors.Is(err, ErrUsernameNotAllowed):
+		return UsernameReasonNotAllowed
+	default:
+		return UsernameReasonTaken
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckUsernamePolicy(t *testing.T) {
+	tests := []struct {
+		username string
+		want     error
+	}{
+		{"car_guy", nil},
+		{"mitsubishi_tuner", nil},
+		{"scunthorpe_rs", nil},
+		{"evo9_mitsubishi", nil},
+		{"cocktail_hour", nil},
+		{"shit", ErrUsernameNotAllowed},
+		{"bullshit_racing", ErrUsernameNotAllowed},
+		{"sh1t_happens", ErrUsernameNotAllowed},
+		{"fuckyou", ErrUsernameNotAllowed},
+		{"drift_b1tch99", ErrUsernameNotAllowed},
+		{"admin", ErrUsernameReserved},
+		{"ad_min", ErrUsernameReserved},
+		{"_car_guy", ErrUsernameInvalid},
+		{"ab", ErrUsernameInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if err := checkUsernamePolicy(tt.username); !errors.Is(err, tt.want) {
+				t.Errorf("checkUsernamePolicy(%q) = %v, want %v", tt.username, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// Content types whose views are counted
+const (
+	ViewableTypePost     = "post"
+	ViewableTypeQuestion = "question"
+)
+
+// HotRank returns an SQL expression ranking the rows of a content table by their views, decayed
+// by age, so content drawing views now outranks content that drew more views long ago
+func HotRank(table string) string {
+	return fmt.Sprintf("(%[1]s.view_count + 1) / POWER(EXTRACT(EPOCH FROM (NOW() - %[1]s.created_at)) / 3600 + 2, 1.5)", table)
+}
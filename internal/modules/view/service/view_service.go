@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/logger"
+)
+
+// releaseLockScript deletes a lock only while it still holds the token of the instance releasing
+// it, so a flush that outlived its lock cannot release the lock another instance took since
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Counter adds flushed view counts, keyed by content ID, to the stored view counts of one content type
+type Counter func(counts map[string]int64) error
+
+// ViewService counts distinct views of posts and questions. A view is counted once per viewer
+// within the configured window and buffered in Redis until the next flush adds it to the database.
+type ViewService struct {
+	config   *config.Config
+	redis    *redis.Client
+	counters map[string]Counter
+}
+
+// NewViewService creates a new view service instance. Modules provide the counters that store
+// flushed views with RegisterCounter.
+func NewViewService(config *config.Config, redis *redis.Client) *ViewService {
+	return &ViewService{
+		config:   config,
+		redis:    redis,
+		counters: make(map[string]Counter),
+	}
+}
+
+// RegisterCounter provides the counter that stores the views of a content type. Views of types
+// without a counter are not recorded.
+func (s *ViewService) RegisterCounter(contentType string, counter Counter) {
+	s.counters[contentType] = counter
+}
+
+// RecordView counts a view unless the viewer already viewed the content within the window.
+// Signed-in viewers are told apart by user ID and anonymous ones by their IP address and user
+// agent. Failures are only logged, as losing a view is better than failing the request.
+func (s *ViewService) RecordView(contentType, contentID, viewerID, ip, userAgent string) {
+	if _, ok := s.counters[contentType]; !ok {
+		return
+	}
+
+	ctx := context.Background()
+	first, err := s.redis.SetNX(ctx, seenKey(contentType, contentID, viewerKey(viewerID, ip, userAgent)), 1, s.config.Views.Window).Result()
+	if err != nil {
+		logger.Warn("Failed to record view", "content_type", contentType, "content_id", contentID, "error", err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	if err := s.redis.HIncrBy(ctx, pendingKey(contentType), contentID, 1).Err(); err != nil {
+		logger.Warn("Failed to buffer view", "content_type", contentType, "content_id", contentID, "error", err)
+	}
+}
+
+// FlushViews adds the buffered views to the database and returns how many pieces of content got
+// new views
+func (s *ViewService) FlushViews() (int, error) {
+	flushed := 0
+	for contentType, counter := range s.counters {
+		n, err := s.flush(contentType, counter)
+		if err != nil {
+			return flushed, fmt.Errorf("failed to flush %s views: %w", contentType, err)
+		}
+		flushed += n
+	}
+	return flushed, nil
+}
+
+// flush moves the pending views of a content type aside, so views recorded meanwhile start a new
+// batch, and hands them to the counter. A batch left behind by a flush that was interrupted is
+// counted first, so views are never lost, though a crash right after the counter succeeds means
+// they are counted twice. A lock keeps other instances from flushing the same type meanwhile.
+func (s *ViewService) flush(contentType string, counter Counter) (int, error) {
+	ctx := context.Background()
+	batchKey := flushingKey(contentType)
+
+	lockToken := uuid.NewString()
+	locked, err := s.redis.SetNX(ctx, flushLockKey(contentType), lockToken, s.config.Views.FlushInterval).Result()
+	if err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer func() {
+		if err := releaseLockScript.Run(ctx, s.redis, []string{flushLockKey(contentType)}, lockToken).Err(); err != nil {
+			logger.Warn("Failed to release view flush lock", "content_type", contentType, "error", err)
+		}
+	}()
+
+	flushed, err := s.flushBatch(ctx, contentType, batchKey, counter)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := s.redis.RenameNX(ctx, pendingKey(contentType), batchKey).Result(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return flushed, nil
+		}
+		return flushed, err
+	}
+
+	n, err := s.flushBatch(ctx, contentType, batchKey, counter)
+	return flushed + n, err
+}
+
+// flushBatch hands the views in the batch to the counter and deletes the batch. When the counter
+// fails the views are put back among the pending ones for the next flush.
+func (s *ViewService) flushBatch(ctx context.Context, contentType, batchKey string, counter Counter) (int, error) {
+	values, err := s.redis.HGetAll(ctx, batchKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	counts := make(map[string]int64, len(values))
+	for contentID, value := range values {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[contentID] = n
+	}
+
+	if err := counter(counts); err != nil {
+		pipe := s.redis.TxPipeline()
+		for contentID, n := range counts {
+			pipe.HIncrBy(ctx, pendingKey(contentType), contentID, n)
+		}
+		pipe.Del(ctx, batchKey)
+		if _, restoreErr := pipe.Exec(ctx); restoreErr != nil {
+			logger.Error("Failed to restore unflushed views", "content_type", contentType, "error", restoreErr)
+		}
+		return 0, err
+	}
+
+	if err := s.redis.Del(ctx, batchKey).Err(); err != nil {
+		logger.Warn("Failed to delete flushed views", "content_type", contentType, "error", err)
+	}
+	return len(counts), nil
+}
+
+// StartFlusher flushes buffered views on the given interval until stop is closed, then flushes
+// once more so no views are left behind on shutdown. The returned channel is closed once that
+// last flush is done.
+func (s *ViewService) StartFlusher(interval time.Duration, stop <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.flushAndLog()
+			case <-stop:
+				s.flushAndLog()
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// flushAndLog flushes buffered views, logging the outcome for the flusher
+func (s *ViewService) flushAndLog() {
+	flushed, err := s.FlushViews()
+	if err != nil {
+		logger.Error("Failed to flush views", "error", err)
+	}
+	if flushed > 0 {
+		logger.Info("Flushed views", "content", flushed)
+	}
+}
+
+// viewerKey identifies a viewer, hashing anonymous viewers' IP address and user agent so
+// neither is stored
+func viewerKey(viewerID, ip, userAgent string) string {
+	if viewerID != "" {
+		return "user:" + viewerID
+	}
+	sum := sha256.Sum256([]byte(ip + "\x00" + userAgent))
+	return "anon:" + hex.EncodeToString(sum[:])
+}
+
+// seenKey is the Redis key that marks a viewer as having viewed content within the window
+func seenKey(contentType, contentID, viewer string) string {
+	return fmt.Sprintf("views:seen:%s:%s:%s", contentType, contentID, viewer)
+}
+
+// flushingKey is the Redis hash holding the batch of views of a content type being flushed
+func flushingKey(contentType string) string {
+	return fmt.Sprintf("views:flushing:%s", contentType)
+}
+
+// flushLockKey is the Redis key held by the instance flushing the views of a content type
+func flushLockKey(contentType string) string {
+	return fmt.Sprintf("views:flush-lock:%s", contentType)
+}
+
+// pendingKey is the Redis hash buffering the unflushed views of a content type, by content ID
+func pendingKey(contentType string) string {
+	return fmt.Sprintf("views:pending:%s", contentType)
+}
@@ -5,19 +5,36 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/topboyasante/pitstop/internal/core/config"
 	"github.com/topboyasante/pitstop/internal/core/logger"
+	"github.com/topboyasante/pitstop/internal/core/mailer"
+	"github.com/topboyasante/pitstop/internal/core/middleware"
+	"github.com/topboyasante/pitstop/internal/core/storage"
 	authHandler "github.com/topboyasante/pitstop/internal/modules/auth/handler"
+	"github.com/topboyasante/pitstop/internal/modules/auth/oauth"
+	authRepository "github.com/topboyasante/pitstop/internal/modules/auth/repository"
 	authService "github.com/topboyasante/pitstop/internal/modules/auth/service"
+	badgeDomain "github.com/topboyasante/pitstop/internal/modules/badge/domain"
+	badgeHandler "github.com/topboyasante/pitstop/internal/modules/badge/handler"
+	badgeRepository "github.com/topboyasante/pitstop/internal/modules/badge/repository"
+	badgeService "github.com/topboyasante/pitstop/internal/modules/badge/service"
 	healthHandler "github.com/topboyasante/pitstop/internal/modules/health/handler"
+	postDomain "github.com/topboyasante/pitstop/internal/modules/post/domain"
 	postHandler "github.com/topboyasante/pitstop/internal/modules/post/handler"
 	postRepository "github.com/topboyasante/pitstop/internal/modules/post/repository"
 	postService "github.com/topboyasante/pitstop/internal/modules/post/service"
 	questionHandler "github.com/topboyasante/pitstop/internal/modules/question/handler"
 	questionRepository "github.com/topboyasante/pitstop/internal/modules/question/repository"
 	questionService "github.com/topboyasante/pitstop/internal/modules/question/service"
+	reputationHandler "github.com/topboyasante/pitstop/internal/modules/reputation/handler"
+	reputationRepository "github.com/topboyasante/pitstop/internal/modules/reputation/repository"
+	reputationService "github.com/topboyasante/pitstop/internal/modules/reputation/service"
 	userHandler "github.com/topboyasante/pitstop/internal/modules/user/handler"
 	userRepository "github.com/topboyasante/pitstop/internal/modules/user/repository"
 	userService "github.com/topboyasante/pitstop/internal/modules/user/service"
+	viewDomain "github.com/topboyasante/pitstop/internal/modules/view/domain"
+	viewService "github.com/topboyasante/pitstop/internal/modules/view/service"
 	"github.com/topboyasante/pitstop/internal/shared/events"
+	"github.com/topboyasante/pitstop/internal/shared/policy"
+	"github.com/topboyasante/pitstop/internal/shared/utils"
 	"gorm.io/gorm"
 )
 
@@ -33,25 +50,46 @@ type Provider struct {
 	EventBus  *events.EventBus
 
 	// Handlers
-	AuthHandler     *authHandler.AuthHandler
-	UserHandler     *userHandler.UserHandler
-	PostHandler     *postHandler.PostHandler
-	CommentHandler  *postHandler.CommentHandler
-	LikeHandler     *postHandler.LikeHandler
-	FollowHandler   *userHandler.FollowHandler
-	HealthHandler   *healthHandler.HealthHandler
-	QuestionHandler *questionHandler.QuestionHandler
-	AnswerHandler   *questionHandler.AnswerHandler
+	AuthHandler       *authHandler.AuthHandler
+	TokenHandler      *authHandler.TokenHandler
+	MFAHandler        *authHandler.MFAHandler
+	UserHandler       *userHandler.UserHandler
+	PostHandler       *postHandler.PostHandler
+	CommentHandler    *postHandler.CommentHandler
+	LikeHandler       *postHandler.LikeHandler
+	FollowHandler     *userHandler.FollowHandler
+	BlockHandler      *userHandler.BlockHandler
+	ExportHandler     *userHandler.ExportHandler
+	SuggestionHandler *userHandler.SuggestionHandler
+	HealthHandler     *healthHandler.HealthHandler
+	QuestionHandler   *questionHandler.QuestionHandler
+	AnswerHandler     *questionHandler.AnswerHandler
+	VoteHandler       *questionHandler.VoteHandler
+	BountyHandler     *questionHandler.BountyHandler
+	LifecycleHandler  *questionHandler.LifecycleHandler
+	ReputationHandler *reputationHandler.ReputationHandler
+	BadgeHandler      *badgeHandler.BadgeHandler
 
 	// Module dependencies (can be accessed by other modules if needed)
-	AuthService     *authService.AuthService
-	UserService     *userService.UserService
-	PostService     *postService.PostService
-	CommentService  *postService.CommentService
-	LikeService     *postService.LikeService
-	FollowService   *userService.FollowService
-	QuestionService *questionService.QuestionService
-	AnswerService   *questionService.AnswerService
+	AuthService       *authService.AuthService
+	TokenService      *authService.TokenService
+	MFAService        *authService.MFAService
+	UserService       *userService.UserService
+	PostService       *postService.PostService
+	CommentService    *postService.CommentService
+	LikeService       *postService.LikeService
+	FollowService     *userService.FollowService
+	BlockService      *userService.BlockService
+	ExportService     *userService.ExportService
+	SuggestionService *userService.SuggestionService
+	QuestionService   *questionService.QuestionService
+	AnswerService     *questionService.AnswerService
+	VoteService       *questionService.VoteService
+	BountyService     *questionService.BountyService
+	LifecycleService  *questionService.LifecycleService
+	ReputationService *reputationService.ReputationService
+	BadgeService      *badgeService.BadgeService
+	ViewService       *viewService.ViewService
 }
 
 // NewProvider creates and initializes the dependency injection container
@@ -61,41 +99,148 @@ func NewProvider(db *gorm.DB, redis *redis.Client, cfg *config.Config, validator
 
 	// Initialize User module
 	userRepo := userRepository.NewUserRepository(db)
-	userSvc := userService.NewUserService(userRepo, validator, eventBus)
+	identityRepo := userRepository.NewIdentityRepository(db)
+	followRepo := userRepository.NewFollowRepository(db)
+	userSvc := userService.NewUserService(cfg, userRepo, identityRepo, followRepo, validator, eventBus)
 	userHdlr := userHandler.NewUserHandler(userSvc)
 
+	// Initialize blocks and mutes (other modules check them before letting users interact)
+	blockRepo := userRepository.NewBlockRepository(db)
+	blockSvc := userService.NewBlockService(blockRepo, userRepo)
+	blockHdlr := userHandler.NewBlockHandler(blockSvc)
+
+	// Authorization policy; every module checks author-or-moderator access through it
+	accessPolicy := policy.NewPolicy(cfg, userRepo.GetRole)
+
 	// Initialize Follow module
-	followRepo := userRepository.NewFollowRepository(db)
-	followSvc := userService.NewFollowService(followRepo, userRepo, eventBus)
+	followSvc := userService.NewFollowService(followRepo, userRepo, blockSvc, eventBus)
 	followHdlr := userHandler.NewFollowHandler(followSvc)
 
+	// Initialize view counting; views are buffered in Redis and flushed to the content tables
+	viewSvc := viewService.NewViewService(cfg, redis)
+
 	// Initialize Post module
 	postRepo := postRepository.NewPostRepository(db)
-	postSvc := postService.NewPostService(postRepo, validator, eventBus)
+	postSvc := postService.NewPostService(postRepo, followSvc, viewSvc, validator, eventBus)
 	postHdlr := postHandler.NewPostHandler(postSvc)
 
 	// Initialize Comment module
 	commentRepo := postRepository.NewCommentRepository(db)
-	commentSvc := postService.NewCommentService(commentRepo, postRepo)
+	commentSvc := postService.NewCommentService(commentRepo, postRepo, blockSvc, followSvc)
 	commentHdlr := postHandler.NewCommentHandler(commentSvc)
 
 	// Initialize Like module
 	likeRepo := postRepository.NewLikeRepository(db)
-	likeSvc := postService.NewLikeService(likeRepo, postRepo, eventBus)
+	likeSvc := postService.NewLikeService(likeRepo, postRepo, followSvc, eventBus)
 	likeHdlr := postHandler.NewLikeHandler(likeSvc)
 
-	// Initialize Question module
+	// Initialize Reputation module; it credits users from question module events
+	reputationRepo := reputationRepository.NewReputationRepository(db)
+	reputationSvc := reputationService.NewReputationService(cfg, reputationRepo, followSvc)
+	reputationSvc.SubscribeToEvents(eventBus)
+	reputationHdlr := reputationHandler.NewReputationHandler(reputationSvc)
+
+	// Initialize Question module; the edit tags privilege lets users retag others' questions
 	questionRepo := questionRepository.NewQuestionRepository(db)
-	questionSvc := questionService.NewQuestionService(questionRepo, validator, eventBus)
+	questionSvc := questionService.NewQuestionService(questionRepo, viewSvc, reputationSvc, followSvc, accessPolicy, validator, eventBus)
 	questionHdlr := questionHandler.NewQuestionHandler(questionSvc)
 
 	// Initialize Answer module
 	answerRepo := questionRepository.NewAnswerRepository(db)
-	answerSvc := questionService.NewAnswerService(answerRepo, questionRepo, validator, eventBus)
+	answerSvc := questionService.NewAnswerService(answerRepo, questionRepo, blockSvc, followSvc, accessPolicy, validator, eventBus)
 	answerHdlr := questionHandler.NewAnswerHandler(answerSvc)
 
+	// Initialize Vote module
+	voteRepo := questionRepository.NewVoteRepository(db)
+	voteSvc := questionService.NewVoteService(voteRepo, questionRepo, answerRepo, blockSvc, validator, eventBus)
+	voteHdlr := questionHandler.NewVoteHandler(voteSvc)
+
+	// Initialize the mailer used for magic-link sign-in and notifications
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize mailer", "error", err)
+	}
+
+	// Initialize Badge module; each module measures the badge metrics it owns
+	badgeRepo := badgeRepository.NewBadgeRepository(db)
+	badgeSvc := badgeService.NewBadgeService(badgeRepo, followSvc, eventBus, mail)
+	badgeSvc.RegisterMetric(badgeDomain.MetricPosts, postRepo.CountByUser)
+	badgeSvc.RegisterMetric(badgeDomain.MetricAcceptedAnswers, answerRepo.CountAcceptedByUser)
+	badgeSvc.RegisterMetric(badgeDomain.MetricFollowers, followRepo.CountFollowers)
+	badgeSvc.RegisterMetric(badgeDomain.MetricMembershipDays, userRepo.MembershipDays)
+	badgeSvc.SubscribeToEvents()
+	badgeHdlr := badgeHandler.NewBadgeHandler(badgeSvc)
+
+	// Initialize Bounty module; bounties are funded from and paid out in reputation
+	bountyRepo := questionRepository.NewBountyRepository(db)
+	bountySvc := questionService.NewBountyService(cfg, bountyRepo, questionRepo, answerRepo, reputationSvc, validator, eventBus)
+	bountyHdlr := questionHandler.NewBountyHandler(bountySvc)
+
+	// Initialize question lifecycle; close and reopen votes need the close vote privilege
+	closeVoteRepo := questionRepository.NewCloseVoteRepository(db)
+	lifecycleSvc := questionService.NewLifecycleService(cfg, closeVoteRepo, questionRepo, reputationSvc, accessPolicy, validator, eventBus)
+	lifecycleHdlr := questionHandler.NewLifecycleHandler(lifecycleSvc)
+
+	// Open questions and answers to comments and likes
+	commentSvc.RegisterCommentable(postDomain.CommentableTypeQuestion, questionSvc.ResolveAuthor)
+	commentSvc.RegisterCommentable(postDomain.CommentableTypeAnswer, answerSvc.ResolveAuthor)
+	likeSvc.RegisterLikable(postDomain.LikableTypeQuestion, questionSvc.ResolveAuthor)
+	likeSvc.RegisterLikable(postDomain.LikableTypeAnswer, answerSvc.ResolveAuthor)
+
+	// Count views of posts and questions
+	viewSvc.RegisterCounter(viewDomain.ViewableTypePost, postRepo.AddViews)
+	viewSvc.RegisterCounter(viewDomain.ViewableTypeQuestion, questionRepo.AddViews)
+
+	// Initialize personal access tokens and let the JWT middleware accept them
+	tokenRepo := authRepository.NewTokenRepository(db)
+	tokenSvc := authService.NewTokenService(cfg, tokenRepo, redis, validator)
+	tokenHdlr := authHandler.NewTokenHandler(tokenSvc)
+	middleware.SetPersonalTokenAuthenticator(tokenSvc)
+	utils.SetSessionRevocationChecker(tokenSvc)
+
+	// Initialize two-factor authentication (depends on user service)
+	mfaRepo := authRepository.NewMFARepository(db)
+	mfaSvc, err := authService.NewMFAService(cfg, mfaRepo, redis, userSvc)
+	if err != nil {
+		logger.Fatal("Failed to initialize MFA service", "error", err)
+	}
+	mfaHdlr := authHandler.NewMFAHandler(mfaSvc)
+
+	// Account deletion signs the user out and erases their rows in every module
+	userSvc.SetSessionRevoker(tokenSvc)
+	userSvc.RegisterAccountPurger("posts", postRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("questions", questionRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("reputation", reputationRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("badges", badgeRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("tokens", tokenRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("mfa", mfaRepo.PurgeUser)
+	userSvc.RegisterAccountPurger("blocks", blockRepo.PurgeUser)
+
+	// Initialize blob storage and personal data exports (depends on user service and mailer)
+	blobs, err := storage.New(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage", "error", err)
+	}
+	exportRepo := userRepository.NewDataExportRepository(db)
+	exportSvc := userService.NewExportService(cfg, exportRepo, userRepo, followRepo, userSvc, blobs, mail)
+	exportSvc.RegisterDataExporter("posts", postRepo.ExportUser)
+	exportSvc.RegisterDataExporter("questions", questionRepo.ExportUser)
+	exportSvc.RegisterDataExporter("reputation", reputationRepo.ExportUser)
+	exportSvc.RegisterDataExporter("badges", badgeRepo.ExportUser)
+	exportSvc.RegisterDataExporter("tokens", tokenRepo.ExportUser)
+	userSvc.RegisterDeferredPurger("exports", exportSvc.PurgeUser)
+	exportHdlr := userHandler.NewExportHandler(exportSvc)
+
+	// Initialize follow suggestions. Modules add the signals they know about.
+	suggestionSvc := userService.NewSuggestionService(cfg, redis, followRepo, userRepo)
+	suggestionSvc.RegisterSuggestionSource("shared_tags", 2, questionRepo.SharedTagUsers)
+	suggestionSvc.RegisterSuggestionSource("active", 1, postRepo.ActiveUsers)
+	suggestionSvc.RegisterSuggestionSource("active", 1, questionRepo.ActiveUsers)
+	suggestionHdlr := userHandler.NewSuggestionHandler(suggestionSvc)
+
 	// Initialize Auth module (depends on user service)
-	authService := authService.NewAuthService(cfg, redis, eventBus, validator, userSvc)
+	oauthProviders := oauth.NewRegistry(cfg)
+	authService := authService.NewAuthService(cfg, redis, eventBus, validator, userSvc, oauthProviders, mfaSvc, mail)
 	authHandler := authHandler.NewAuthHandler(authService)
 
 	// Initialize Health module
@@ -111,24 +256,45 @@ func NewProvider(db *gorm.DB, redis *redis.Client, cfg *config.Config, validator
 		Validator: validator,
 		EventBus:  eventBus,
 
-		AuthHandler:     authHandler,
-		UserHandler:     userHdlr,
-		PostHandler:     postHdlr,
-		CommentHandler:  commentHdlr,
-		LikeHandler:     likeHdlr,
-		FollowHandler:   followHdlr,
-		HealthHandler:   healthHdlr,
-		QuestionHandler: questionHdlr,
-		AnswerHandler:   answerHdlr,
-
-		AuthService:     authService,
-		UserService:     userSvc,
-		PostService:     postSvc,
-		CommentService:  commentSvc,
-		LikeService:     likeSvc,
-		FollowService:   followSvc,
-		QuestionService: questionSvc,
-		AnswerService:   answerSvc,
+		AuthHandler:       authHandler,
+		TokenHandler:      tokenHdlr,
+		MFAHandler:        mfaHdlr,
+		UserHandler:       userHdlr,
+		PostHandler:       postHdlr,
+		CommentHandler:    commentHdlr,
+		LikeHandler:       likeHdlr,
+		FollowHandler:     followHdlr,
+		BlockHandler:      blockHdlr,
+		ExportHandler:     exportHdlr,
+		SuggestionHandler: suggestionHdlr,
+		HealthHandler:     healthHdlr,
+		QuestionHandler:   questionHdlr,
+		AnswerHandler:     answerHdlr,
+		VoteHandler:       voteHdlr,
+		BountyHandler:     bountyHdlr,
+		LifecycleHandler:  lifecycleHdlr,
+		ReputationHandler: reputationHdlr,
+		BadgeHandler:      badgeHdlr,
+
+		AuthService:       authService,
+		TokenService:      tokenSvc,
+		MFAService:        mfaSvc,
+		UserService:       userSvc,
+		PostService:       postSvc,
+		CommentService:    commentSvc,
+		LikeService:       likeSvc,
+		FollowService:     followSvc,
+		BlockService:      blockSvc,
+		ExportService:     exportSvc,
+		SuggestionService: suggestionSvc,
+		QuestionService:   questionSvc,
+		AnswerService:     answerSvc,
+		VoteService:       voteSvc,
+		BountyService:     bountySvc,
+		LifecycleService:  lifecycleSvc,
+		ReputationService: reputationSvc,
+		BadgeService:      badgeSvc,
+		ViewService:       viewSvc,
 	}
 }
 
@@ -143,6 +309,84 @@ func setupEventSubscribers(eventBus *events.EventBus, authService *authService.A
 		// Could trigger welcome email, create default garage, etc.
 	})
 
+	eventBus.Subscribe("AccountDeleted", func(event events.Event) {
+		deleted := event.(*events.AccountDeleted)
+		logger.Info("Account deleted",
+			"event", deleted.Name,
+			"user_id", deleted.UserID)
+	})
+
+	eventBus.Subscribe("FollowRequested", func(event events.Event) {
+		requested := event.(*events.FollowRequested)
+		logger.Info("Follow requested",
+			"event", requested.Name,
+			"request_id", requested.RequestID,
+			"requester_id", requested.RequesterID,
+			"target_id", requested.TargetID)
+	})
+
+	eventBus.Subscribe("FollowRequestApproved", func(event events.Event) {
+		approved := event.(*events.FollowRequestApproved)
+		logger.Info("Follow request approved",
+			"event", approved.Name,
+			"request_id", approved.RequestID,
+			"requester_id", approved.RequesterID,
+			"target_id", approved.TargetID)
+	})
+
+	eventBus.Subscribe("VoteCast", func(event events.Event) {
+		vote := event.(*events.VoteCast)
+		logger.Info("Vote cast",
+			"event", vote.Name,
+			"votable_type", vote.VotableType,
+			"votable_id", vote.VotableID,
+			"voter_id", vote.VoterID,
+			"value", vote.Value,
+			"previous_value", vote.PreviousValue)
+	})
+
+	eventBus.Subscribe("QuestionAnswered", func(event events.Event) {
+		answered := event.(*events.QuestionAnswered)
+		logger.Info("Question answered",
+			"event", answered.Name,
+			"question_id", answered.QuestionID,
+			"answer_id", answered.AnswerID,
+			"question_author_id", answered.QuestionAuthorID)
+	})
+
+	eventBus.Subscribe("BountyAwarded", func(event events.Event) {
+		awarded := event.(*events.BountyAwarded)
+		logger.Info("Bounty awarded",
+			"event", awarded.Name,
+			"bounty_id", awarded.BountyID,
+			"question_id", awarded.QuestionID,
+			"answer_id", awarded.AnswerID,
+			"recipient_id", awarded.RecipientID,
+			"amount", awarded.Amount,
+			"automatic", awarded.Automatic)
+	})
+
+	eventBus.Subscribe("QuestionStatusChanged", func(event events.Event) {
+		changed := event.(*events.QuestionStatusChanged)
+		logger.Info("Question status changed",
+			"event", changed.Name,
+			"question_id", changed.QuestionID,
+			"status", changed.Status,
+			"reason", changed.Reason,
+			"canonical_id", changed.CanonicalID,
+			"changed_by", changed.ChangedBy)
+	})
+
+	eventBus.Subscribe("BadgeAwarded", func(event events.Event) {
+		awarded := event.(*events.BadgeAwarded)
+		logger.Info("Badge awarded",
+			"event", awarded.Name,
+			"user_id", awarded.UserID,
+			"badge", awarded.Badge,
+			"tier", awarded.Tier,
+			"milestone", awarded.Milestone)
+	})
+
 	// Example: When a user registers, other modules can react
 	// eventBus.Subscribe("UserRegistered", func(event events.Event) {
 	// 	userEvent := event.(*events.UserRegistered)
@@ -57,3 +57,339 @@ func NewAuthenticationSuccessful(provider,
 		Locale:     locale,
 	}
 }
+
+// User Events
+
+// AccountDeleted is published after an account and the data it owned have been erased.
+// Modules that keep their own user data should remove it when they receive this event.
+type AccountDeleted struct {
+	BaseEvent
+	UserID string `json:"user_id"`
+}
+
+func NewAccountDeleted(userID string) *AccountDeleted {
+	return &AccountDeleted{
+		BaseEvent: BaseEvent{
+			Name:      "user.account_deleted",
+			Timestamp: time.Now(),
+		},
+		UserID: userID,
+	}
+}
+
+// FollowRequested is published when someone asks to follow a private account
+type FollowRequested struct {
+	BaseEvent
+	RequestID   string `json:"request_id"`
+	RequesterID string `json:"requester_id"`
+	TargetID    string `json:"target_id"`
+}
+
+func NewFollowRequested(requestID, requesterID, targetID string) *FollowRequested {
+	return &FollowRequested{
+		BaseEvent: BaseEvent{
+			Name:      "user.follow_requested",
+			Timestamp: time.Now(),
+		},
+		RequestID:   requestID,
+		RequesterID: requesterID,
+		TargetID:    targetID,
+	}
+}
+
+// FollowRequestApproved is published when a private account approves a follow request.
+// The requester is a follower from this point on.
+type FollowRequestApproved struct {
+	BaseEvent
+	RequestID   string `json:"request_id"`
+	RequesterID string `json:"requester_id"`
+	TargetID    string `json:"target_id"`
+}
+
+func NewFollowRequestApproved(requestID, requesterID, targetID string) *FollowRequestApproved {
+	return &FollowRequestApproved{
+		BaseEvent: BaseEvent{
+			Name:      "user.follow_request_approved",
+			Timestamp: time.Now(),
+		},
+		RequestID:   requestID,
+		RequesterID: requesterID,
+		TargetID:    targetID,
+	}
+}
+
+// UserFollowed is published when a user starts following another user directly. Approved
+// follow requests are published as FollowRequestApproved instead.
+type UserFollowed struct {
+	BaseEvent
+	FollowerID  string `json:"follower_id"`
+	FollowingID string `json:"following_id"`
+}
+
+func NewUserFollowed(followerID, followingID string) *UserFollowed {
+	return &UserFollowed{
+		BaseEvent: BaseEvent{
+			Name:      "user.followed",
+			Timestamp: time.Now(),
+		},
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	}
+}
+
+// Post Events
+
+// PostCreated is published when a user creates a post
+type PostCreated struct {
+	BaseEvent
+	PostID   string `json:"post_id"`
+	AuthorID string `json:"author_id"`
+}
+
+func NewPostCreated(postID, authorID string) *PostCreated {
+	return &PostCreated{
+		BaseEvent: BaseEvent{
+			Name:      "post.created",
+			Timestamp: time.Now(),
+		},
+		PostID:   postID,
+		AuthorID: authorID,
+	}
+}
+
+// Question Events
+
+// QuestionCreated is published when a user asks a question
+type QuestionCreated struct {
+	BaseEvent
+	QuestionID string `json:"question_id"`
+	AuthorID   string `json:"author_id"`
+}
+
+func NewQuestionCreated(questionID, authorID string) *QuestionCreated {
+	return &QuestionCreated{
+		BaseEvent: BaseEvent{
+			Name:      "question.created",
+			Timestamp: time.Now(),
+		},
+		QuestionID: questionID,
+		AuthorID:   authorID,
+	}
+}
+
+// AnswerCreated is published when a user answers a question
+type AnswerCreated struct {
+	BaseEvent
+	QuestionID string `json:"question_id"`
+	AnswerID   string `json:"answer_id"`
+	AuthorID   string `json:"author_id"`
+}
+
+func NewAnswerCreated(questionID, answerID, authorID string) *AnswerCreated {
+	return &AnswerCreated{
+		BaseEvent: BaseEvent{
+			Name:      "question.answer_created",
+			Timestamp: time.Now(),
+		},
+		QuestionID: questionID,
+		AnswerID:   answerID,
+		AuthorID:   authorID,
+	}
+}
+
+// VoteCast is published when a user votes on a question or answer, changes their vote or
+// retracts it. Value is the new vote (0 after a retraction) and PreviousValue the vote it
+// replaced (0 when there was none).
+type VoteCast struct {
+	BaseEvent
+	VotableType   string `json:"votable_type"`
+	VotableID     string `json:"votable_id"`
+	AuthorID      string `json:"author_id"`
+	VoterID       string `json:"voter_id"`
+	Value         int    `json:"value"`
+	PreviousValue int    `json:"previous_value"`
+}
+
+func NewVoteCast(votableType, votableID, authorID, voterID string, value, previousValue int) *VoteCast {
+	return &VoteCast{
+		BaseEvent: BaseEvent{
+			Name:      "question.vote_cast",
+			Timestamp: time.Now(),
+		},
+		VotableType:   votableType,
+		VotableID:     votableID,
+		AuthorID:      authorID,
+		VoterID:       voterID,
+		Value:         value,
+		PreviousValue: previousValue,
+	}
+}
+
+// AnswerAccepted is published when a question author accepts an answer
+type AnswerAccepted struct {
+	BaseEvent
+	QuestionID       string `json:"question_id"`
+	AnswerID         string `json:"answer_id"`
+	QuestionAuthorID string `json:"question_author_id"`
+	AnswerAuthorID   string `json:"answer_author_id"`
+}
+
+func NewAnswerAccepted(questionID, answerID, questionAuthorID, answerAuthorID string) *AnswerAccepted {
+	return &AnswerAccepted{
+		BaseEvent: BaseEvent{
+			Name:      "question.answer_accepted",
+			Timestamp: time.Now(),
+		},
+		QuestionID:       questionID,
+		AnswerID:         answerID,
+		QuestionAuthorID: questionAuthorID,
+		AnswerAuthorID:   answerAuthorID,
+	}
+}
+
+// QuestionAnswered is published when a question without an accepted answer gets one
+type QuestionAnswered struct {
+	BaseEvent
+	QuestionID       string `json:"question_id"`
+	AnswerID         string `json:"answer_id"`
+	QuestionAuthorID string `json:"question_author_id"`
+}
+
+func NewQuestionAnswered(questionID, answerID, questionAuthorID string) *QuestionAnswered {
+	return &QuestionAnswered{
+		BaseEvent: BaseEvent{
+			Name:      "question.answered",
+			Timestamp: time.Now(),
+		},
+		QuestionID:       questionID,
+		AnswerID:         answerID,
+		QuestionAuthorID: questionAuthorID,
+	}
+}
+
+// AnswerUnaccepted is published when an accepted answer stops being accepted, because the
+// question author unaccepted it or accepted another answer instead, or the answer was deleted
+type AnswerUnaccepted struct {
+	BaseEvent
+	QuestionID       string `json:"question_id"`
+	AnswerID         string `json:"answer_id"`
+	QuestionAuthorID string `json:"question_author_id"`
+	AnswerAuthorID   string `json:"answer_author_id"`
+}
+
+func NewAnswerUnaccepted(questionID, answerID, questionAuthorID, answerAuthorID string) *AnswerUnaccepted {
+	return &AnswerUnaccepted{
+		BaseEvent: BaseEvent{
+			Name:      "question.answer_unaccepted",
+			Timestamp: time.Now(),
+		},
+		QuestionID:       questionID,
+		AnswerID:         answerID,
+		QuestionAuthorID: questionAuthorID,
+		AnswerAuthorID:   answerAuthorID,
+	}
+}
+
+// BountyAwarded is published when a bounty is paid out to an answer, either by the question
+// author or automatically when it expired
+type BountyAwarded struct {
+	BaseEvent
+	BountyID    string `json:"bounty_id"`
+	QuestionID  string `json:"question_id"`
+	AnswerID    string `json:"answer_id"`
+	SponsorID   string `json:"sponsor_id"`
+	RecipientID string `json:"recipient_id"`
+	Amount      int    `json:"amount"`
+	Automatic   bool   `json:"automatic"`
+}
+
+func NewBountyAwarded(bountyID, questionID, answerID, sponsorID, recipientID string, amount int, automatic bool) *BountyAwarded {
+	return &BountyAwarded{
+		BaseEvent: BaseEvent{
+			Name:      "question.bounty_awarded",
+			Timestamp: time.Now(),
+		},
+		BountyID:    bountyID,
+		QuestionID:  questionID,
+		AnswerID:    answerID,
+		SponsorID:   sponsorID,
+		RecipientID: recipientID,
+		Amount:      amount,
+		Automatic:   automatic,
+	}
+}
+
+// QuestionStatusChanged is published when close votes, reopen votes or a moderator change a
+// question's lifecycle state
+type QuestionStatusChanged struct {
+	BaseEvent
+	QuestionID  string `json:"question_id"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	CanonicalID string `json:"canonical_id,omitempty"`
+	ChangedBy   string `json:"changed_by"`
+}
+
+func NewQuestionStatusChanged(questionID, status, reason, canonicalID, changedBy string) *QuestionStatusChanged {
+	return &QuestionStatusChanged{
+		BaseEvent: BaseEvent{
+			Name:      "question.status_changed",
+			Timestamp: time.Now(),
+		},
+		QuestionID:  questionID,
+		Status:      status,
+		Reason:      reason,
+		CanonicalID: canonicalID,
+		ChangedBy:   changedBy,
+	}
+}
+
+// Badge Events
+
+// BadgeAwarded is published once for every badge a user earns, and again for each further
+// milestone of a repeatable badge. Replayed awards are not published.
+type BadgeAwarded struct {
+	BaseEvent
+	UserID    string `json:"user_id"`
+	Badge     string `json:"badge"`
+	Tier      string `json:"tier"`
+	Milestone int    `json:"milestone"`
+}
+
+func NewBadgeAwarded(userID, badge, tier string, milestone int) *BadgeAwarded {
+	return &BadgeAwarded{
+		BaseEvent: BaseEvent{
+			Name:      "badge.awarded",
+			Timestamp: time.Now(),
+		},
+		UserID:    userID,
+		Badge:     badge,
+		Tier:      tier,
+		Milestone: milestone,
+	}
+}
+
+// Moderation Events
+
+// ContentRemovedAsSpam is published when a moderator removes a post, question, answer or
+// comment as spam
+type ContentRemovedAsSpam struct {
+	BaseEvent
+	ContentType string `json:"content_type"`
+	ContentID   string `json:"content_id"`
+	AuthorID    string `json:"author_id"`
+	ModeratorID string `json:"moderator_id"`
+}
+
+func NewContentRemovedAsSpam(contentType, contentID, authorID, moderatorID string) *ContentRemovedAsSpam {
+	return &ContentRemovedAsSpam{
+		BaseEvent: BaseEvent{
+			Name:      "moderation.content_removed_as_spam",
+			Timestamp: time.Now(),
+		},
+		ContentType: contentType,
+		ContentID:   contentID,
+		AuthorID:    authorID,
+		ModeratorID: moderatorID,
+	}
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/topboyasante/pitstop/internal/core/config"
+	userDomain "github.com/topboyasante/pitstop/internal/modules/user/domain"
+	utils "github.com/topboyasante/pitstop/internal/shared/utils"
+	"gorm.io/gorm"
+)
+
+// ErrForbidden is returned when a user is not allowed to act on a piece of content. The errors
+// the policy returns wrap it, so handlers map them to 403 Forbidden with errors.Is.
+var ErrForbidden = errors.New("forbidden")
+
+// RoleLookup returns a user's role
+type RoleLookup func(userID string) (string, error)
+
+// Actor is the user a request acts for, together with how the request was authenticated
+type Actor struct {
+	UserID string
+	// MFA reports whether the session passed two-factor authentication. Personal access tokens never have.
+	MFA bool
+}
+
+// ActorFromContext returns the actor of an authenticated request
+func ActorFromContext(c *fiber.Ctx) (Actor, error) {
+	userID, err := utils.ExtractUserIDFromContext(c)
+	if err != nil {
+		return Actor{}, err
+	}
+	mfa, _ := c.Locals("mfa").(bool)
+	return Actor{UserID: userID, MFA: mfa}, nil
+}
+
+// Policy decides who may change content. Every module asks it, so authors, moderators and
+// everyone else are treated the same way everywhere.
+type Policy struct {
+	config *config.Config
+	roles  RoleLookup
+}
+
+// NewPolicy creates a new policy instance
+func NewPolicy(config *config.Config, roles RoleLookup) *Policy {
+	return &Policy{
+		config: config,
+		roles:  roles,
+	}
+}
+
+// IsModeratorRole reports whether a role may moderate other users' content
+func IsModeratorRole(role string) bool {
+	return role == userDomain.RoleModerator || role == userDomain.RoleAdmin
+}
+
+// RequireAuthor allows only the content's author. action describes what was attempted, as in
+// "accept answers".
+func RequireAuthor(userID, authorID, action string) error {
+	if userID == "" || userID != authorID {
+		return fmt.Errorf("%w: only the author can %s", ErrForbidden, action)
+	}
+	return nil
+}
+
+// RequireAuthorOrModerator allows the content's author and moderators
+func (p *Policy) RequireAuthorOrModerator(actor Actor, authorID, action string) error {
+	if actor.UserID != "" && actor.UserID == authorID {
+		return nil
+	}
+	moderator, err := p.IsModerator(actor)
+	if err != nil {
+		return err
+	}
+	if !moderator {
+		return fmt.Errorf("%w: only the author or a moderator can %s", ErrForbidden, action)
+	}
+	return nil
+}
+
+// RequireModerator allows only moderators
+func (p *Policy) RequireModerator(actor Actor, action string) error {
+	moderator, err := p.IsModerator(actor)
+	if err != nil {
+		return err
+	}
+	if !moderator {
+		return fmt.Errorf("%w: only moderators can %s", ErrForbidden, action)
+	}
+	return nil
+}
+
+// IsModerator looks up whether the actor may act as a moderator. Unknown users are not, and
+// neither is a user whose role requires two-factor authentication unless the session passed it.
+func (p *Policy) IsModerator(actor Actor) (bool, error) {
+	if actor.UserID == "" {
+		return false, nil
+	}
+	role, err := p.roles(actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get user role: %w", err)
+	}
+	if p.config.RequiresMFA(role) && !actor.MFA {
+		return false, nil
+	}
+	return IsModeratorRole(role), nil
+}
@@ -0,0 +1,21 @@
+package suggestion
+
+import "time"
+
+// ActivityWindow is how far back activity counts when ranking users to suggest
+const ActivityWindow = 30 * 24 * time.Hour
+
+// ScoredUser is a user ID with a ranking score, the row shape of user suggestion queries
+type ScoredUser struct {
+	UserID string
+	Score  float64
+}
+
+// ScoresByUser indexes scored users by user ID
+func ScoresByUser(rows []ScoredUser) map[string]float64 {
+	scores := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		scores[row.UserID] = row.Score
+	}
+	return scores
+}
@@ -1,42 +1,84 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/topboyasante/pitstop/internal/core/config"
+	"github.com/topboyasante/pitstop/internal/core/keys"
 	"github.com/topboyasante/pitstop/internal/core/logger"
 )
 
+// Token lifetimes used when the audience is not a registered client
+const (
+	defaultAccessTokenTTL  = 30 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// SessionRevocationChecker reports whether the tokens a user was issued at a given time have been revoked
+type SessionRevocationChecker interface {
+	SessionRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+var sessionRevocationChecker SessionRevocationChecker
+
+// SetSessionRevocationChecker registers the store consulted by ValidateJWTToken for revoked sessions
+func SetSessionRevocationChecker(checker SessionRevocationChecker) {
+	sessionRevocationChecker = checker
+}
+
+// tokenLifetimes returns the access and refresh token lifetimes for an audience
+func tokenLifetimes(config *config.Config, audience string) (time.Duration, time.Duration) {
+	if client, ok := config.Client(audience); ok {
+		return client.AccessTokenTTL, client.RefreshTokenTTL
+	}
+	return defaultAccessTokenTTL, defaultRefreshTokenTTL
+}
+
 func CreateJWTTokens(config *config.Config, userID string, audience string) (string, string, int64, error) {
+	return CreateJWTTokensWithClaims(config, userID, audience, nil)
+}
+
+// CreateJWTTokensWithClaims creates access and refresh tokens carrying extra claims, such as "mfa"
+// for sessions that passed two-factor authentication. Refreshing keeps the extra claims.
+func CreateJWTTokensWithClaims(config *config.Config, userID string, audience string, extra jwt.MapClaims) (string, string, int64, error) {
 	logger.Debug("Creating JWT tokens", "userID", userID, "audience", audience)
 
-	accessTokenExp := time.Now().Add(time.Minute * 30).Unix()
-	accessTokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	accessTTL, refreshTTL := tokenLifetimes(config, audience)
+	accessTokenExp := time.Now().Add(accessTTL).Unix()
+	accessTokenClaims := jwt.MapClaims{
 		"sub": userID,                  // Subject (user identifier)
 		"iss": config.Server.JWTIssuer, // Issuer
 		"aud": audience,                // Audience (intended recipient)
 		"exp": accessTokenExp,          // Expiration time
-		"iat": time.Now().Unix(),       // Issued at
-	})
+		"iat": issuedAtNow(),           // Issued at
+	}
+	for claim, value := range extra {
+		accessTokenClaims[claim] = value
+	}
 
-	accessTokenString, err := accessTokenClaims.SignedString([]byte(config.Server.JWTSecret))
+	accessTokenString, err := keys.Get().Sign(accessTokenClaims)
 	if err != nil {
 		logger.Error("Failed to sign access token", "error", err, "userID", userID)
 		return "", "", 0, err
 	}
 
-	refreshTokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": userID,                                     // Subject (user identifier)
-		"iss": config.Server.JWTIssuer,                    // Issuer
-		"aud": audience,                                   // Audience (intended recipient)
-		"exp": time.Now().Add(time.Hour * 24 * 30).Unix(), // Expiration time = 30 days
-		"iat": time.Now().Unix(),                          // Issued at
-	})
+	refreshTokenClaims := jwt.MapClaims{
+		"sub": userID,                            // Subject (user identifier)
+		"iss": config.Server.JWTIssuer,           // Issuer
+		"aud": audience,                          // Audience (intended recipient)
+		"exp": time.Now().Add(refreshTTL).Unix(), // Expiration time, per audience
+		"iat": issuedAtNow(),                     // Issued at
+	}
+	for claim, value := range extra {
+		refreshTokenClaims[claim] = value
+	}
 
-	refreshTokenString, err := refreshTokenClaims.SignedString([]byte(config.Server.JWTSecret))
+	refreshTokenString, err := keys.Get().Sign(refreshTokenClaims)
 	if err != nil {
 		logger.Error("Failed to sign refresh token", "error", err, "userID", userID)
 		return "", "", 0, err
@@ -49,13 +91,11 @@ func CreateJWTTokens(config *config.Config, userID string, audience string) (str
 func ValidateJWTToken(config *config.Config, tokenString string) (*jwt.Token, error) {
 	logger.Debug("Validating JWT token")
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			logger.Error("Unexpected signing method", "method", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.Server.JWTSecret), nil
-	})
+	manager := keys.Get()
+	token, err := jwt.Parse(tokenString, manager.Keyfunc,
+		jwt.WithValidMethods(manager.ValidMethods()),
+		jwt.WithIssuer(config.Server.JWTIssuer),
+	)
 
 	if err != nil {
 		logger.Error("Failed to parse JWT token", "error", err)
@@ -67,6 +107,10 @@ func ValidateJWTToken(config *config.Config, tokenString string) (*jwt.Token, er
 		return nil, errors.New("invalid token")
 	}
 
+	if err := checkSessionRevoked(token); err != nil {
+		return nil, err
+	}
+
 	logger.Debug("JWT token validated successfully")
 	return token, nil
 }
@@ -117,8 +161,20 @@ func RefreshToken(config *config.Config, refreshTokenString string) (string, str
 		return "", "", 0, fmt.Errorf("failed to extract claims: %w", err)
 	}
 
+	// Only registered clients can keep refreshing
+	if _, ok := config.Client(audience); !ok {
+		logger.Warn("Refresh token issued to unknown client", "userID", userID, "audience", audience)
+		return "", "", 0, fmt.Errorf("unknown client: %s", audience)
+	}
+
+	// Carry forward the two-factor status of the session
+	var extra jwt.MapClaims
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && claims["mfa"] == true {
+		extra = jwt.MapClaims{"mfa": true}
+	}
+
 	logger.Info("Refresh token validated, creating new tokens", "userID", userID)
-	return CreateJWTTokens(config, userID, audience)
+	return CreateJWTTokensWithClaims(config, userID, audience, extra)
 }
 
 func GetUserIDFromToken(config *config.Config, tokenString string) (string, error) {
@@ -139,3 +195,38 @@ func GetUserIDFromToken(config *config.Config, tokenString string) (string, erro
 	logger.Debug("User ID extracted successfully", "userID", userID)
 	return userID, nil
 }
+
+// issuedAtNow is the iat of a new session token, in seconds with millisecond precision so that
+// a token issued right after its user's sessions were revoked is told apart from the revoked ones
+func issuedAtNow() float64 {
+	return float64(time.Now().UnixMilli()) / 1000
+}
+
+// checkSessionRevoked rejects tokens issued before the user's sessions were revoked
+func checkSessionRevoked(token *jwt.Token) error {
+	if sessionRevocationChecker == nil {
+		return nil
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+	userID, _ := claims["sub"].(string)
+	// Read iat directly: the library's parsed date drops the milliseconds
+	iat, ok := claims["iat"].(float64)
+	if userID == "" || !ok {
+		return nil
+	}
+	issuedAt := time.UnixMilli(int64(math.Round(iat * 1000)))
+
+	revoked, err := sessionRevocationChecker.SessionRevoked(context.Background(), userID, issuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		logger.Warn("Revoked JWT token provided", "userID", userID)
+		return errors.New("token has been revoked")
+	}
+	return nil
+}